@@ -0,0 +1,334 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/api"
+)
+
+// deployTask is a task of the simulated agent job: its name and how long it runs
+type deployTask struct {
+	name     string
+	duration time.Duration
+}
+
+// deployTasks are the tasks of every simulated deployment
+var deployTasks = []deployTask{
+	{"Initialize job", 4 * time.Second},
+	{"Download artifact - _build", 21 * time.Second},
+	{"Deploy Azure App Service", 96 * time.Second},
+	{"Finalize Job", 2 * time.Second},
+}
+
+// deployTaskIndex is the task that fails in rejected and runs in in-progress deployments
+const deployTaskIndex = 2
+
+// releaseDetail fills in the deploy attempts of a release fixture the way the single release
+// API returns them: approvals, gates, and the phases and tasks derived from each environment's status
+func releaseDetail(r api.Release) api.Release {
+	r.Environments = append([]api.ReleaseEnvironment(nil), r.Environments...)
+	for i := range r.Environments {
+		env := &r.Environments[i]
+		env.DeploySteps = append([]api.DeployStep(nil), env.DeploySteps...)
+		env.PreDeployApprovals = nil
+		env.PostDeployApprovals = nil
+
+		for j := range env.DeploySteps {
+			step := &env.DeploySteps[j]
+			step.Attempt = j + 1
+			step.Reason = "automated"
+			step.RequestedFor = r.CreatedBy
+			step.QueuedOn = r.CreatedOn.Add(time.Duration(i*10+j*5) * time.Minute)
+			start := step.QueuedOn.Add(time.Minute)
+
+			env.PreDeployApprovals = append(env.PreDeployApprovals, preDeployApproval(r, i, step, start))
+
+			// The last environment is guarded by a gate
+			if i == len(r.Environments)-1 && len(r.Environments) > 1 {
+				step.PreDeploymentGates = &api.ReleaseGates{
+					ID:        step.ID*10 + 2,
+					Status:    "succeeded",
+					StartedOn: start,
+					DeploymentJobs: []api.DeploymentJob{{Tasks: []api.ReleaseTask{{
+						ID:         1,
+						Name:       "Query Azure Monitor alerts",
+						Status:     api.TaskStatusSucceeded,
+						StartTime:  start,
+						FinishTime: start.Add(5 * time.Minute),
+					}}}},
+				}
+				start = start.Add(5 * time.Minute)
+			}
+
+			step.ReleaseDeployPhases = []api.ReleaseDeployPhase{deployPhase(step, start)}
+
+			if step.Status == api.EnvironmentStatusSucceeded {
+				env.PostDeployApprovals = append(env.PostDeployApprovals, api.ReleaseApproval{
+					ID:           step.ID*10 + 3,
+					ApprovalType: "postDeploy",
+					Status:       api.ApprovalStatusApproved,
+					Attempt:      step.Attempt,
+					IsAutomated:  true,
+					CreatedOn:    step.LastModifiedOn,
+					ModifiedOn:   step.LastModifiedOn,
+				})
+			}
+		}
+	}
+	return r
+}
+
+// preDeployApproval returns the approval of a deploy attempt: automatic for the first
+// environment, by a release manager for the others
+func preDeployApproval(r api.Release, envIndex int, step *api.DeployStep, approvedOn time.Time) api.ReleaseApproval {
+	approval := api.ReleaseApproval{
+		ID:           step.ID*10 + 1,
+		ApprovalType: "preDeploy",
+		Status:       api.ApprovalStatusApproved,
+		Attempt:      step.Attempt,
+		CreatedOn:    step.QueuedOn,
+		ModifiedOn:   approvedOn,
+	}
+	if envIndex == 0 {
+		approval.IsAutomated = true
+		return approval
+	}
+	approval.Approver = api.Identity{DisplayName: "[" + r.ProjectReference.Name + "]\\Release Managers"}
+	approval.ApprovedBy = &api.Identity{DisplayName: "Alex Ops", UniqueName: "alex@example.com"}
+	approval.Comments = "Smoke tests passed in the previous stage"
+	return approval
+}
+
+// deployPhase returns the agent job of a deploy attempt with task statuses matching the attempt
+func deployPhase(step *api.DeployStep, start time.Time) api.ReleaseDeployPhase {
+	phase := api.ReleaseDeployPhase{
+		ID:        step.ID*10 + 4,
+		PhaseID:   "1",
+		Name:      "Agent job",
+		Rank:      1,
+		PhaseType: "agentBasedDeployment",
+		Status:    string(step.Status),
+		StartedOn: start,
+	}
+
+	job := api.DeploymentJob{Job: api.ReleaseTask{ID: 1, Name: "Agent job", AgentName: "Hosted Agent", StartTime: start}}
+	t := start
+	for k, dt := range deployTasks {
+		task := api.ReleaseTask{ID: k + 2, Name: dt.name, Rank: k + 1, AgentName: "Hosted Agent"}
+		switch {
+		case step.Status == api.EnvironmentStatusInProgress && k == deployTaskIndex:
+			task.Status = api.TaskStatusInProgress
+			task.StartTime = t
+		case step.Status == api.EnvironmentStatusInProgress && k > deployTaskIndex:
+			task.Status = api.TaskStatusPending
+		case step.Status == api.EnvironmentStatusQueued:
+			task.Status = api.TaskStatusPending
+		case step.Status == api.EnvironmentStatusCanceled && k > 0:
+			task.Status = api.TaskStatusCanceled
+		case step.Status == api.EnvironmentStatusRejected && k == deployTaskIndex:
+			task.Status = api.TaskStatusFailed
+			task.Issues = []api.TaskIssue{{
+				IssueType: "error",
+				Message:   "Failed to deploy web package to App Service. Conflict (CODE: 409)",
+			}}
+		default:
+			task.Status = api.TaskStatusSucceeded
+		}
+		if task.Status != api.TaskStatusPending && task.Status != api.TaskStatusCanceled {
+			task.StartTime = t
+			if task.Status != api.TaskStatusInProgress {
+				task.FinishTime = t.Add(dt.duration)
+			}
+		}
+		t = t.Add(dt.duration)
+		job.Tasks = append(job.Tasks, task)
+	}
+	switch step.Status {
+	case api.EnvironmentStatusRejected:
+		phase.Status = "failed"
+		phase.ErrorLog = "Deployment of Agent job failed"
+		job.Job.Status = api.TaskStatusFailed
+	case api.EnvironmentStatusInProgress:
+		job.Job.Status = api.TaskStatusInProgress
+	case api.EnvironmentStatusCanceled:
+		job.Job.Status = api.TaskStatusCanceled
+	case api.EnvironmentStatusQueued:
+		job.Job.Status = api.TaskStatusPending
+		job.Job.StartTime = time.Time{}
+		phase.StartedOn = time.Time{}
+	default:
+		job.Job.Status = api.TaskStatusSucceeded
+	}
+	phase.DeploymentJobs = []api.DeploymentJob{job}
+	step.LastModifiedOn = t
+	return phase
+}
+
+// taskLog returns the log of a task of a deploy phase in the format of the Azure Pipelines agent
+func taskLog(task api.ReleaseTask) string {
+	var b strings.Builder
+	t := task.StartTime
+	line := func(format string, args ...any) {
+		fmt.Fprintf(&b, "%s %s\n", t.UTC().Format("2006-01-02T15:04:05.0000000Z"), fmt.Sprintf(format, args...))
+		t = t.Add(700 * time.Millisecond)
+	}
+
+	line("##[section]Starting: %s", task.Name)
+	line("==============================================================================")
+	line("Task         : %s", task.Name)
+	line("Version      : 4.238.1")
+	line("==============================================================================")
+	for i := 1; i <= 12; i++ {
+		line("Step %d of %s", i, strings.ToLower(task.Name))
+	}
+	for _, issue := range task.Issues {
+		line("##[%s]%s", issue.IssueType, issue.Message)
+	}
+	line("##[section]Finishing: %s", task.Name)
+	return b.String()
+}
+
+// findTask returns a task of a deploy phase of a release detail
+func findTask(r api.Release, environmentID, phaseID, taskID int) (api.ReleaseTask, bool) {
+	for _, env := range r.Environments {
+		if env.ID != environmentID {
+			continue
+		}
+		for _, step := range env.DeploySteps {
+			for _, phase := range step.ReleaseDeployPhases {
+				if phase.ID != phaseID {
+					continue
+				}
+				for _, task := range phase.Tasks() {
+					if task.ID == taskID {
+						return task, true
+					}
+				}
+			}
+		}
+	}
+	return api.ReleaseTask{}, false
+}
+
+// definitionDetail fills in a release definition the way the single definition API returns it:
+// the environments of its releases, of which the last is only deployed manually, and the build
+// artifact of the build definition named like it ("payments-api-cd" releases "payments-api-ci")
+func (s *server) definitionDetail(d api.ReleaseDefinition, project string) api.ReleaseDefinition {
+	for _, r := range s.data.Releases {
+		if r.ReleaseDefinition.ID != d.ID {
+			continue
+		}
+		for i, env := range r.Environments {
+			e := api.DefinitionEnvironment{ID: d.ID*100 + i + 1, Name: env.Name, Rank: i + 1, Conditions: []api.EnvironmentCondition{}}
+			switch {
+			case i == 0:
+				e.Conditions = []api.EnvironmentCondition{{Name: "ReleaseStarted", ConditionType: "event"}}
+			case i < len(r.Environments)-1:
+				e.Conditions = []api.EnvironmentCondition{{Name: r.Environments[i-1].Name, ConditionType: "environmentState", Value: "4"}}
+			}
+			d.Environments = append(d.Environments, e)
+		}
+		break
+	}
+
+	build := strings.TrimSuffix(d.Name, "-cd") + "-ci"
+	for _, b := range s.buildDefinitions() {
+		if b.Name == build {
+			d.Artifacts = []api.DefinitionArtifact{{
+				Alias:     "_" + b.Name,
+				Type:      "Build",
+				IsPrimary: true,
+				DefinitionReference: map[string]api.ArtifactSourceReference{
+					"definition": {ID: strconv.Itoa(b.ID), Name: b.Name},
+					"project":    {ID: s.data.Releases[0].ProjectReference.ID, Name: project},
+				},
+			}}
+		}
+	}
+	return d
+}
+
+// createRelease serves POST releases: it checks the definition, artifact versions and manual
+// environments, and adds a release whose automated first environment is queued
+func (s *server) createRelease(w http.ResponseWriter, r *http.Request, project string) {
+	var req api.NewRelease
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "The request body is invalid.")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var def *api.ReleaseDefinition
+	for _, d := range s.releaseDefinitions() {
+		if d.ID == req.DefinitionID {
+			detail := s.definitionDetail(d, project)
+			def = &detail
+		}
+	}
+	if def == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("VS402885: Release definition with id %d does not exist.", req.DefinitionID))
+		return
+	}
+
+	builds := make(map[string]bool)
+	for _, b := range s.currentBuilds(project) {
+		if b.Result == api.BuildResultSucceeded {
+			builds[strconv.Itoa(b.ID)] = true
+		}
+	}
+	for _, a := range req.Artifacts {
+		if !builds[a.InstanceReference.ID] {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("VS402962: No succeeded build %s found for artifact %s.", a.InstanceReference.ID, a.Alias))
+			return
+		}
+	}
+	environments := make(map[string]bool)
+	for _, env := range def.Environments {
+		environments[env.Name] = true
+	}
+	manual := make(map[string]bool)
+	for _, name := range req.ManualEnvironments {
+		if !environments[name] {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("VS402898: Environment %s does not exist in the release definition.", name))
+			return
+		}
+		manual[name] = true
+	}
+
+	id := 1
+	for _, existing := range s.data.Releases {
+		id = max(id, existing.ID+1)
+	}
+	user := s.data.Connection.AuthenticatedUser
+	now := s.now().UTC()
+	release := api.Release{
+		ID:                id,
+		Name:              fmt.Sprintf("Release-%d", id),
+		Description:       req.Description,
+		Status:            api.ReleaseStatusActive,
+		CreatedOn:         now,
+		ModifiedOn:        now,
+		ReleaseDefinition: api.ReleaseDefinition{ID: def.ID, Name: def.Name},
+		CreatedBy:         api.Identity{ID: user.ID, DisplayName: user.ProviderDisplayName, UniqueName: user.Properties.Account.Value},
+		ProjectReference:  api.ProjectReference{ID: s.data.Releases[0].ProjectReference.ID, Name: project},
+	}
+	for i, env := range def.Environments {
+		e := api.ReleaseEnvironment{ID: id*10 + i + 1, Name: env.Name, Status: api.EnvironmentStatusNotStarted, DeploySteps: []api.DeployStep{}}
+		if i == 0 && env.IsAutomated() && !manual[env.Name] {
+			e.Status = api.EnvironmentStatusQueued
+			e.DeploySteps = []api.DeployStep{{ID: id*100 + i + 1, Status: api.EnvironmentStatusQueued, OperationStatus: "Queued"}}
+		}
+		release.Environments = append(release.Environments, e)
+	}
+
+	// Lists are newest first
+	s.data.Releases = append([]api.Release{release}, s.data.Releases...)
+	writeJSON(w, release)
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"strconv"
+
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/api"
+)
+
+//go:embed fixtures/*.json
+var builtinFixtures embed.FS
+
+// embeddedFixtures returns the fixtures compiled into the binary
+func embeddedFixtures() fs.FS {
+	sub, err := fs.Sub(builtinFixtures, "fixtures")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+// fixtureData holds the parsed fixture files
+type fixtureData struct {
+	Connection   api.ConnectionData
+	Builds       []api.Build
+	Timelines    map[int][]api.BuildTimelineRecord
+	Releases     []api.Release
+	PullRequests []api.PullRequest
+	Policies     map[int][]api.PolicyEvaluation // pull request ID -> policy evaluations
+	Threads      map[int][]api.CommentThread    // pull request ID -> comment threads
+	Iterations   map[int][]api.PullRequestIteration
+	Changes      map[int][]api.PullRequestChange // pull request ID -> changed files of every iteration
+	Blobs        map[string]string               // object ID -> file content
+}
+
+// changesFixture is the layout of changes.json
+type changesFixture struct {
+	PullRequests map[string]struct {
+		Iterations []api.PullRequestIteration `json:"iterations"`
+		Changes    []api.PullRequestChange    `json:"changes"`
+	} `json:"pullRequests"`
+	Blobs map[string]string `json:"blobs"`
+}
+
+// loadFixtures reads and parses all fixture files
+func loadFixtures(fsys fs.FS) (*fixtureData, error) {
+	var data fixtureData
+
+	if err := readFixture(fsys, "connection_data.json", &data.Connection); err != nil {
+		return nil, err
+	}
+
+	var builds api.BuildsResponse
+	if err := readFixture(fsys, "builds.json", &builds); err != nil {
+		return nil, err
+	}
+	data.Builds = builds.Value
+
+	var timelines map[string][]api.BuildTimelineRecord
+	if err := readFixture(fsys, "timelines.json", &timelines); err != nil {
+		return nil, err
+	}
+	data.Timelines = make(map[int][]api.BuildTimelineRecord)
+	for key, records := range timelines {
+		id, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, fmt.Errorf("timelines.json: invalid build ID %q", key)
+		}
+		for i := range records {
+			records[i].ID = fmt.Sprintf("stage-%d-%d", id, i)
+			records[i].Type = "Stage"
+			records[i].Order = i + 1
+		}
+		data.Timelines[id] = records
+	}
+
+	var releases api.ReleasesResponse
+	if err := readFixture(fsys, "releases.json", &releases); err != nil {
+		return nil, err
+	}
+	data.Releases = releases.Value
+
+	var pullRequests api.PullRequestsResponse
+	if err := readFixture(fsys, "pull_requests.json", &pullRequests); err != nil {
+		return nil, err
+	}
+	data.PullRequests = pullRequests.Value
+
+	// Policy evaluations, threads and changes are optional so older fixture directories keep working
+	var policies map[string][]api.PolicyEvaluation
+	if _, err := fs.Stat(fsys, "policy_evaluations.json"); err == nil {
+		if err := readFixture(fsys, "policy_evaluations.json", &policies); err != nil {
+			return nil, err
+		}
+	}
+	data.Policies = make(map[int][]api.PolicyEvaluation)
+	for key, evaluations := range policies {
+		id, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, fmt.Errorf("policy_evaluations.json: invalid pull request ID %q", key)
+		}
+		data.Policies[id] = evaluations
+	}
+
+	var threads map[string][]api.CommentThread
+	if _, err := fs.Stat(fsys, "threads.json"); err == nil {
+		if err := readFixture(fsys, "threads.json", &threads); err != nil {
+			return nil, err
+		}
+	}
+	data.Threads = make(map[int][]api.CommentThread)
+	for key, prThreads := range threads {
+		id, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, fmt.Errorf("threads.json: invalid pull request ID %q", key)
+		}
+		data.Threads[id] = prThreads
+	}
+
+	var changes changesFixture
+	if _, err := fs.Stat(fsys, "changes.json"); err == nil {
+		if err := readFixture(fsys, "changes.json", &changes); err != nil {
+			return nil, err
+		}
+	}
+	data.Iterations = make(map[int][]api.PullRequestIteration)
+	data.Changes = make(map[int][]api.PullRequestChange)
+	for key, pr := range changes.PullRequests {
+		id, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, fmt.Errorf("changes.json: invalid pull request ID %q", key)
+		}
+		data.Iterations[id] = pr.Iterations
+		data.Changes[id] = pr.Changes
+	}
+	data.Blobs = changes.Blobs
+
+	return &data, nil
+}
+
+// readFixture decodes a single JSON fixture file
+func readFixture(fsys fs.FS, name string, v any) error {
+	raw, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+// Command azdo-mock serves a fake Azure DevOps REST API for developing and demoing azdo-tui
+// without access to a real organization.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:8080", "Address to listen on")
+	fixturesDir := flag.String("fixtures", "", "Directory with fixture JSON files (default: built-in fixtures)")
+	stageDuration := flag.Duration("stage-duration", 20*time.Second, "How long each simulated build stage runs")
+	latency := flag.Duration("latency", 0, "Delay added to every response")
+	failRate := flag.Float64("fail-rate", 0, "Fraction of requests answered with 500 Internal Server Error (0-1)")
+	throttleRate := flag.Float64("throttle-rate", 0, "Fraction of requests answered with 429 Too Many Requests (0-1)")
+	flag.Parse()
+
+	if *stageDuration <= 0 {
+		fmt.Fprintln(os.Stderr, "Error: --stage-duration must be positive")
+		os.Exit(1)
+	}
+
+	var fixtures fs.FS = embeddedFixtures()
+	if *fixturesDir != "" {
+		fixtures = os.DirFS(*fixturesDir)
+	}
+
+	data, err := loadFixtures(fixtures)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading fixtures: %v\n", err)
+		os.Exit(1)
+	}
+
+	srv := newServer(data, serverOptions{
+		stageDuration: *stageDuration,
+		latency:       *latency,
+		failRate:      *failRate,
+		throttleRate:  *throttleRate,
+	})
+
+	log.Printf("azdo-mock listening on http://%s (set azure_devops.base_url to this address)", *addr)
+	if err := http.ListenAndServe(*addr, srv); err != nil {
+		fmt.Fprintf(os.Stderr, "Error running server: %v\n", err)
+		os.Exit(1)
+	}
+}
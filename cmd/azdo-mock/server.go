@@ -0,0 +1,853 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/api"
+)
+
+// serverOptions controls the behavior of the mock server
+type serverOptions struct {
+	stageDuration time.Duration
+	latency       time.Duration
+	failRate      float64
+	throttleRate  float64
+}
+
+// server is a mock Azure DevOps REST API.
+// Routing only looks at the path, so the same server answers for the vsrm host used by releases.
+type server struct {
+	mu      sync.Mutex // Guards data changed by write requests
+	data    *fixtureData
+	opts    serverOptions
+	started time.Time
+	now     func() time.Time
+}
+
+func newServer(data *fixtureData, opts serverOptions) *server {
+	return &server{
+		data:    data,
+		opts:    opts,
+		started: time.Now(),
+		now:     time.Now,
+	}
+}
+
+// ServeHTTP routes requests by the path segments around "_apis"
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s", r.Method, r.URL.RequestURI())
+
+	if s.opts.latency > 0 {
+		time.Sleep(s.opts.latency)
+	}
+
+	if r.Header.Get("Authorization") == "" {
+		writeError(w, http.StatusUnauthorized, "TF400813: The user is not authorized to access this resource.")
+		return
+	}
+	if rand.Float64() < s.opts.throttleRate {
+		w.Header().Set("Retry-After", "1")
+		writeError(w, http.StatusTooManyRequests, "Request was blocked due to exceeding usage of resource 'Global' in namespace 'User'.")
+		return
+	}
+	if rand.Float64() < s.opts.failRate {
+		writeError(w, http.StatusInternalServerError, "Simulated internal server error.")
+		return
+	}
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	apisIdx := -1
+	for i, seg := range segments {
+		if seg == "_apis" {
+			apisIdx = i
+			break
+		}
+	}
+	if apisIdx < 1 || apisIdx == len(segments)-1 {
+		writeError(w, http.StatusNotFound, "Unknown resource.")
+		return
+	}
+
+	route := segments[apisIdx+1:]
+	// Pull requests, their threads and releases are the only resources that can be changed
+	if r.Method != http.MethodGet && route[0] != "git" && route[0] != "release" {
+		writeError(w, http.StatusMethodNotAllowed, "Only GET is supported for this resource by azdo-mock.")
+		return
+	}
+	switch route[0] {
+	case "connectionData":
+		writeJSON(w, s.data.Connection)
+		return
+	case "projects":
+		s.handleProject(w, r, route[1:])
+		return
+	case "identities":
+		ids := s.identities(first(r.URL.Query(), "filterValue"))
+		writeJSON(w, api.IdentitiesResponse{Count: len(ids), Value: ids})
+		return
+	}
+
+	project := segments[apisIdx-1]
+	switch route[0] {
+	case "build":
+		s.handleBuild(w, r, project, route[1:])
+	case "release":
+		s.handleRelease(w, r, project, route[1:])
+	case "git":
+		s.handleGit(w, r, project, route[1:])
+	case "policy":
+		s.handlePolicy(w, r, route[1:])
+	default:
+		writeError(w, http.StatusNotFound, "Unknown resource.")
+	}
+}
+
+// handleProject serves _apis/projects and _apis/projects/{project}; every project name exists
+func (s *server) handleProject(w http.ResponseWriter, r *http.Request, route []string) {
+	switch len(route) {
+	case 0:
+		projects := s.projects()
+		if skip, err := strconv.Atoi(first(r.URL.Query(), "$skip")); err == nil && skip > 0 {
+			projects = projects[min(skip, len(projects)):]
+		}
+		projects = top(projects, first(r.URL.Query(), "$top"))
+		writeJSON(w, api.ProjectsResponse{Count: len(projects), Value: projects})
+	case 1:
+		writeJSON(w, api.TeamProject{ID: "0f1e2d3c-4b5a-6978-8a9b-0c1d2e3f4a5b", Name: route[0]})
+	default:
+		writeError(w, http.StatusNotFound, "Unknown resource.")
+	}
+}
+
+// projects returns the unique projects referenced by the build fixtures
+func (s *server) projects() []api.TeamProject {
+	seen := make(map[string]bool)
+	var projects []api.TeamProject
+	for _, b := range s.data.Builds {
+		if b.Project.Name != "" && !seen[b.Project.Name] {
+			seen[b.Project.Name] = true
+			projects = append(projects, b.Project)
+		}
+	}
+	return projects
+}
+
+// handleBuild serves _apis/build/...
+func (s *server) handleBuild(w http.ResponseWriter, r *http.Request, project string, route []string) {
+	switch {
+	case len(route) == 1 && route[0] == "builds":
+		builds := s.currentBuilds(project)
+		builds = filterBuilds(builds, r.URL.Query())
+		writeJSON(w, api.BuildsResponse{Count: len(builds), Value: builds})
+
+	case len(route) == 3 && route[0] == "builds" && route[2] == "timeline":
+		id, _ := strconv.Atoi(route[1])
+		for _, b := range s.currentBuilds(project) {
+			if b.ID == id {
+				writeJSON(w, api.BuildTimelineResponse{Records: b.Stages})
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Build %d not found.", id))
+
+	case len(route) == 1 && route[0] == "definitions":
+		defs := s.buildDefinitions()
+		writeJSON(w, map[string]any{"count": len(defs), "value": defs})
+
+	case len(route) == 2 && route[0] == "definitions":
+		id, _ := strconv.Atoi(route[1])
+		for _, d := range s.buildDefinitions() {
+			if d.ID == id {
+				writeJSON(w, d)
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, fmt.Sprintf("The definition with ID %d does not exist.", id))
+
+	default:
+		writeError(w, http.StatusNotFound, "Unknown resource.")
+	}
+}
+
+// handleRelease serves _apis/release/...
+func (s *server) handleRelease(w http.ResponseWriter, r *http.Request, project string, route []string) {
+	if len(route) == 1 && route[0] == "releases" && r.Method == http.MethodPost {
+		s.createRelease(w, r, project)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed.")
+		return
+	}
+
+	// Releases are added by write requests
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case len(route) == 1 && route[0] == "releases":
+		releases := filterReleases(s.data.Releases, r.URL.Query())
+		for i := range releases {
+			releases[i].ProjectReference.Name = project
+		}
+		writeJSON(w, api.ReleasesResponse{Count: len(releases), Value: releases})
+
+	case len(route) == 2 && route[0] == "releases":
+		release, ok := findRelease(s.data.Releases, route[1])
+		if !ok {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("VS402881: No release found with ID %s.", route[1]))
+			return
+		}
+		release.ProjectReference.Name = project
+		writeJSON(w, releaseDetail(release))
+
+	case len(route) == 9 && route[0] == "releases" && route[2] == "environments" && route[4] == "deployPhases" &&
+		route[6] == "tasks" && route[8] == "logs":
+		release, ok := findRelease(s.data.Releases, route[1])
+		if !ok {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("VS402881: No release found with ID %s.", route[1]))
+			return
+		}
+		release.ProjectReference.Name = project
+		environmentID, _ := strconv.Atoi(route[3])
+		phaseID, _ := strconv.Atoi(route[5])
+		taskID, _ := strconv.Atoi(route[7])
+		task, ok := findTask(releaseDetail(release), environmentID, phaseID, taskID)
+		if !ok || task.StartTime.IsZero() {
+			writeError(w, http.StatusNotFound, "The requested task log was not found.")
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprint(w, taskLog(task))
+
+	case len(route) == 1 && route[0] == "definitions":
+		defs := s.releaseDefinitions()
+		writeJSON(w, map[string]any{"count": len(defs), "value": defs})
+
+	case len(route) == 2 && route[0] == "definitions":
+		id, _ := strconv.Atoi(route[1])
+		for _, d := range s.releaseDefinitions() {
+			if d.ID == id {
+				writeJSON(w, s.definitionDetail(d, project))
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, fmt.Sprintf("ReleaseDefinition with id %d does not exist.", id))
+
+	default:
+		writeError(w, http.StatusNotFound, "Unknown resource.")
+	}
+}
+
+// handleGit serves _apis/git/...
+func (s *server) handleGit(w http.ResponseWriter, r *http.Request, project string, route []string) {
+	if len(route) >= 4 && route[0] == "repositories" && route[2] == "pullrequests" {
+		s.handlePullRequest(w, r, route[3], route[4:])
+		return
+	}
+	if len(route) == 3 && route[0] == "repositories" && route[2] == "pullrequests" && r.Method == http.MethodPost {
+		s.createPullRequest(w, r, route[1])
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed.")
+		return
+	}
+
+	switch {
+	case len(route) == 1 && route[0] == "pullrequests":
+		s.mu.Lock()
+		prs := filterPullRequests(s.data.PullRequests, "", r.URL.Query())
+		s.mu.Unlock()
+		writeJSON(w, api.PullRequestsResponse{Count: len(prs), Value: prs})
+
+	case len(route) == 1 && route[0] == "repositories":
+		repos := s.repositories()
+		writeJSON(w, map[string]any{"count": len(repos), "value": repos})
+
+	case len(route) == 2 && route[0] == "repositories":
+		for _, repo := range s.repositories() {
+			if repo.ID == route[1] || strings.EqualFold(repo.Name, route[1]) {
+				writeJSON(w, api.GitRepository{ID: repo.ID, Name: repo.Name, DefaultBranch: "refs/heads/main", Project: repo.Project})
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, fmt.Sprintf("TF401019: The Git repository with name or identifier %s does not exist.", route[1]))
+
+	case len(route) == 3 && route[0] == "repositories" && route[2] == "pullrequests":
+		s.mu.Lock()
+		prs := filterPullRequests(s.data.PullRequests, route[1], r.URL.Query())
+		s.mu.Unlock()
+		writeJSON(w, api.PullRequestsResponse{Count: len(prs), Value: prs})
+
+	case len(route) == 3 && route[0] == "repositories" && route[2] == "refs":
+		// There is no git data behind the mock, so every branch asked for exists
+		filter := r.URL.Query().Get("filter")
+		refs := []api.GitRef{}
+		if strings.HasPrefix(filter, "heads/") {
+			refs = append(refs, api.GitRef{Name: "refs/" + filter, ObjectID: "4b1e9c7d2a3f5e6b8c0d1e2f3a4b5c6d7e8f9a0b"})
+		}
+		writeJSON(w, api.GitRefsResponse{Count: len(refs), Value: refs})
+
+	case len(route) == 4 && route[0] == "repositories" && route[2] == "blobs":
+		content, ok := s.data.Blobs[route[3]]
+		if !ok {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("TF401174: The item %s could not be found.", route[3]))
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write([]byte(content))
+
+	default:
+		writeError(w, http.StatusNotFound, "Unknown resource.")
+	}
+}
+
+// handlePullRequest serves _apis/git/repositories/{repo}/pullrequests/{id}/..., including
+// completion, auto-complete, replies to threads and thread status changes, which are kept
+// until the server stops
+func (s *server) handlePullRequest(w http.ResponseWriter, r *http.Request, prID string, route []string) {
+	id, err := strconv.Atoi(prID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "The pull request ID is invalid.")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case len(route) == 0 && r.Method == http.MethodGet:
+		for _, pr := range s.data.PullRequests {
+			if pr.PullRequestID == id {
+				writeJSON(w, pr)
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, fmt.Sprintf("TF401180: The requested pull request was not found: %d.", id))
+
+	case len(route) == 0 && r.Method == http.MethodPatch:
+		s.updatePullRequest(w, r, id)
+
+	case len(route) == 1 && route[0] == "threads" && r.Method == http.MethodGet:
+		threads := s.data.Threads[id]
+		writeJSON(w, api.CommentThreadsResponse{Count: len(threads), Value: threads})
+
+	case len(route) == 1 && route[0] == "iterations" && r.Method == http.MethodGet:
+		iterations := s.data.Iterations[id]
+		writeJSON(w, api.PullRequestIterationsResponse{Count: len(iterations), Value: iterations})
+
+	case len(route) == 3 && route[0] == "iterations" && route[2] == "changes" && r.Method == http.MethodGet:
+		writeJSON(w, pageChanges(s.data.Changes[id], r.URL.Query()))
+
+	case len(route) == 2 && route[0] == "threads" && r.Method == http.MethodPatch:
+		thread := s.thread(id, route[1])
+		if thread == nil {
+			writeError(w, http.StatusNotFound, "The thread was not found.")
+			return
+		}
+		var update api.CommentThread
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			writeError(w, http.StatusBadRequest, "The request body is invalid.")
+			return
+		}
+		if update.Status != "" {
+			thread.Status = update.Status
+		}
+		thread.LastUpdatedDate = s.now().UTC()
+		writeJSON(w, thread)
+
+	case len(route) == 3 && route[0] == "threads" && route[2] == "comments" && r.Method == http.MethodPost:
+		thread := s.thread(id, route[1])
+		if thread == nil {
+			writeError(w, http.StatusNotFound, "The thread was not found.")
+			return
+		}
+		var comment api.Comment
+		if err := json.NewDecoder(r.Body).Decode(&comment); err != nil || strings.TrimSpace(comment.Content) == "" {
+			writeError(w, http.StatusBadRequest, "The comment content is required.")
+			return
+		}
+		comment.ID = len(thread.Comments) + 1
+		user := s.data.Connection.AuthenticatedUser
+		comment.Author = api.Identity{ID: user.ID, DisplayName: user.ProviderDisplayName, UniqueName: user.Properties.Account.Value}
+		comment.PublishedDate = s.now().UTC()
+		thread.Comments = append(thread.Comments, comment)
+		thread.LastUpdatedDate = comment.PublishedDate
+		writeJSON(w, comment)
+
+	default:
+		writeError(w, http.StatusNotFound, "Unknown resource.")
+	}
+}
+
+// updatePullRequest completes a pull request or sets its auto-complete; the caller must hold s.mu
+func (s *server) updatePullRequest(w http.ResponseWriter, r *http.Request, id int) {
+	var pr *api.PullRequest
+	for i := range s.data.PullRequests {
+		if s.data.PullRequests[i].PullRequestID == id {
+			pr = &s.data.PullRequests[i]
+		}
+	}
+	if pr == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("TF401180: The requested pull request was not found: %d.", id))
+		return
+	}
+
+	var update api.PullRequest
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		writeError(w, http.StatusBadRequest, "The request body is invalid.")
+		return
+	}
+
+	if update.CompletionOptions != nil {
+		pr.CompletionOptions = update.CompletionOptions
+	}
+	if update.AutoCompleteSetBy != nil {
+		pr.AutoCompleteSetBy = nil
+		if strings.Trim(update.AutoCompleteSetBy.ID, "0-") != "" {
+			pr.AutoCompleteSetBy = update.AutoCompleteSetBy
+		}
+	}
+	if update.Status == api.PullRequestStatusCompleted {
+		if !pr.IsActive() {
+			writeError(w, http.StatusConflict, fmt.Sprintf("TF401181: The pull request cannot be edited due to its state: %s.", pr.Status))
+			return
+		}
+		if update.LastMergeSourceCommit == nil || pr.LastMergeSourceCommit == nil ||
+			update.LastMergeSourceCommit.CommitID != pr.LastMergeSourceCommit.CommitID {
+			writeError(w, http.StatusConflict, "TF401192: The source branch has been updated since the pull request was last merged.")
+			return
+		}
+		pr.Status = api.PullRequestStatusCompleted
+		pr.ClosedDate = s.now().UTC()
+		pr.MergeCommit = &api.GitCommitRef{CommitID: fmt.Sprintf("%x", sha1.Sum([]byte(pr.LastMergeSourceCommit.CommitID)))}
+		pr.AutoCompleteSetBy = nil
+	}
+	writeJSON(w, pr)
+}
+
+// createPullRequest adds an active pull request to a repository of the fixtures
+func (s *server) createPullRequest(w http.ResponseWriter, r *http.Request, repositoryID string) {
+	var req api.NewPullRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "The request body is invalid.")
+		return
+	}
+	if strings.TrimSpace(req.Title) == "" || req.SourceRefName == "" || req.TargetRefName == "" {
+		writeError(w, http.StatusBadRequest, "The title, source and target branch are required.")
+		return
+	}
+	if req.SourceRefName == req.TargetRefName {
+		writeError(w, http.StatusBadRequest, "TF401398: The source and target branch must be different.")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var repo *api.PullRequestRepository
+	for _, candidate := range s.repositories() {
+		if candidate.ID == repositoryID || strings.EqualFold(candidate.Name, repositoryID) {
+			repo = &candidate
+			break
+		}
+	}
+	if repo == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("TF401019: The Git repository with name or identifier %s does not exist.", repositoryID))
+		return
+	}
+
+	id := 1
+	for _, pr := range s.data.PullRequests {
+		if pr.Repository.ID == repo.ID && pr.IsActive() && pr.SourceRefName == req.SourceRefName && pr.TargetRefName == req.TargetRefName {
+			writeError(w, http.StatusConflict, "TF401179: An active pull request for the source and target branch already exists.")
+			return
+		}
+		id = max(id, pr.PullRequestID+1)
+	}
+
+	// Reviewers are sent by ID only
+	known := make(map[string]api.ConnectionIdentity)
+	for _, identity := range s.identities("") {
+		known[identity.ID] = identity
+	}
+	reviewers := make([]api.Reviewer, 0, len(req.Reviewers))
+	for _, reviewer := range req.Reviewers {
+		if identity, ok := known[reviewer.ID]; ok {
+			reviewer.DisplayName = identity.ProviderDisplayName
+			reviewer.UniqueName = identity.Properties.Account.Value
+		}
+		reviewers = append(reviewers, reviewer)
+	}
+
+	user := s.data.Connection.AuthenticatedUser
+	now := s.now().UTC()
+	pr := api.PullRequest{
+		PullRequestID:         id,
+		Title:                 req.Title,
+		Description:           req.Description,
+		SourceRefName:         req.SourceRefName,
+		TargetRefName:         req.TargetRefName,
+		CreationDate:          now,
+		CreatedBy:             api.Identity{ID: user.ID, DisplayName: user.ProviderDisplayName, UniqueName: user.Properties.Account.Value},
+		Repository:            *repo,
+		Reviewers:             reviewers,
+		Status:                api.PullRequestStatusActive,
+		IsDraft:               req.IsDraft,
+		MergeStatus:           "succeeded",
+		LastMergeSourceCommit: &api.GitCommitRef{CommitID: fmt.Sprintf("%x", sha1.Sum([]byte(req.SourceRefName+now.String())))},
+	}
+	s.data.PullRequests = append(s.data.PullRequests, pr)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, pr)
+}
+
+// identities returns the signed-in user and the pull request authors and reviewers whose
+// name or account contains query
+func (s *server) identities(query string) []api.ConnectionIdentity {
+	query = strings.ToLower(query)
+	seen := make(map[string]bool)
+	found := []api.ConnectionIdentity{}
+	add := func(id, name, account string) {
+		if id == "" || seen[id] {
+			return
+		}
+		if query != "" && !strings.Contains(strings.ToLower(name), query) && !strings.Contains(strings.ToLower(account), query) {
+			return
+		}
+		seen[id] = true
+		identity := api.ConnectionIdentity{ID: id, ProviderDisplayName: name}
+		identity.Properties.Account.Value = account
+		identity.Properties.Mail.Value = account
+		found = append(found, identity)
+	}
+
+	user := s.data.Connection.AuthenticatedUser
+	add(user.ID, user.ProviderDisplayName, user.Properties.Account.Value)
+	for _, pr := range s.data.PullRequests {
+		add(pr.CreatedBy.ID, pr.CreatedBy.DisplayName, pr.CreatedBy.UniqueName)
+		for _, reviewer := range pr.Reviewers {
+			add(reviewer.ID, reviewer.DisplayName, reviewer.UniqueName)
+		}
+	}
+	return found
+}
+
+// changesPageSize is the most changes the mock returns at once, like the server it returns
+// fewer than requested and points to the rest with nextSkip
+const changesPageSize = 100
+
+// pageChanges applies the $skip and $top parameters of the iteration changes API
+func pageChanges(changes []api.PullRequestChange, query url.Values) api.PullRequestChangesResponse {
+	skip, _ := strconv.Atoi(query.Get("$skip"))
+	skip = max(skip, 0)
+	if skip >= len(changes) {
+		return api.PullRequestChangesResponse{ChangeEntries: []api.PullRequestChange{}}
+	}
+	top, err := strconv.Atoi(query.Get("$top"))
+	if err != nil || top < 0 {
+		top = changesPageSize
+	}
+	top = min(top, changesPageSize)
+
+	page := changes[skip:]
+	if top >= len(page) {
+		return api.PullRequestChangesResponse{ChangeEntries: page}
+	}
+	return api.PullRequestChangesResponse{ChangeEntries: page[:top], NextSkip: skip + top, NextTop: top}
+}
+
+// thread returns a thread of a pull request by its ID string; the caller must hold s.mu
+func (s *server) thread(pullRequestID int, threadID string) *api.CommentThread {
+	id, err := strconv.Atoi(threadID)
+	if err != nil {
+		return nil
+	}
+	threads := s.data.Threads[pullRequestID]
+	for i := range threads {
+		if threads[i].ID == id {
+			return &threads[i]
+		}
+	}
+	return nil
+}
+
+// handlePolicy serves _apis/policy/evaluations for pull request artifact IDs
+// (vstfs:///CodeReview/CodeReviewId/{projectId}/{pullRequestId})
+func (s *server) handlePolicy(w http.ResponseWriter, r *http.Request, route []string) {
+	if len(route) != 1 || route[0] != "evaluations" {
+		writeError(w, http.StatusNotFound, "Unknown resource.")
+		return
+	}
+
+	artifactID := first(r.URL.Query(), "artifactId")
+	if !strings.HasPrefix(artifactID, "vstfs:///CodeReview/CodeReviewId/") {
+		writeError(w, http.StatusBadRequest, "The artifactId parameter is invalid.")
+		return
+	}
+	id, _ := strconv.Atoi(artifactID[strings.LastIndex(artifactID, "/")+1:])
+
+	evaluations := s.data.Policies[id]
+	writeJSON(w, api.PolicyEvaluationsResponse{Count: len(evaluations), Value: evaluations})
+}
+
+// currentBuilds returns the fixture builds with in-progress builds advanced through their stages
+func (s *server) currentBuilds(project string) []api.Build {
+	now := s.now()
+	builds := make([]api.Build, len(s.data.Builds))
+	running := 0
+
+	for i, b := range s.data.Builds {
+		b.Project.Name = project
+		b.Stages = append([]api.BuildTimelineRecord(nil), s.data.Timelines[b.ID]...)
+
+		if b.Status == api.BuildStatusInProgress {
+			s.simulateBuild(&b, now, time.Duration(running)*s.opts.stageDuration)
+			running++
+		}
+		builds[i] = b
+	}
+
+	sort.SliceStable(builds, func(i, j int) bool {
+		return builds[i].QueueTime.After(builds[j].QueueTime)
+	})
+	return builds
+}
+
+// simulateBuild moves a build through its stages based on the time since the server started.
+// Each stage runs for stageDuration; after the last stage the build shows as succeeded for one
+// stage duration before starting over. offset staggers multiple running builds.
+func (s *server) simulateBuild(b *api.Build, now time.Time, offset time.Duration) {
+	stageCount := len(b.Stages)
+	cycle := time.Duration(stageCount+1) * s.opts.stageDuration
+	elapsed := (now.Sub(s.started) + offset) % cycle
+	current := int(elapsed / s.opts.stageDuration)
+
+	b.StartTime = now.Add(-elapsed)
+	b.QueueTime = b.StartTime.Add(-5 * time.Second)
+
+	for i := range b.Stages {
+		switch {
+		case i < current:
+			b.Stages[i].State = api.BuildTimelineRecordStateCompleted
+			b.Stages[i].Result = api.BuildTimelineRecordResultSucceeded
+		case i == current:
+			b.Stages[i].State = api.BuildTimelineRecordStateInProgress
+			b.Stages[i].Result = ""
+		default:
+			b.Stages[i].State = api.BuildTimelineRecordStatePending
+			b.Stages[i].Result = ""
+		}
+	}
+
+	if current >= stageCount {
+		b.Status = api.BuildStatusCompleted
+		b.Result = api.BuildResultSucceeded
+		b.FinishTime = b.StartTime.Add(time.Duration(stageCount) * s.opts.stageDuration)
+	} else {
+		b.Status = api.BuildStatusInProgress
+		b.Result = api.BuildResultNone
+		b.FinishTime = time.Time{}
+	}
+}
+
+// buildDefinitions returns the unique build definitions referenced by the fixtures
+func (s *server) buildDefinitions() []api.BuildDefinition {
+	seen := make(map[int]bool)
+	var defs []api.BuildDefinition
+	for _, b := range s.data.Builds {
+		if !seen[b.Definition.ID] {
+			seen[b.Definition.ID] = true
+			defs = append(defs, b.Definition)
+		}
+	}
+	return defs
+}
+
+// releaseDefinitions returns the unique release definitions referenced by the fixtures
+func (s *server) releaseDefinitions() []api.ReleaseDefinition {
+	seen := make(map[int]bool)
+	var defs []api.ReleaseDefinition
+	for _, r := range s.data.Releases {
+		if !seen[r.ReleaseDefinition.ID] {
+			seen[r.ReleaseDefinition.ID] = true
+			defs = append(defs, r.ReleaseDefinition)
+		}
+	}
+	return defs
+}
+
+// repositories returns the unique repositories referenced by the pull request fixtures
+func (s *server) repositories() []api.PullRequestRepository {
+	seen := make(map[string]bool)
+	var repos []api.PullRequestRepository
+	for _, pr := range s.data.PullRequests {
+		if !seen[pr.Repository.Name] {
+			seen[pr.Repository.Name] = true
+			repos = append(repos, pr.Repository)
+		}
+	}
+	return repos
+}
+
+// filterBuilds applies the definitions, branchName, statusFilter, resultFilter and $top query parameters
+func filterBuilds(builds []api.Build, query map[string][]string) []api.Build {
+	definitions := intSet(first(query, "definitions"))
+	branch := first(query, "branchName")
+	status := first(query, "statusFilter")
+	result := first(query, "resultFilter")
+
+	var filtered []api.Build
+	for _, b := range builds {
+		if len(definitions) > 0 && !definitions[b.Definition.ID] {
+			continue
+		}
+		if branch != "" && b.SourceBranch != branch {
+			continue
+		}
+		if status != "" && status != "all" && string(b.Status) != status {
+			continue
+		}
+		if result != "" && string(b.Result) != result {
+			continue
+		}
+		filtered = append(filtered, b)
+	}
+	return top(filtered, first(query, "$top"))
+}
+
+// filterReleases applies the definitionId and $top query parameters
+func filterReleases(releases []api.Release, query map[string][]string) []api.Release {
+	definitions := intSet(first(query, "definitionId"))
+
+	var filtered []api.Release
+	for _, r := range releases {
+		if len(definitions) > 0 && !definitions[r.ReleaseDefinition.ID] {
+			continue
+		}
+		filtered = append(filtered, r)
+	}
+	return top(filtered, first(query, "$top"))
+}
+
+// findRelease returns the release with the ID in a path segment
+func findRelease(releases []api.Release, id string) (api.Release, bool) {
+	for _, r := range releases {
+		if strconv.Itoa(r.ID) == id {
+			return r, true
+		}
+	}
+	return api.Release{}, false
+}
+
+// filterPullRequests applies the repository, searchCriteria (status, targetRefName, creatorId,
+// reviewerId, minTime with queryTimeRangeType), $skip and $top query parameters
+func filterPullRequests(prs []api.PullRequest, repository string, query map[string][]string) []api.PullRequest {
+	target := first(query, "searchCriteria.targetRefName")
+	minTime, _ := time.Parse(time.RFC3339, first(query, "searchCriteria.minTime"))
+	closedTime := first(query, "searchCriteria.queryTimeRangeType") == "closed"
+	source := first(query, "searchCriteria.sourceRefName")
+	creator := first(query, "searchCriteria.creatorId")
+	reviewer := first(query, "searchCriteria.reviewerId")
+	status := first(query, "searchCriteria.status")
+	if status == "" {
+		status = string(api.PullRequestStatusActive)
+	}
+
+	var filtered []api.PullRequest
+	for _, pr := range prs {
+		if repository != "" && !strings.EqualFold(pr.Repository.Name, repository) && pr.Repository.ID != repository {
+			continue
+		}
+		if status != "all" && string(pr.Status) != status {
+			continue
+		}
+		if target != "" && !strings.EqualFold(pr.TargetRefName, target) {
+			continue
+		}
+		if source != "" && !strings.EqualFold(pr.SourceRefName, source) {
+			continue
+		}
+		if creator != "" && !strings.EqualFold(pr.CreatedBy.ID, creator) {
+			continue
+		}
+		if reviewer != "" && !pr.HasReviewer(reviewer) {
+			continue
+		}
+		if !minTime.IsZero() {
+			at := pr.CreationDate
+			if closedTime {
+				at = pr.ClosedDate
+			}
+			if at.Before(minTime) {
+				continue
+			}
+		}
+		filtered = append(filtered, pr)
+	}
+	if skip, err := strconv.Atoi(first(query, "$skip")); err == nil && skip > 0 {
+		filtered = filtered[min(skip, len(filtered)):]
+	}
+	return top(filtered, first(query, "$top"))
+}
+
+// first returns the first value of a query parameter
+func first(query map[string][]string, key string) string {
+	if values := query[key]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// intSet parses a comma-separated list of integers
+func intSet(s string) map[int]bool {
+	set := make(map[int]bool)
+	for _, part := range strings.Split(s, ",") {
+		if id, err := strconv.Atoi(strings.TrimSpace(part)); err == nil {
+			set[id] = true
+		}
+	}
+	return set
+}
+
+// top limits a slice to the $top query parameter, if set
+func top[T any](items []T, value string) []T {
+	n, err := strconv.Atoi(value)
+	if err == nil && n >= 0 && len(items) > n {
+		return items[:n]
+	}
+	return items
+}
+
+// writeJSON writes a 200 response with a JSON body
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("failed to write response: %v", err)
+	}
+}
+
+// writeError writes an error response shaped like Azure DevOps errors
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"$id":       "1",
+		"message":   message,
+		"typeName":  "Microsoft.VisualStudio.Services.WebApi.VssServiceException",
+		"typeKey":   "VssServiceException",
+		"errorCode": 0,
+	})
+}
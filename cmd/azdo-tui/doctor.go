@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/config"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/discovery"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/doctor"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/tui"
+)
+
+// runDoctor checks the connection, credentials and configured projects and prints a report.
+// Returns the process exit code.
+func runDoctor(args []string) int {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	configPath := fs.String("config", "", "Path to configuration file (required)")
+	configPathShort := fs.String("c", "", "Path to configuration file (shorthand)")
+	recordDir := fs.String("record", "", "Save every API response to this directory (secrets are stripped)")
+	replayDir := fs.String("replay", "", "Serve API responses from a recording directory instead of the network")
+	_ = fs.Parse(args)
+
+	cfgPath := *configPath
+	if cfgPath == "" {
+		cfgPath = *configPathShort
+	}
+
+	if cfgPath == "" {
+		fmt.Fprintln(os.Stderr, "Error: --config or -c flag is required")
+		fmt.Fprintln(os.Stderr, "Usage: azdo-tui doctor --config <path-to-config.yaml>")
+		return 1
+	}
+
+	load := config.Load
+	if *replayDir != "" {
+		// Replayed responses need no credentials
+		load = config.LoadForReplay
+	}
+	cfg, err := load(cfgPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading configuration: %v\n", err)
+		return 1
+	}
+
+	clients, err := tui.NewClients(cfg, tui.ClientOptions{
+		RecordDir: *recordDir,
+		ReplayDir: *replayDir,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating API client: %v\n", err)
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	if err := discovery.Projects(ctx, cfg, clients); err != nil {
+		fmt.Printf("%s Projects: %v\n", doctor.StatusFailed.Icon(), err)
+		fmt.Println("\n1 problem(s) found")
+		return 1
+	}
+
+	var failures int
+	for i, org := range cfg.Organizations {
+		if len(cfg.Organizations) > 1 {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("Organization %s\n", org.Name)
+		}
+
+		report := doctor.Run(ctx, clients[org.Name], org.Projects)
+		fmt.Print(report.String())
+		failures += len(report.Failures())
+	}
+
+	if failures > 0 {
+		fmt.Printf("\n%d problem(s) found\n", failures)
+		return 1
+	}
+
+	fmt.Println("\nAll checks passed")
+	return 0
+}
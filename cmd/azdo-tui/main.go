@@ -1,13 +1,18 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/polakv93/azure_devops_tui_dashboard/internal/config"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/discovery"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/localgit"
 	"github.com/polakv93/azure_devops_tui_dashboard/internal/tui"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/webhook"
 )
 
 var (
@@ -15,16 +20,28 @@ var (
 )
 
 func main() {
+	// Handle subcommands
+	if len(os.Args) > 1 && os.Args[1] == "doctor" {
+		os.Exit(runDoctor(os.Args[2:]))
+	}
+	os.Exit(run())
+}
+
+// run starts the dashboard and returns the process exit code once it is closed.
+// Deferred cleanup runs before the process exits.
+func run() int {
 	// Parse command line flags
-	configPath := flag.String("config", "", "Path to configuration file (required)")
+	configPath := flag.String("config", "", "Path to configuration file (optional inside an Azure DevOps git checkout)")
 	configPathShort := flag.String("c", "", "Path to configuration file (shorthand)")
 	showVersion := flag.Bool("version", false, "Show version information")
+	recordDir := flag.String("record", "", "Save every API response to this directory (secrets are stripped)")
+	replayDir := flag.String("replay", "", "Serve API responses from a recording directory instead of the network")
 	flag.Parse()
 
 	// Handle version flag
 	if *showVersion {
 		fmt.Printf("azdo-tui version %s\n", version)
-		os.Exit(0)
+		return 0
 	}
 
 	// Determine config path
@@ -33,25 +50,107 @@ func main() {
 		cfgPath = *configPathShort
 	}
 
-	if cfgPath == "" {
-		fmt.Fprintln(os.Stderr, "Error: --config or -c flag is required")
+	// The git checkout the dashboard is started in, if its remote is an Azure DevOps repository
+	var local *localgit.Repository
+	if dir, err := os.Getwd(); err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		local, _ = localgit.Detect(ctx, dir)
+		cancel()
+	}
+
+	if cfgPath == "" && local == nil {
+		fmt.Fprintln(os.Stderr, "Error: --config or -c flag is required outside of an Azure DevOps git checkout")
 		fmt.Fprintln(os.Stderr, "Usage: azdo-tui --config <path-to-config.yaml>")
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, "       azdo-tui doctor --config <path-to-config.yaml>")
+		return 1
 	}
 
-	// Load configuration
-	cfg, err := config.Load(cfgPath)
+	// Load configuration, or show the project of the checkout without one
+	var cfg *config.Config
+	var err error
+	switch {
+	case cfgPath != "" && *replayDir != "":
+		// Replayed responses need no credentials
+		cfg, err = config.LoadForReplay(cfgPath)
+	case cfgPath != "":
+		cfg, err = config.Load(cfgPath)
+	default:
+		cfg, err = configForCheckout(local.Remote, *replayDir != "")
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading configuration: %v\n", err)
-		os.Exit(1)
+		return 1
+	}
+
+	// Create the API clients, one per organization
+	clients, err := tui.NewClients(cfg, tui.ClientOptions{
+		RecordDir: *recordDir,
+		ReplayDir: *replayDir,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating API client: %v\n", err)
+		return 1
 	}
 
-	// Create and run the TUI
-	model := tui.NewModel(cfg)
+	// Discover projects configured with "projects: auto" and resolve definitions selected by name
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	// Organizations whose projects cannot be listed start with the projects cached by the last run
+	projectsErr := discovery.Projects(ctx, cfg, clients)
+	// Projects whose definitions cannot be listed yet start empty and are resolved again in the TUI
+	warnings, definitionsErr := discovery.Definitions(ctx, cfg, clients)
+
+	// Create and run the TUI, opened on the project of the checkout. Problems are shown
+	// inside it, as the alternate screen hides anything printed before.
+	model := tui.NewModel(cfg, clients)
+	model.SetProjectsError(projectsErr)
+	model.AddWarnings(warnings...)
+	model.SetDefinitionsError(definitionsErr)
+	if local != nil {
+		model.SetLocalRepository(local)
+	}
 	p := tea.NewProgram(model, tea.WithAltScreen())
 
+	// Start the service hook listener and forward its events to the TUI
+	if cfg.Webhook.Enabled {
+		hooks := webhook.NewServer(webhook.Config{
+			Secret:   cfg.Webhook.Secret,
+			Username: cfg.Webhook.Username,
+			Password: cfg.Webhook.Password,
+		})
+		if err := hooks.Start(cfg.Webhook.Listen); err != nil {
+			fmt.Fprintf(os.Stderr, "Error starting webhook listener: %v\n", err)
+			return 1
+		}
+		defer hooks.Close()
+
+		go func() {
+			for event := range hooks.Events() {
+				p.Send(tui.WebhookEventMsg{Event: event})
+			}
+		}()
+	}
+
 	if _, err := p.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error running application: %v\n", err)
-		os.Exit(1)
+		return 1
+	}
+	return 0
+}
+
+// configForCheckout returns the configuration showing the project of a git remote.
+// When replaying, no credentials are looked up.
+func configForCheckout(remote localgit.Remote, replay bool) (*config.Config, error) {
+	ado := config.AzureDevOpsConfig{Organization: remote.Organization}
+	if !remote.IsCloud() {
+		ado = config.AzureDevOpsConfig{
+			Mode:          config.ModeServer,
+			CollectionURL: remote.CollectionURL(),
+		}
+	}
+	if replay {
+		// Command authentication resolves nothing up front and is replaced by the replay transport
+		ado.Auth.Type = config.AuthTypeCommand
 	}
+	return config.ForProject(ado, remote.Project)
 }
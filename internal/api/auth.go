@@ -0,0 +1,172 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/shell"
+)
+
+// AzureDevOpsResourceID is the Microsoft Entra ID resource (application) ID of Azure DevOps
+const AzureDevOpsResourceID = "499b84ac-1321-427f-aa17-267ca6975798"
+
+// DefaultTokenCommand fetches an Entra ID access token for Azure DevOps using the Azure CLI
+const DefaultTokenCommand = "az account get-access-token --resource " + AzureDevOpsResourceID + " --output json"
+
+const (
+	// tokenRefreshMargin is how long before expiry a cached token is refreshed
+	tokenRefreshMargin = 5 * time.Minute
+	// defaultTokenLifetime is assumed when a token command does not report an expiry
+	defaultTokenLifetime = 30 * time.Minute
+	// maxErrorSnippet is the longest command output kept in an error message, in bytes
+	maxErrorSnippet = 200
+)
+
+// AuthProvider supplies the Authorization header value for API requests
+type AuthProvider interface {
+	AuthorizationHeader(ctx context.Context) (string, error)
+}
+
+// PATAuth authenticates with a Personal Access Token sent as Basic auth
+type PATAuth struct {
+	PAT string
+}
+
+// AuthorizationHeader returns the Basic auth header for the PAT
+func (a PATAuth) AuthorizationHeader(ctx context.Context) (string, error) {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(":"+a.PAT)), nil
+}
+
+// BearerTokenAuth authenticates with a static OAuth / Entra ID access token
+type BearerTokenAuth struct {
+	Token string
+}
+
+// AuthorizationHeader returns the Bearer auth header for the token
+func (a BearerTokenAuth) AuthorizationHeader(ctx context.Context) (string, error) {
+	return "Bearer " + a.Token, nil
+}
+
+// CommandTokenAuth authenticates with an access token printed by an external command,
+// such as `az account get-access-token`. The token is cached and refreshed before it expires.
+type CommandTokenAuth struct {
+	command string
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+	now       func() time.Time
+}
+
+// NewCommandTokenAuth creates a token provider that runs the given shell command.
+// An empty command defaults to the Azure CLI.
+func NewCommandTokenAuth(command string) *CommandTokenAuth {
+	if command == "" {
+		command = DefaultTokenCommand
+	}
+	return &CommandTokenAuth{
+		command: command,
+		now:     time.Now,
+	}
+}
+
+// AuthorizationHeader returns the Bearer auth header, refreshing the token if needed
+func (a *CommandTokenAuth) AuthorizationHeader(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token == "" || a.now().Add(tokenRefreshMargin).After(a.expiresAt) {
+		token, expiresAt, err := a.fetchToken(ctx)
+		if err != nil {
+			return "", err
+		}
+		a.token = token
+		a.expiresAt = expiresAt
+	}
+
+	return "Bearer " + a.token, nil
+}
+
+// fetchToken runs the token command and parses its output
+func (a *CommandTokenAuth) fetchToken(ctx context.Context) (string, time.Time, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := shell.Command(ctx, a.command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return "", time.Time{}, fmt.Errorf("token command failed: %w", err)
+		}
+		// Stderr may echo the token or other credentials, so only a redacted snippet is kept
+		msg := errorSnippet(stderr.String())
+		if msg == "" {
+			return "", time.Time{}, fmt.Errorf("token command exited with status %d", exitErr.ExitCode())
+		}
+		return "", time.Time{}, fmt.Errorf("token command exited with status %d: %s", exitErr.ExitCode(), msg)
+	}
+
+	return parseTokenOutput(stdout.Bytes(), a.now())
+}
+
+// errorSnippet shortens command output to one redacted line for use in error messages
+func errorSnippet(output string) string {
+	msg := redactText(strings.Join(strings.Fields(output), " "))
+	if len(msg) <= maxErrorSnippet {
+		return msg
+	}
+	cut := maxErrorSnippet
+	for cut > 0 && !utf8.RuneStart(msg[cut]) {
+		cut--
+	}
+	return msg[:cut] + "…"
+}
+
+// tokenCommandOutput is the JSON printed by `az account get-access-token`
+type tokenCommandOutput struct {
+	AccessToken string `json:"accessToken"`
+	ExpiresOn   string `json:"expiresOn"`  // Local time, e.g. "2024-01-31 12:34:56.000000"
+	ExpiresOnTS int64  `json:"expires_on"` // Unix timestamp (newer Azure CLI versions)
+}
+
+// parseTokenOutput extracts the token and its expiry from a token command's output.
+// Both Azure CLI JSON and a bare token on stdout are supported.
+func parseTokenOutput(output []byte, now time.Time) (string, time.Time, error) {
+	trimmed := bytes.TrimSpace(output)
+	if len(trimmed) == 0 {
+		return "", time.Time{}, errors.New("token command produced no output")
+	}
+
+	if trimmed[0] != '{' {
+		return string(trimmed), now.Add(defaultTokenLifetime), nil
+	}
+
+	var parsed tokenCommandOutput
+	if err := json.Unmarshal(trimmed, &parsed); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to parse token command output: %w", err)
+	}
+	if parsed.AccessToken == "" {
+		return "", time.Time{}, errors.New("token command output has no accessToken")
+	}
+
+	expiresAt := now.Add(defaultTokenLifetime)
+	if parsed.ExpiresOnTS > 0 {
+		expiresAt = time.Unix(parsed.ExpiresOnTS, 0)
+	} else if parsed.ExpiresOn != "" {
+		if t, err := time.ParseInLocation("2006-01-02 15:04:05.999999", parsed.ExpiresOn, time.Local); err == nil {
+			expiresAt = t
+		}
+	}
+
+	return parsed.AccessToken, expiresAt, nil
+}
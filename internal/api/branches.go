@@ -0,0 +1,127 @@
+package api
+
+import "strings"
+
+const (
+	// branchScanSize is the number of recent builds or pull requests requested per page when
+	// branch patterns or other filters have to be applied client-side
+	branchScanSize = 200
+	// branchScanLimit caps the builds or pull requests scanned for enough matches
+	branchScanLimit = 2000
+)
+
+// BranchFilter selects refs by include and exclude patterns.
+// Patterns without a "refs/" prefix are branch names, so "main" means "refs/heads/main".
+// "*" matches any characters including "/", "?" matches one character and a leading "!"
+// excludes matching refs, e.g. "release/*", "refs/pull/*" or "!dependabot/*".
+// Matching is case-insensitive, like branch names in Azure DevOps.
+type BranchFilter struct {
+	include []string
+	exclude []string
+}
+
+// NewBranchFilter parses branch patterns. Empty patterns are ignored.
+func NewBranchFilter(patterns []string) BranchFilter {
+	var f BranchFilter
+	for _, p := range patterns {
+		p = strings.TrimSpace(p)
+		excluded := strings.HasPrefix(p, "!")
+		p = strings.TrimSpace(strings.TrimPrefix(p, "!"))
+		if p == "" {
+			continue
+		}
+
+		ref := branchRef(p)
+		if excluded {
+			f.exclude = appendUniqueString(f.exclude, ref)
+		} else {
+			f.include = appendUniqueString(f.include, ref)
+		}
+	}
+	return f
+}
+
+// IsEmpty returns true if the filter selects every ref
+func (f BranchFilter) IsEmpty() bool {
+	return len(f.include) == 0 && len(f.exclude) == 0
+}
+
+// Matches returns true if ref matches an include pattern (or there are none) and no exclude pattern
+func (f BranchFilter) Matches(ref string) bool {
+	ref = strings.ToLower(ref)
+	for _, p := range f.exclude {
+		if globMatch(strings.ToLower(p), ref) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, p := range f.include {
+		if globMatch(strings.ToLower(p), ref) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExactRefs returns the included refs if every include pattern is a plain ref without wildcards,
+// so the server can filter by them. Exclude patterns still have to be applied with Matches.
+func (f BranchFilter) ExactRefs() ([]string, bool) {
+	if len(f.include) == 0 {
+		return nil, false
+	}
+	for _, p := range f.include {
+		if strings.ContainsAny(p, "*?") {
+			return nil, false
+		}
+	}
+	return f.include, true
+}
+
+// branchRef converts a branch name to a full ref; refs such as "refs/pull/1/merge" are kept
+func branchRef(branch string) string {
+	if strings.HasPrefix(branch, "refs/") {
+		return branch
+	}
+	return "refs/heads/" + branch
+}
+
+// globMatch reports whether s matches pattern, where "*" matches any sequence of characters
+// (including "/") and "?" matches a single character
+func globMatch(pattern, s string) bool {
+	p, str := []rune(pattern), []rune(s)
+	pi, si := 0, 0
+	star, mark := -1, 0
+
+	for si < len(str) {
+		switch {
+		case pi < len(p) && (p[pi] == '?' || p[pi] == str[si]):
+			pi++
+			si++
+		case pi < len(p) && p[pi] == '*':
+			star, mark = pi, si
+			pi++
+		case star >= 0:
+			// Let the last star consume one more character
+			mark++
+			pi, si = star+1, mark
+		default:
+			return false
+		}
+	}
+	for pi < len(p) && p[pi] == '*' {
+		pi++
+	}
+	return pi == len(p)
+}
+
+// appendUniqueString appends s unless it is already present, ignoring case
+func appendUniqueString(list []string, s string) []string {
+	for _, existing := range list {
+		if strings.EqualFold(existing, s) {
+			return list
+		}
+	}
+	return append(list, s)
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBranchFilterMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		ref      string
+		want     bool
+	}{
+		{"no patterns", nil, "refs/heads/anything", true},
+		{"branch name", []string{"main"}, "refs/heads/main", true},
+		{"full ref", []string{"refs/heads/main"}, "refs/heads/main", true},
+		{"other branch", []string{"main"}, "refs/heads/develop", false},
+		{"case-insensitive", []string{"Main"}, "refs/heads/main", true},
+		{"glob", []string{"release/*"}, "refs/heads/release/2024.1", true},
+		{"glob across slashes", []string{"feature/payments-*"}, "refs/heads/feature/payments-refunds/v2", true},
+		{"glob mismatch", []string{"feature/payments-*"}, "refs/heads/feature/orders", false},
+		{"question mark", []string{"release/v?"}, "refs/heads/release/v2", true},
+		{"pull request builds", []string{"refs/pull/*"}, "refs/pull/42/merge", true},
+		{"pull request ref is not a branch", []string{"*"}, "refs/pull/42/merge", false},
+		{"only exclusions", []string{"!dependabot/*"}, "refs/heads/main", true},
+		{"excluded", []string{"!dependabot/*"}, "refs/heads/dependabot/npm/lodash", false},
+		{"exclusion wins", []string{"*", "!dependabot/*"}, "refs/heads/dependabot/go", false},
+		{"included despite exclusion", []string{"*", "!dependabot/*"}, "refs/heads/main", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewBranchFilter(tt.patterns).Matches(tt.ref); got != tt.want {
+				t.Errorf("NewBranchFilter(%q).Matches(%q) = %v, want %v", tt.patterns, tt.ref, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBranchFilterExactRefs(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		want     []string
+		ok       bool
+	}{
+		{nil, nil, false},
+		{[]string{"main", "refs/heads/main", "develop"}, []string{"refs/heads/main", "refs/heads/develop"}, true},
+		{[]string{"main", "!dependabot/*"}, []string{"refs/heads/main"}, true},
+		{[]string{"main", "release/*"}, nil, false},
+		{[]string{"!dependabot/*"}, nil, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := NewBranchFilter(tt.patterns).ExactRefs()
+		if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("NewBranchFilter(%q).ExactRefs() = %q, %v; want %q, %v", tt.patterns, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"strings"
+)
+
+// has returns true if the change type includes t; change types are comma separated flags
+func (c VersionControlChangeType) has(t VersionControlChangeType) bool {
+	for _, part := range strings.Split(string(c), ",") {
+		if VersionControlChangeType(strings.TrimSpace(part)) == t {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAdd returns true if the file was added
+func (c *PullRequestChange) IsAdd() bool {
+	return c.ChangeType.has(ChangeTypeAdd)
+}
+
+// IsDelete returns true if the file was deleted
+func (c *PullRequestChange) IsDelete() bool {
+	return c.ChangeType.has(ChangeTypeDelete)
+}
+
+// IsRename returns true if the file was moved
+func (c *PullRequestChange) IsRename() bool {
+	return c.ChangeType.has(ChangeTypeRename)
+}
+
+// GetMarker returns a one letter marker for the change: A(dded), D(eleted), R(enamed) or M(odified)
+func (c *PullRequestChange) GetMarker() string {
+	switch {
+	case c.IsAdd():
+		return "A"
+	case c.IsDelete():
+		return "D"
+	case c.IsRename():
+		return "R"
+	default:
+		return "M"
+	}
+}
+
+// GetPathDisplay returns the path of the file, with the original path for renames
+func (c *PullRequestChange) GetPathDisplay() string {
+	if c.IsRename() && c.OriginalPath != "" {
+		return c.OriginalPath + " -> " + c.Item.Path
+	}
+	return c.Item.Path
+}
+
+// OldObjectID returns the blob of the file before the change, empty for added files
+func (c *PullRequestChange) OldObjectID() string {
+	if c.IsAdd() {
+		return ""
+	}
+	return c.Item.OriginalObjectID
+}
+
+// NewObjectID returns the blob of the file after the change, empty for deleted files
+func (c *PullRequestChange) NewObjectID() string {
+	if c.IsDelete() {
+		return ""
+	}
+	return c.Item.ObjectID
+}
+
+// FileChanges returns the changed files of an iteration, leaving out folders
+func FileChanges(changes []PullRequestChange) []PullRequestChange {
+	var files []PullRequestChange
+	for _, c := range changes {
+		if !c.Item.IsFolder && c.Item.GitObjectType != "tree" {
+			files = append(files, c)
+		}
+	}
+	return files
+}
+
+// LatestIteration returns the iteration with the highest ID, nil if there are none
+func LatestIteration(iterations []PullRequestIteration) *PullRequestIteration {
+	var latest *PullRequestIteration
+	for i := range iterations {
+		if latest == nil || iterations[i].ID > latest.ID {
+			latest = &iterations[i]
+		}
+	}
+	return latest
+}
@@ -1,64 +1,310 @@
 package api
 
 import (
+	"bytes"
 	"context"
-	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"sort"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"golang.org/x/time/rate"
 )
 
+// DefaultAPIVersion is the REST API version requested from Azure DevOps Services
+const DefaultAPIVersion = "7.0"
+
+const (
+	// closedPullRequestWindow is how far back completed and abandoned pull requests are searched
+	closedPullRequestWindow = 30 * 24 * time.Hour
+	// closedPullRequestLimit caps the closed pull requests fetched from one repository or project
+	closedPullRequestLimit = 1000
+	// policyConcurrency is how many pull requests have their policy evaluations fetched at once
+	policyConcurrency = 8
+)
+
+// fallbackAPIVersions lists the API versions tried, newest first, when a server
+// rejects the requested version (e.g. older Azure DevOps Server / TFS installations)
+var fallbackAPIVersions = []string{"7.0", "6.0", "5.1", "5.0", "4.1"}
+
 // Client is the Azure DevOps API client
 type Client struct {
-	httpClient   *http.Client
-	baseURL      string
-	organization string
-	authHeader   string
-	limiter      *rate.Limiter
+	httpClient  *http.Client
+	orgURL      string // Organization (or collection) URL used for build, git and web URLs
+	releaseURL  string // Organization (or collection) URL used for release management APIs
+	identityURL string // Organization (or collection) URL used for identity search
+	auth        AuthProvider
+	limiter     *rate.Limiter
+
+	versionMu      sync.RWMutex
+	apiVersion     string
+	previewVersion string // Negotiated separately, as preview APIs may be rejected at versions others accept
+	pinnedVersion  bool
 }
 
 // ClientConfig holds configuration for creating a new client
 type ClientConfig struct {
 	Organization      string
 	BaseURL           string
-	PAT               string
+	CollectionURL     string       // Azure DevOps Server: collection URL, replaces BaseURL + Organization
+	ReleaseURL        string       // Optional override for the release management base URL
+	Server            bool         // Azure DevOps Server (on-premises) mode
+	APIVersion        string       // Optional: pin the API version instead of negotiating it
+	PAT               string       // Used when Auth is nil
+	Auth              AuthProvider // Optional: authentication provider (defaults to PAT)
 	RequestsPerSecond float64
 	BurstSize         int
 	Timeout           time.Duration
+	Transport         http.RoundTripper // Optional: custom transport (proxy, TLS); defaults to http.DefaultTransport
+}
+
+// APIError represents a non-successful response from the Azure DevOps API
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Message())
+}
+
+// Message returns a short, readable description of the error.
+// JSON error messages are extracted and HTML pages (e.g. sign-in pages) are not shown verbatim.
+func (e *APIError) Message() string {
+	if e.StatusCode == http.StatusNonAuthoritativeInfo {
+		return "credentials were rejected (sign-in page returned)"
+	}
+
+	body := strings.TrimSpace(e.Body)
+
+	var parsed struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal([]byte(body), &parsed); err == nil && parsed.Message != "" {
+		return parsed.Message
+	}
+
+	if body == "" || strings.HasPrefix(body, "<") {
+		return http.StatusText(e.StatusCode)
+	}
+
+	if len(body) > 200 {
+		// Cut on a rune boundary so the message stays valid UTF-8
+		cut := 200
+		for cut > 0 && !utf8.RuneStart(body[cut]) {
+			cut--
+		}
+		return body[:cut] + "..."
+	}
+	return body
+}
+
+// TypeKey returns the exception type reported in a JSON error body, e.g.
+// "UnauthorizedRequestException", or an empty string
+func (e *APIError) TypeKey() string {
+	var parsed struct {
+		TypeKey string `json:"typeKey"`
+	}
+	if err := json.Unmarshal([]byte(e.Body), &parsed); err != nil {
+		return ""
+	}
+	return parsed.TypeKey
+}
+
+// IsUnauthorized returns true if the credentials were rejected
+func (e *APIError) IsUnauthorized() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusNonAuthoritativeInfo
+}
+
+// isAPIVersionError returns true if the server rejected the requested api-version
+func isAPIVersionError(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		return false
+	}
+	return strings.Contains(apiErr.Body, "VssVersionOutOfRangeException") ||
+		strings.Contains(apiErr.Body, "VssInvalidPreviewVersionException") ||
+		strings.Contains(apiErr.Body, "is out of range")
 }
 
 // NewClient creates a new Azure DevOps API client
 func NewClient(cfg ClientConfig) *Client {
-	// Create Basic Auth header with PAT
-	auth := base64.StdEncoding.EncodeToString([]byte(":" + cfg.PAT))
+	// Default to Basic auth with the PAT
+	auth := cfg.Auth
+	if auth == nil {
+		auth = PATAuth{PAT: cfg.PAT}
+	}
 
 	timeout := cfg.Timeout
 	if timeout == 0 {
 		timeout = 30 * time.Second
 	}
 
+	orgURL := strings.TrimSuffix(cfg.CollectionURL, "/")
+	if orgURL == "" {
+		orgURL = strings.TrimSuffix(cfg.BaseURL, "/") + "/" + cfg.Organization
+	}
+
+	// Azure DevOps Services serves releases from vsrm.dev.azure.com,
+	// Azure DevOps Server serves them from the collection URL itself
+	releaseURL := strings.TrimSuffix(cfg.ReleaseURL, "/")
+	if releaseURL == "" {
+		if cfg.Server {
+			releaseURL = orgURL
+		} else {
+			releaseURL = serviceURL(orgURL, "vsrm")
+		}
+	}
+
+	// Azure DevOps Services serves identities from vssps.dev.azure.com
+	identityURL := orgURL
+	if !cfg.Server {
+		identityURL = serviceURL(orgURL, "vssps")
+	}
+
+	apiVersion := cfg.APIVersion
+	if apiVersion == "" {
+		apiVersion = DefaultAPIVersion
+	}
+
 	return &Client{
 		httpClient: &http.Client{
-			Timeout: timeout,
+			Timeout:   timeout,
+			Transport: cfg.Transport,
 		},
-		baseURL:      strings.TrimSuffix(cfg.BaseURL, "/"),
-		organization: cfg.Organization,
-		authHeader:   "Basic " + auth,
-		limiter:      rate.NewLimiter(rate.Limit(cfg.RequestsPerSecond), cfg.BurstSize),
+		orgURL:         orgURL,
+		releaseURL:     releaseURL,
+		identityURL:    identityURL,
+		auth:           auth,
+		limiter:        rate.NewLimiter(rate.Limit(cfg.RequestsPerSecond), cfg.BurstSize),
+		apiVersion:     apiVersion,
+		previewVersion: apiVersion,
+		pinnedVersion:  cfg.APIVersion != "",
+	}
+}
+
+// serviceURL returns the URL of an Azure DevOps Services service host for an organization,
+// e.g. https://vssps.dev.azure.com/{org} or https://{org}.vssps.visualstudio.com
+func serviceURL(orgURL, service string) string {
+	u, err := url.Parse(orgURL)
+	if err != nil {
+		return orgURL
 	}
+	host := strings.ToLower(u.Host)
+	switch {
+	case host == "dev.azure.com":
+		u.Host = service + ".dev.azure.com"
+	case strings.HasSuffix(host, ".visualstudio.com"):
+		u.Host = strings.TrimSuffix(host, ".visualstudio.com") + "." + service + ".visualstudio.com"
+	}
+	return u.String()
+}
+
+// APIVersion returns the API version currently used for requests
+func (c *Client) APIVersion() string {
+	c.versionMu.RLock()
+	defer c.versionMu.RUnlock()
+	return c.apiVersion
+}
+
+// previewAPIVersion returns the API version currently used for preview APIs, without the -preview suffix
+func (c *Client) previewAPIVersion() string {
+	c.versionMu.RLock()
+	defer c.versionMu.RUnlock()
+	return c.previewVersion
+}
+
+// downgradeAPIVersion switches to the next older API version after the server rejected
+// the given one. Returns false if there is nothing left to try.
+func (c *Client) downgradeAPIVersion(rejected string) bool {
+	return c.downgradeVersion(&c.apiVersion, rejected)
+}
+
+// downgradePreviewVersion is downgradeAPIVersion for the version used for preview APIs
+func (c *Client) downgradePreviewVersion(rejected string) bool {
+	return c.downgradeVersion(&c.previewVersion, rejected)
+}
+
+// downgradeVersion switches version to the one after rejected in fallbackAPIVersions
+func (c *Client) downgradeVersion(version *string, rejected string) bool {
+	if c.pinnedVersion {
+		return false
+	}
+
+	c.versionMu.Lock()
+	defer c.versionMu.Unlock()
+
+	// Another request may already have negotiated a lower version
+	if *version != rejected {
+		return true
+	}
+
+	for i, v := range fallbackAPIVersions {
+		if v == rejected && i+1 < len(fallbackAPIVersions) {
+			*version = fallbackAPIVersions[i+1]
+			return true
+		}
+	}
+	return false
 }
 
-// doRequest performs an HTTP request with rate limiting and retries
+// withAPIVersion appends the api-version query parameter to a request URL
+func withAPIVersion(url, version string) string {
+	if strings.Contains(url, "?") {
+		return url + "&api-version=" + version
+	}
+	return url + "?api-version=" + version
+}
+
+// doRequest performs an HTTP request, negotiating the API version with the server
 func (c *Client) doRequest(ctx context.Context, url string) ([]byte, error) {
+	body, _, err := c.doPagedRequest(ctx, url)
+	return body, err
+}
+
+// doPagedRequest performs an HTTP request like doRequest and also returns the continuation
+// token of the next page, which is empty on the last page
+func (c *Client) doPagedRequest(ctx context.Context, url string) ([]byte, string, error) {
+	for {
+		version := c.APIVersion()
+		body, header, err := c.doRequestWithRetry(ctx, withAPIVersion(url, version))
+		if err == nil || !isAPIVersionError(err) {
+			return body, header.Get("x-ms-continuationtoken"), err
+		}
+		if !c.downgradeAPIVersion(version) {
+			return nil, "", err
+		}
+	}
+}
+
+// doPreviewRequest performs an HTTP request to an API that is only available as a preview version.
+// Rejected preview versions do not downgrade the version used for other requests.
+func (c *Client) doPreviewRequest(ctx context.Context, url string) ([]byte, error) {
+	for {
+		version := c.previewAPIVersion()
+		body, _, err := c.doRequestWithRetry(ctx, withAPIVersion(url, version+"-preview.1"))
+		if err == nil || !isAPIVersionError(err) {
+			return body, err
+		}
+		if !c.downgradePreviewVersion(version) {
+			return nil, err
+		}
+	}
+}
+
+// doRequestWithRetry performs an HTTP request with rate limiting and retries and returns the
+// body and headers of the response
+func (c *Client) doRequestWithRetry(ctx context.Context, url string) ([]byte, http.Header, error) {
 	// Wait for rate limiter
 	if err := c.limiter.Wait(ctx); err != nil {
-		return nil, fmt.Errorf("rate limiter error: %w", err)
+		return nil, nil, fmt.Errorf("rate limiter error: %w", err)
 	}
 
 	// Retry with exponential backoff
@@ -69,91 +315,129 @@ func (c *Client) doRequest(ctx context.Context, url string) ([]byte, error) {
 			backoff := time.Duration(1<<uint(attempt-1)) * time.Second
 			select {
 			case <-ctx.Done():
-				return nil, ctx.Err()
+				return nil, nil, ctx.Err()
 			case <-time.After(backoff):
 			}
 		}
 
-		body, err := c.doSingleRequest(ctx, url)
+		body, header, err := c.doSingleRequest(ctx, http.MethodGet, url, nil)
 		if err == nil {
-			return body, nil
+			return body, header, nil
 		}
 
 		lastErr = err
 
 		// Don't retry on context cancellation or client errors (4xx)
 		if ctx.Err() != nil {
-			return nil, ctx.Err()
+			return nil, nil, ctx.Err()
+		}
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode >= 400 && apiErr.StatusCode < 500 &&
+			apiErr.StatusCode != http.StatusTooManyRequests {
+			return nil, nil, err
 		}
 	}
 
-	return nil, fmt.Errorf("request failed after 3 attempts: %w", lastErr)
+	return nil, nil, fmt.Errorf("request failed after 3 attempts: %w", lastErr)
+}
+
+// doWriteRequest sends a JSON payload with the given method, negotiating the API version.
+// Writes are not idempotent, so failed requests are not retried.
+func (c *Client) doWriteRequest(ctx context.Context, method, url string, payload any) ([]byte, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limiter error: %w", err)
+	}
+
+	for {
+		version := c.APIVersion()
+		body, _, err := c.doSingleRequest(ctx, method, withAPIVersion(url, version), data)
+		if err == nil || !isAPIVersionError(err) {
+			return body, err
+		}
+		if !c.downgradeAPIVersion(version) {
+			return nil, err
+		}
+	}
 }
 
-func (c *Client) doSingleRequest(ctx context.Context, url string) ([]byte, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+func (c *Client) doSingleRequest(ctx context.Context, method, url string, payload []byte) ([]byte, http.Header, error) {
+	var reqBody io.Reader
+	if payload != nil {
+		reqBody = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
 	}
 
-	req.Header.Set("Authorization", c.authHeader)
+	authHeader, err := c.auth.AuthorizationHeader(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to authenticate: %w", err)
+	}
+
+	req.Header.Set("Authorization", authHeader)
 	req.Header.Set("Accept", "application/json")
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+	// Azure DevOps answers rejected credentials with a 203 sign-in page instead of a 401
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 || resp.StatusCode == http.StatusNonAuthoritativeInfo {
+		return nil, nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
-	return body, nil
+	return body, resp.Header, nil
 }
 
-// GetBuilds fetches builds for a project
+// GetBuilds fetches builds for a project.
+// Branches are include/exclude patterns (see BranchFilter). Plain branch names are requested
+// per branch using the server-side branchName filter and merged by queue time, so quiet
+// branches are not crowded out. Wildcard patterns are applied to recent builds page by page
+// until maxCount match or branchScanLimit builds have been scanned.
 func (c *Client) GetBuilds(ctx context.Context, project string, definitionIDs []int, branches []string, maxCount int) ([]Build, error) {
-	// Fetch more items if we need to filter by branches (to ensure we get enough results after filtering)
-	fetchCount := maxCount
-	if len(branches) > 0 {
-		fetchCount = maxCount * 5 // Fetch more to account for filtering
-		if fetchCount > 100 {
-			fetchCount = 100
-		}
-	}
+	var builds []Build
+	filter := NewBranchFilter(branches)
 
-	url := fmt.Sprintf("%s/%s/%s/_apis/build/builds?api-version=7.0&$top=%d&statusFilter=all&queryOrder=queueTimeDescending",
-		c.baseURL, c.organization, project, fetchCount)
-
-	if len(definitionIDs) > 0 {
-		ids := make([]string, len(definitionIDs))
-		for i, id := range definitionIDs {
-			ids[i] = fmt.Sprintf("%d", id)
+	if refs, ok := filter.ExactRefs(); ok {
+		for _, ref := range refs {
+			result, err := c.getBuildsForBranch(ctx, project, definitionIDs, ref, maxCount)
+			if err != nil {
+				return nil, err
+			}
+			builds = append(builds, result...)
 		}
-		url += "&definitions=" + strings.Join(ids, ",")
-	}
-
-	body, err := c.doRequest(ctx, url)
-	if err != nil {
-		return nil, err
-	}
-
-	var response BuildsResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("failed to parse builds response: %w", err)
-	}
 
-	builds := response.Value
-
-	// Filter by branches if specified
-	if len(branches) > 0 {
-		builds = filterBuildsByBranches(builds, branches)
+		// Merge results from all branches, newest first
+		sort.SliceStable(builds, func(i, j int) bool {
+			return builds[i].QueueTime.After(builds[j].QueueTime)
+		})
+	} else if filter.IsEmpty() {
+		result, err := c.getBuildsForBranch(ctx, project, definitionIDs, "", maxCount)
+		if err != nil {
+			return nil, err
+		}
+		builds = result
+	} else {
+		result, err := c.scanBuilds(ctx, project, definitionIDs, filter, maxCount)
+		if err != nil {
+			return nil, err
+		}
+		builds = result
 	}
 
 	// Limit results to maxCount
@@ -178,30 +462,75 @@ func (c *Client) GetBuilds(ctx context.Context, project string, definitionIDs []
 	return builds, nil
 }
 
-// filterBuildsByBranches filters builds to only include those from specified branches
-func filterBuildsByBranches(builds []Build, branches []string) []Build {
-	// Create a map for quick branch lookup (normalize branch names)
-	branchMap := make(map[string]bool)
-	for _, branch := range branches {
-		// Support both "main" and "refs/heads/main" formats
-		normalized := strings.TrimPrefix(branch, "refs/heads/")
-		branchMap[normalized] = true
-		branchMap["refs/heads/"+normalized] = true
+// scanBuilds fetches recent builds page by page and keeps those of branches matching the filter,
+// until maxCount match, the last page has been fetched or branchScanLimit builds have been scanned
+func (c *Client) scanBuilds(ctx context.Context, project string, definitionIDs []int, filter BranchFilter, maxCount int) ([]Build, error) {
+	pageSize := max(maxCount, branchScanSize)
+
+	var matching []Build
+	continuation := ""
+	for scanned := 0; scanned < branchScanLimit; scanned += pageSize {
+		page, next, err := c.getBuildsPage(ctx, project, definitionIDs, "", pageSize, continuation)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range page {
+			if filter.Matches(b.SourceBranch) {
+				matching = append(matching, b)
+			}
+		}
+		if len(matching) >= maxCount || next == "" || next == continuation {
+			break
+		}
+		continuation = next
 	}
+	return matching, nil
+}
+
+// getBuildsForBranch fetches the most recent builds, optionally restricted to a single branch ref
+func (c *Client) getBuildsForBranch(ctx context.Context, project string, definitionIDs []int, branchRef string, maxCount int) ([]Build, error) {
+	builds, _, err := c.getBuildsPage(ctx, project, definitionIDs, branchRef, maxCount, "")
+	return builds, err
+}
+
+// getBuildsPage fetches a page of the most recent builds, optionally restricted to a single branch
+// ref, and returns the continuation token of the next page
+func (c *Client) getBuildsPage(ctx context.Context, project string, definitionIDs []int, branchRef string, maxCount int, continuation string) ([]Build, string, error) {
+	reqURL := fmt.Sprintf("%s/%s/_apis/build/builds?$top=%d&statusFilter=all&queryOrder=queueTimeDescending",
+		c.orgURL, project, maxCount)
 
-	var filtered []Build
-	for _, build := range builds {
-		if branchMap[build.SourceBranch] {
-			filtered = append(filtered, build)
+	if len(definitionIDs) > 0 {
+		ids := make([]string, len(definitionIDs))
+		for i, id := range definitionIDs {
+			ids[i] = fmt.Sprintf("%d", id)
 		}
+		reqURL += "&definitions=" + strings.Join(ids, ",")
+	}
+
+	if branchRef != "" {
+		reqURL += "&branchName=" + url.QueryEscape(branchRef)
+	}
+	if continuation != "" {
+		reqURL += "&continuationToken=" + url.QueryEscape(continuation)
+	}
+
+	body, next, err := c.doPagedRequest(ctx, reqURL)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var response BuildsResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, "", fmt.Errorf("failed to parse builds response: %w", err)
 	}
-	return filtered
+
+	return response.Value, next, nil
 }
 
 // GetBuildTimeline fetches the timeline records for a build and returns only Stage-type records
 func (c *Client) GetBuildTimeline(ctx context.Context, project string, buildID int) ([]BuildTimelineRecord, error) {
-	url := fmt.Sprintf("%s/%s/%s/_apis/build/builds/%d/timeline?api-version=7.0",
-		c.baseURL, c.organization, project, buildID)
+	url := fmt.Sprintf("%s/%s/_apis/build/builds/%d/timeline",
+		c.orgURL, project, buildID)
 
 	body, err := c.doRequest(ctx, url)
 	if err != nil {
@@ -233,11 +562,9 @@ func (c *Client) GetBuildTimeline(ctx context.Context, project string, buildID i
 
 // GetReleases fetches releases for a project
 func (c *Client) GetReleases(ctx context.Context, project string, definitionIDs []int, maxCount int) ([]Release, error) {
-	// Note: Releases API uses a different base URL (vsrm.dev.azure.com)
-	releaseURL := strings.Replace(c.baseURL, "dev.azure.com", "vsrm.dev.azure.com", 1)
-
-	url := fmt.Sprintf("%s/%s/%s/_apis/release/releases?api-version=7.0&$top=%d&$expand=environments",
-		releaseURL, c.organization, project, maxCount)
+	// Note: Releases API uses a different base URL (vsrm.dev.azure.com) on Azure DevOps Services
+	url := fmt.Sprintf("%s/%s/_apis/release/releases?$top=%d&$expand=environments",
+		c.releaseURL, project, maxCount)
 
 	if len(definitionIDs) > 0 {
 		ids := make([]string, len(definitionIDs))
@@ -260,34 +587,180 @@ func (c *Client) GetReleases(ctx context.Context, project string, definitionIDs
 	return response.Value, nil
 }
 
+// GetRelease fetches a release with the deploy attempts, approvals, gates and tasks of its environments
+func (c *Client) GetRelease(ctx context.Context, project string, releaseID int) (*Release, error) {
+	body, err := c.doRequest(ctx, fmt.Sprintf("%s/%s/_apis/release/releases/%d", c.releaseURL, project, releaseID))
+	if err != nil {
+		return nil, err
+	}
+
+	var release Release
+	if err := json.Unmarshal(body, &release); err != nil {
+		return nil, fmt.Errorf("failed to parse release response: %w", err)
+	}
+	return &release, nil
+}
+
+// GetReleaseTaskLog fetches the log of a task of a deploy phase
+func (c *Client) GetReleaseTaskLog(ctx context.Context, project string, releaseID, environmentID, deployPhaseID, taskID int) (string, error) {
+	body, err := c.doRequest(ctx, fmt.Sprintf("%s/%s/_apis/release/releases/%d/environments/%d/deployPhases/%d/tasks/%d/logs",
+		c.releaseURL, project, releaseID, environmentID, deployPhaseID, taskID))
+	if err != nil {
+		return "", err
+	}
+
+	// The log is plain text, but some servers honor the JSON Accept header and return its lines
+	var lines struct {
+		Value []string `json:"value"`
+	}
+	if len(body) > 0 && body[0] == '{' && json.Unmarshal(body, &lines) == nil {
+		return strings.Join(lines.Value, "\n"), nil
+	}
+	return string(body), nil
+}
+
+// GetSuccessfulBuilds fetches the most recent successful builds of a definition, without their stages
+func (c *Client) GetSuccessfulBuilds(ctx context.Context, project string, definitionID int, maxCount int) ([]Build, error) {
+	reqURL := fmt.Sprintf("%s/%s/_apis/build/builds?definitions=%d&statusFilter=completed&resultFilter=succeeded&queryOrder=finishTimeDescending&$top=%d",
+		c.orgURL, project, definitionID, maxCount)
+
+	body, err := c.doRequest(ctx, reqURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var response BuildsResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse builds response: %w", err)
+	}
+	return response.Value, nil
+}
+
+// CreateRelease creates a release of a definition, which starts the automated deployments
+func (c *Client) CreateRelease(ctx context.Context, project string, release NewRelease) (*Release, error) {
+	reqURL := fmt.Sprintf("%s/%s/_apis/release/releases", c.releaseURL, project)
+
+	body, err := c.doWriteRequest(ctx, http.MethodPost, reqURL, release)
+	if err != nil {
+		return nil, err
+	}
+
+	var created Release
+	if err := json.Unmarshal(body, &created); err != nil {
+		return nil, fmt.Errorf("failed to parse release response: %w", err)
+	}
+	return &created, nil
+}
+
 // GetBuildWebURL returns the web URL for a build
 func (c *Client) GetBuildWebURL(project string, buildID int) string {
-	return fmt.Sprintf("%s/%s/%s/_build/results?buildId=%d",
-		c.baseURL, c.organization, project, buildID)
+	return fmt.Sprintf("%s/%s/_build/results?buildId=%d",
+		c.orgURL, project, buildID)
 }
 
 // GetReleaseWebURL returns the web URL for a release
 func (c *Client) GetReleaseWebURL(project string, releaseID int) string {
-	return fmt.Sprintf("%s/%s/%s/_releaseProgress?releaseId=%d",
-		c.baseURL, c.organization, project, releaseID)
+	return fmt.Sprintf("%s/%s/_releaseProgress?releaseId=%d",
+		c.orgURL, project, releaseID)
+}
+
+// PullRequestCriteria narrows down the pull requests returned by GetPullRequests
+type PullRequestCriteria struct {
+	Status         PullRequestStatus // active (default), completed, abandoned or all
+	TargetBranches []string          // Include/exclude patterns for the target branch (see BranchFilter)
+	CreatorID      string            // Only pull requests created by this identity
+	ReviewerID     string            // Only pull requests with this identity as a reviewer
+	ExcludeDrafts  bool              // Leave out draft pull requests
+	SourceBranch   string            // Only pull requests from this branch, a full ref
+}
+
+// status returns the status to search for
+func (c PullRequestCriteria) status() PullRequestStatus {
+	if c.Status == "" {
+		return PullRequestStatusActive
+	}
+	return c.Status
 }
 
-// GetPullRequests fetches pull requests for a project
-func (c *Client) GetPullRequests(ctx context.Context, project string, repositories []string, maxCount int) ([]PullRequest, error) {
+// Matches returns true if a pull request meets the criteria. Used to filter client-side
+// what the search criteria of the API cannot express.
+func (c PullRequestCriteria) Matches(pr PullRequest) bool {
+	if status := c.status(); status != PullRequestStatusAll && pr.Status != status {
+		return false
+	}
+	if c.ExcludeDrafts && pr.IsDraft {
+		return false
+	}
+	if c.CreatorID != "" && !strings.EqualFold(pr.CreatedBy.ID, c.CreatorID) {
+		return false
+	}
+	if c.ReviewerID != "" && !pr.HasReviewer(c.ReviewerID) {
+		return false
+	}
+	if c.SourceBranch != "" && !strings.EqualFold(pr.SourceRefName, c.SourceBranch) {
+		return false
+	}
+	return NewBranchFilter(c.TargetBranches).Matches(pr.TargetRefName)
+}
+
+// GetPullRequests fetches the pull requests of a project with the given status.
+// Creator, reviewer, source branch and a single plain target branch are filtered server-side; other target
+// branch patterns and drafts are applied to recent pull requests page by page until maxCount match or
+// branchScanLimit have been scanned. Completed and abandoned pull requests are those closed within the
+// last 30 days, most recently closed first.
+func (c *Client) GetPullRequests(ctx context.Context, project string, repositories []string, criteria PullRequestCriteria, maxCount int) ([]PullRequest, error) {
+	filter := NewBranchFilter(criteria.TargetBranches)
+
+	query := "searchCriteria.status=" + string(criteria.status())
+	if criteria.CreatorID != "" {
+		query += "&searchCriteria.creatorId=" + url.QueryEscape(criteria.CreatorID)
+	}
+	if criteria.ReviewerID != "" {
+		query += "&searchCriteria.reviewerId=" + url.QueryEscape(criteria.ReviewerID)
+	}
+	if criteria.SourceBranch != "" {
+		query += "&searchCriteria.sourceRefName=" + url.QueryEscape(criteria.SourceBranch)
+	}
+
+	scan := criteria.ExcludeDrafts
+	if refs, ok := filter.ExactRefs(); ok && len(refs) == 1 {
+		query += "&searchCriteria.targetRefName=" + url.QueryEscape(refs[0])
+	} else if !filter.IsEmpty() {
+		scan = true
+	}
+
+	fetch := func(baseURL string) ([]PullRequest, error) {
+		if scan {
+			return c.scanPullRequests(ctx, baseURL, query, criteria, maxCount)
+		}
+		return c.getPullRequests(ctx, baseURL, query, maxCount)
+	}
+
+	// The server orders pull requests by creation date, so one closed today may be far down
+	// the list. Every pull request closed within the window is fetched and sorted afterwards.
+	closed := criteria.status() == PullRequestStatusCompleted || criteria.status() == PullRequestStatusAbandoned
+	if closed {
+		since := time.Now().Add(-closedPullRequestWindow).UTC().Format(time.RFC3339)
+		query += "&searchCriteria.queryTimeRangeType=closed&searchCriteria.minTime=" + url.QueryEscape(since)
+		fetch = func(baseURL string) ([]PullRequest, error) {
+			return c.getPullRequestPages(ctx, baseURL, query, maxCount)
+		}
+	}
+
 	var allPRs []PullRequest
 
 	// If no specific repositories are specified, get all PRs for the project
 	if len(repositories) == 0 {
-		prs, err := c.getPullRequestsForProject(ctx, project, maxCount)
+		prs, err := fetch(fmt.Sprintf("%s/%s/_apis/git/pullrequests", c.orgURL, project))
 		if err != nil {
 			return nil, err
 		}
-		return prs, nil
+		allPRs = prs
 	}
 
 	// Fetch PRs for each repository
 	for _, repo := range repositories {
-		prs, err := c.getPullRequestsForRepo(ctx, project, repo, maxCount)
+		prs, err := fetch(fmt.Sprintf("%s/%s/_apis/git/repositories/%s/pullrequests", c.orgURL, project, repo))
 		if err != nil {
 			// Log error but continue with other repos
 			continue
@@ -295,38 +768,322 @@ func (c *Client) GetPullRequests(ctx context.Context, project string, repositori
 		allPRs = append(allPRs, prs...)
 	}
 
+	matching := allPRs[:0]
+	for _, pr := range allPRs {
+		if criteria.Matches(pr) {
+			matching = append(matching, pr)
+		}
+	}
+	allPRs = matching
+
+	// Show the most recently closed first, e.g. to see what landed today
+	if closed {
+		sort.SliceStable(allPRs, func(i, j int) bool {
+			return allPRs[i].ClosedDate.After(allPRs[j].ClosedDate)
+		})
+	}
+
 	// Limit total results
 	if len(allPRs) > maxCount {
 		allPRs = allPRs[:maxCount]
 	}
 
+	// Fetch policy evaluations for each active PR in parallel, a few at a time
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, policyConcurrency)
+	for i := range allPRs {
+		if !allPRs[i].IsActive() || allPRs[i].Repository.Project.ID == "" {
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			policies, err := c.GetPolicyEvaluations(ctx, project, allPRs[i].Repository.Project.ID, allPRs[i].PullRequestID)
+			if err != nil {
+				allPRs[i].PolicyError = err.Error()
+				return
+			}
+			allPRs[i].Policies = policies
+		}(i)
+	}
+	wg.Wait()
+
 	return allPRs, nil
 }
 
-// getPullRequestsForProject fetches all active PRs for a project
-func (c *Client) getPullRequestsForProject(ctx context.Context, project string, maxCount int) ([]PullRequest, error) {
-	url := fmt.Sprintf("%s/%s/%s/_apis/git/pullrequests?api-version=7.0&searchCriteria.status=active&$top=%d",
-		c.baseURL, c.organization, project, maxCount)
+// GetPolicyEvaluations fetches the branch policy evaluations of a pull request.
+// projectID is the project GUID, which is part of the pull request's artifact ID.
+func (c *Client) GetPolicyEvaluations(ctx context.Context, project, projectID string, pullRequestID int) ([]PolicyEvaluation, error) {
+	artifactID := fmt.Sprintf("vstfs:///CodeReview/CodeReviewId/%s/%d", projectID, pullRequestID)
+	reqURL := fmt.Sprintf("%s/%s/_apis/policy/evaluations?artifactId=%s",
+		c.orgURL, project, url.QueryEscape(artifactID))
 
-	body, err := c.doRequest(ctx, url)
+	body, err := c.doPreviewRequest(ctx, reqURL)
 	if err != nil {
 		return nil, err
 	}
 
-	var response PullRequestsResponse
+	var response PolicyEvaluationsResponse
 	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("failed to parse pull requests response: %w", err)
+		return nil, fmt.Errorf("failed to parse policy evaluations response: %w", err)
+	}
+
+	// Disabled policies are evaluated as not applicable and only add noise
+	var evaluations []PolicyEvaluation
+	for _, e := range response.Value {
+		if e.Status != PolicyEvaluationStatusNotApplicable {
+			evaluations = append(evaluations, e)
+		}
+	}
+	return evaluations, nil
+}
+
+// GetPullRequest fetches a single pull request, including its full description
+func (c *Client) GetPullRequest(ctx context.Context, project, repositoryID string, pullRequestID int) (*PullRequest, error) {
+	body, err := c.doRequest(ctx, c.pullRequestURL(project, repositoryID, pullRequestID))
+	if err != nil {
+		return nil, err
+	}
+
+	var pr PullRequest
+	if err := json.Unmarshal(body, &pr); err != nil {
+		return nil, fmt.Errorf("failed to parse pull request response: %w", err)
+	}
+	return &pr, nil
+}
+
+// GetPullRequestThreads fetches the comment threads of a pull request
+func (c *Client) GetPullRequestThreads(ctx context.Context, project, repositoryID string, pullRequestID int) ([]CommentThread, error) {
+	body, err := c.doRequest(ctx, c.pullRequestURL(project, repositoryID, pullRequestID)+"/threads")
+	if err != nil {
+		return nil, err
 	}
 
+	var response CommentThreadsResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse threads response: %w", err)
+	}
 	return response.Value, nil
 }
 
-// getPullRequestsForRepo fetches active PRs for a specific repository
-func (c *Client) getPullRequestsForRepo(ctx context.Context, project, repository string, maxCount int) ([]PullRequest, error) {
-	url := fmt.Sprintf("%s/%s/%s/_apis/git/repositories/%s/pullrequests?api-version=7.0&searchCriteria.status=active&$top=%d",
-		c.baseURL, c.organization, project, repository, maxCount)
+// ReplyToThread adds a comment to a pull request thread, as a reply to the given comment
+// (see CommentThread.RootCommentID)
+func (c *Client) ReplyToThread(ctx context.Context, project, repositoryID string, pullRequestID, threadID, parentCommentID int, content string) (*Comment, error) {
+	reqURL := fmt.Sprintf("%s/threads/%d/comments", c.pullRequestURL(project, repositoryID, pullRequestID), threadID)
+	payload := struct {
+		ParentCommentID int         `json:"parentCommentId"`
+		Content         string      `json:"content"`
+		CommentType     CommentType `json:"commentType"`
+	}{
+		ParentCommentID: parentCommentID,
+		Content:         content,
+		CommentType:     CommentTypeText,
+	}
 
-	body, err := c.doRequest(ctx, url)
+	body, err := c.doWriteRequest(ctx, http.MethodPost, reqURL, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var comment Comment
+	if err := json.Unmarshal(body, &comment); err != nil {
+		return nil, fmt.Errorf("failed to parse comment response: %w", err)
+	}
+	return &comment, nil
+}
+
+// SetThreadStatus changes the status of a pull request thread, e.g. to resolve it as fixed
+func (c *Client) SetThreadStatus(ctx context.Context, project, repositoryID string, pullRequestID, threadID int, status CommentThreadStatus) (*CommentThread, error) {
+	reqURL := fmt.Sprintf("%s/threads/%d", c.pullRequestURL(project, repositoryID, pullRequestID), threadID)
+
+	body, err := c.doWriteRequest(ctx, http.MethodPatch, reqURL, map[string]CommentThreadStatus{"status": status})
+	if err != nil {
+		return nil, err
+	}
+
+	var thread CommentThread
+	if err := json.Unmarshal(body, &thread); err != nil {
+		return nil, fmt.Errorf("failed to parse thread response: %w", err)
+	}
+	return &thread, nil
+}
+
+// GetPullRequestIterations fetches the iterations (pushes) of a pull request, oldest first
+func (c *Client) GetPullRequestIterations(ctx context.Context, project, repositoryID string, pullRequestID int) ([]PullRequestIteration, error) {
+	body, err := c.doRequest(ctx, c.pullRequestURL(project, repositoryID, pullRequestID)+"/iterations")
+	if err != nil {
+		return nil, err
+	}
+
+	var response PullRequestIterationsResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse iterations response: %w", err)
+	}
+	return response.Value, nil
+}
+
+// GetPullRequestIterationChanges fetches the files changed by a pull request up to an iteration,
+// compared to the merge base of the source and target branches
+func (c *Client) GetPullRequestIterationChanges(ctx context.Context, project, repositoryID string, pullRequestID, iterationID int) ([]PullRequestChange, error) {
+	baseURL := fmt.Sprintf("%s/iterations/%d/changes", c.pullRequestURL(project, repositoryID, pullRequestID), iterationID)
+
+	// Changes are paged; the server may return fewer than requested, so follow nextSkip
+	// until it is no longer set
+	var changes []PullRequestChange
+	skip, top := 0, 1000
+	for {
+		body, err := c.doRequest(ctx, fmt.Sprintf("%s?$top=%d&$skip=%d", baseURL, top, skip))
+		if err != nil {
+			return nil, err
+		}
+
+		var response PullRequestChangesResponse
+		if err := json.Unmarshal(body, &response); err != nil {
+			return nil, fmt.Errorf("failed to parse changes response: %w", err)
+		}
+		changes = append(changes, response.ChangeEntries...)
+		if response.NextSkip <= skip {
+			return changes, nil
+		}
+		skip = response.NextSkip
+		if response.NextTop > 0 {
+			top = response.NextTop
+		}
+	}
+}
+
+// GetBlobContent fetches the raw content of a git blob
+func (c *Client) GetBlobContent(ctx context.Context, project, repositoryID, objectID string) ([]byte, error) {
+	// $format overrides the JSON Accept header, so the blob is returned as is
+	return c.doRequest(ctx, fmt.Sprintf("%s/%s/_apis/git/repositories/%s/blobs/%s?$format=octetstream",
+		c.orgURL, project, url.PathEscape(repositoryID), url.PathEscape(objectID)))
+}
+
+// CompletePullRequest merges a pull request with the given options. lastMergeSourceCommit is the
+// source commit the pull request was reviewed at; the server rejects the request if newer
+// changes have been pushed since.
+func (c *Client) CompletePullRequest(ctx context.Context, project, repositoryID string, pullRequestID int, lastMergeSourceCommit string, options CompletionOptions) (*PullRequest, error) {
+	payload := struct {
+		Status                PullRequestStatus `json:"status"`
+		LastMergeSourceCommit GitCommitRef      `json:"lastMergeSourceCommit"`
+		CompletionOptions     CompletionOptions `json:"completionOptions"`
+	}{
+		Status:                PullRequestStatusCompleted,
+		LastMergeSourceCommit: GitCommitRef{CommitID: lastMergeSourceCommit},
+		CompletionOptions:     options,
+	}
+	return c.updatePullRequest(ctx, project, repositoryID, pullRequestID, payload)
+}
+
+// SetAutoComplete makes a pull request complete with the given options once its policies pass.
+// userID is the identity setting auto-complete; an empty userID cancels auto-complete.
+func (c *Client) SetAutoComplete(ctx context.Context, project, repositoryID string, pullRequestID int, userID string, options CompletionOptions) (*PullRequest, error) {
+	if userID == "" {
+		// Auto-complete is cancelled by setting it to the empty identity
+		userID = "00000000-0000-0000-0000-000000000000"
+	}
+	payload := struct {
+		AutoCompleteSetBy Identity          `json:"autoCompleteSetBy"`
+		CompletionOptions CompletionOptions `json:"completionOptions"`
+	}{
+		AutoCompleteSetBy: Identity{ID: userID},
+		CompletionOptions: options,
+	}
+	return c.updatePullRequest(ctx, project, repositoryID, pullRequestID, payload)
+}
+
+// updatePullRequest sends a pull request update and returns the updated pull request
+func (c *Client) updatePullRequest(ctx context.Context, project, repositoryID string, pullRequestID int, payload any) (*PullRequest, error) {
+	body, err := c.doWriteRequest(ctx, http.MethodPatch, c.pullRequestURL(project, repositoryID, pullRequestID), payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var pr PullRequest
+	if err := json.Unmarshal(body, &pr); err != nil {
+		return nil, fmt.Errorf("failed to parse pull request response: %w", err)
+	}
+	return &pr, nil
+}
+
+// GetRepository fetches a git repository by ID or name, including its default branch
+func (c *Client) GetRepository(ctx context.Context, project, repositoryID string) (*GitRepository, error) {
+	body, err := c.doRequest(ctx, fmt.Sprintf("%s/%s/_apis/git/repositories/%s",
+		c.orgURL, project, url.PathEscape(repositoryID)))
+	if err != nil {
+		return nil, err
+	}
+
+	var repo GitRepository
+	if err := json.Unmarshal(body, &repo); err != nil {
+		return nil, fmt.Errorf("failed to parse repository response: %w", err)
+	}
+	return &repo, nil
+}
+
+// BranchExists returns true if a branch, given as a full ref, exists in a repository
+func (c *Client) BranchExists(ctx context.Context, project, repositoryID, branchRef string) (bool, error) {
+	// The filter matches refs starting with it, without the "refs/" prefix
+	body, err := c.doRequest(ctx, fmt.Sprintf("%s/%s/_apis/git/repositories/%s/refs?filter=%s",
+		c.orgURL, project, url.PathEscape(repositoryID), url.QueryEscape(strings.TrimPrefix(branchRef, "refs/"))))
+	if err != nil {
+		return false, err
+	}
+
+	var response GitRefsResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return false, fmt.Errorf("failed to parse refs response: %w", err)
+	}
+	for _, ref := range response.Value {
+		if ref.Name == branchRef {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CreatePullRequest creates a pull request in a repository
+func (c *Client) CreatePullRequest(ctx context.Context, project, repositoryID string, pr NewPullRequest) (*PullRequest, error) {
+	reqURL := fmt.Sprintf("%s/%s/_apis/git/repositories/%s/pullrequests", c.orgURL, project, url.PathEscape(repositoryID))
+
+	body, err := c.doWriteRequest(ctx, http.MethodPost, reqURL, pr)
+	if err != nil {
+		return nil, err
+	}
+
+	var created PullRequest
+	if err := json.Unmarshal(body, &created); err != nil {
+		return nil, fmt.Errorf("failed to parse pull request response: %w", err)
+	}
+	return &created, nil
+}
+
+// FindIdentities searches users and groups by display name, account or e-mail address
+func (c *Client) FindIdentities(ctx context.Context, query string) ([]ConnectionIdentity, error) {
+	body, err := c.doRequest(ctx, fmt.Sprintf("%s/_apis/identities?searchFilter=General&filterValue=%s&queryMembership=None",
+		c.identityURL, url.QueryEscape(query)))
+	if err != nil {
+		return nil, err
+	}
+
+	var response IdentitiesResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse identities response: %w", err)
+	}
+	return response.Value, nil
+}
+
+// pullRequestURL returns the API URL of a pull request
+func (c *Client) pullRequestURL(project, repositoryID string, pullRequestID int) string {
+	return fmt.Sprintf("%s/%s/_apis/git/repositories/%s/pullrequests/%d",
+		c.orgURL, project, url.PathEscape(repositoryID), pullRequestID)
+}
+
+// getPullRequests fetches pull requests from a project or repository pull requests URL
+func (c *Client) getPullRequests(ctx context.Context, baseURL, query string, maxCount int) ([]PullRequest, error) {
+	body, err := c.doRequest(ctx, fmt.Sprintf("%s?%s&$top=%d", baseURL, query, maxCount))
 	if err != nil {
 		return nil, err
 	}
@@ -339,8 +1096,50 @@ func (c *Client) getPullRequestsForRepo(ctx context.Context, project, repository
 	return response.Value, nil
 }
 
+// scanPullRequests fetches pull requests page by page and keeps those matching the criteria,
+// until maxCount match, a short page is returned or branchScanLimit have been scanned
+func (c *Client) scanPullRequests(ctx context.Context, baseURL, query string, criteria PullRequestCriteria, maxCount int) ([]PullRequest, error) {
+	pageSize := max(maxCount, branchScanSize)
+
+	var matching []PullRequest
+	for skip := 0; skip < branchScanLimit; skip += pageSize {
+		page, err := c.getPullRequests(ctx, baseURL, fmt.Sprintf("%s&$skip=%d", query, skip), pageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, pr := range page {
+			if criteria.Matches(pr) {
+				matching = append(matching, pr)
+			}
+		}
+		if len(matching) >= maxCount || len(page) < pageSize {
+			break
+		}
+	}
+	return matching, nil
+}
+
+// getPullRequestPages fetches pull requests page by page until a short page is returned or
+// closedPullRequestLimit is reached. minCount is the smallest page size that is useful to the caller.
+func (c *Client) getPullRequestPages(ctx context.Context, baseURL, query string, minCount int) ([]PullRequest, error) {
+	pageSize := max(minCount, branchScanSize)
+
+	var all []PullRequest
+	for skip := 0; skip < closedPullRequestLimit; skip += pageSize {
+		page, err := c.getPullRequests(ctx, baseURL, fmt.Sprintf("%s&$skip=%d", query, skip), pageSize)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		if len(page) < pageSize {
+			break
+		}
+	}
+	return all, nil
+}
+
 // GetPullRequestWebURL returns the web URL for a pull request
 func (c *Client) GetPullRequestWebURL(project, repoName string, prID int) string {
-	return fmt.Sprintf("%s/%s/%s/_git/%s/pullrequest/%d",
-		c.baseURL, c.organization, project, repoName, prID)
+	return fmt.Sprintf("%s/%s/_git/%s/pullrequest/%d",
+		c.orgURL, project, repoName, prID)
 }
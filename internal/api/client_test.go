@@ -0,0 +1,549 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestDowngradeAPIVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  string
+		pinned   bool
+		rejected string
+		want     string
+		ok       bool
+	}{
+		{name: "next older version", current: "7.0", rejected: "7.0", want: "6.0", ok: true},
+		{name: "middle of the chain", current: "5.1", rejected: "5.1", want: "5.0", ok: true},
+		{name: "oldest version", current: "4.1", rejected: "4.1", want: "4.1", ok: false},
+		{name: "unknown version", current: "3.0", rejected: "3.0", want: "3.0", ok: false},
+		{name: "already downgraded", current: "6.0", rejected: "7.0", want: "6.0", ok: true},
+		{name: "pinned", current: "7.0", pinned: true, rejected: "7.0", want: "7.0", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{apiVersion: tt.current, pinnedVersion: tt.pinned}
+			if ok := c.downgradeAPIVersion(tt.rejected); ok != tt.ok {
+				t.Errorf("downgradeAPIVersion(%q) = %v, want %v", tt.rejected, ok, tt.ok)
+			}
+			if got := c.APIVersion(); got != tt.want {
+				t.Errorf("APIVersion() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewClientURLs(t *testing.T) {
+	tests := []struct {
+		name         string
+		cfg          ClientConfig
+		wantOrg      string
+		wantRelease  string
+		wantIdentity string
+	}{
+		{
+			name:         "services",
+			cfg:          ClientConfig{BaseURL: "https://dev.azure.com/", Organization: "contoso"},
+			wantOrg:      "https://dev.azure.com/contoso",
+			wantRelease:  "https://vsrm.dev.azure.com/contoso",
+			wantIdentity: "https://vssps.dev.azure.com/contoso",
+		},
+		{
+			name:         "services on visualstudio.com",
+			cfg:          ClientConfig{CollectionURL: "https://contoso.visualstudio.com/"},
+			wantOrg:      "https://contoso.visualstudio.com",
+			wantRelease:  "https://contoso.vsrm.visualstudio.com",
+			wantIdentity: "https://contoso.vssps.visualstudio.com",
+		},
+		{
+			name:         "server collection",
+			cfg:          ClientConfig{CollectionURL: "https://tfs.contoso.com/tfs/DefaultCollection/", Server: true},
+			wantOrg:      "https://tfs.contoso.com/tfs/DefaultCollection",
+			wantRelease:  "https://tfs.contoso.com/tfs/DefaultCollection",
+			wantIdentity: "https://tfs.contoso.com/tfs/DefaultCollection",
+		},
+		{
+			name:         "server on a dev.azure.com-like host",
+			cfg:          ClientConfig{CollectionURL: "http://dev.azure.com.local:8080/tfs/Main", Server: true},
+			wantOrg:      "http://dev.azure.com.local:8080/tfs/Main",
+			wantRelease:  "http://dev.azure.com.local:8080/tfs/Main",
+			wantIdentity: "http://dev.azure.com.local:8080/tfs/Main",
+		},
+		{
+			name:         "release URL override",
+			cfg:          ClientConfig{BaseURL: "https://dev.azure.com", Organization: "contoso", ReleaseURL: "https://releases.example.com/contoso/"},
+			wantOrg:      "https://dev.azure.com/contoso",
+			wantRelease:  "https://releases.example.com/contoso",
+			wantIdentity: "https://vssps.dev.azure.com/contoso",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewClient(tt.cfg)
+			if c.orgURL != tt.wantOrg {
+				t.Errorf("orgURL = %q, want %q", c.orgURL, tt.wantOrg)
+			}
+			if c.releaseURL != tt.wantRelease {
+				t.Errorf("releaseURL = %q, want %q", c.releaseURL, tt.wantRelease)
+			}
+			if c.identityURL != tt.wantIdentity {
+				t.Errorf("identityURL = %q, want %q", c.identityURL, tt.wantIdentity)
+			}
+		})
+	}
+}
+
+func TestAPIErrorMessage(t *testing.T) {
+	long := strings.Repeat("a", 199) + "é" + strings.Repeat("b", 50)
+
+	tests := []struct {
+		name string
+		err  APIError
+		want string
+	}{
+		{"json message", APIError{StatusCode: 400, Body: `{"message":"TF401019: bad branch","typeKey":"GitRefNotFoundException"}`}, "TF401019: bad branch"},
+		{"html page", APIError{StatusCode: 401, Body: "<html><body>Sign in</body></html>"}, "Unauthorized"},
+		{"empty body", APIError{StatusCode: 500}, "Internal Server Error"},
+		{"sign-in redirect", APIError{StatusCode: 203, Body: "<html>"}, "credentials were rejected (sign-in page returned)"},
+		{"short text", APIError{StatusCode: 502, Body: " Bad gateway \n"}, "Bad gateway"},
+		{"truncated on a rune boundary", APIError{StatusCode: 500, Body: long}, strings.Repeat("a", 199) + "..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.err.Message()
+			if got != tt.want {
+				t.Errorf("Message() = %q, want %q", got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("Message() = %q is not valid UTF-8", got)
+			}
+		})
+	}
+}
+
+func TestGetPullRequestsClosed(t *testing.T) {
+	// 250 completed pull requests in creation order; the most recently closed is on the second page
+	now := time.Now().UTC()
+	var prs []PullRequest
+	for i := 0; i < 250; i++ {
+		prs = append(prs, PullRequest{PullRequestID: i + 1, Status: PullRequestStatusCompleted, ClosedDate: now.Add(-time.Duration(250-i) * time.Hour)})
+	}
+	prs[220].ClosedDate = now
+
+	var skips []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/_apis/connectionData") {
+			connectionDataHandler(w, r)
+			return
+		}
+		q := r.URL.Query()
+		if q.Get("searchCriteria.status") != "completed" || q.Get("searchCriteria.queryTimeRangeType") != "closed" {
+			t.Errorf("query = %s, want completed pull requests searched by closing time", r.URL.RawQuery)
+		}
+		minTime, err := time.Parse(time.RFC3339, q.Get("searchCriteria.minTime"))
+		if err != nil || now.Sub(minTime) < 29*24*time.Hour || now.Sub(minTime) > 31*24*time.Hour {
+			t.Errorf("minTime = %q, want about 30 days ago", q.Get("searchCriteria.minTime"))
+		}
+		skip, _ := strconv.Atoi(q.Get("$skip"))
+		top, _ := strconv.Atoi(q.Get("$top"))
+		skips = append(skips, q.Get("$skip"))
+
+		page := prs[min(skip, len(prs)):min(skip+top, len(prs))]
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(PullRequestsResponse{Value: page, Count: len(page)})
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv, TransportConfig{})
+	got, err := c.GetPullRequests(context.Background(), "Payments", nil, PullRequestCriteria{Status: PullRequestStatusCompleted}, 3)
+	if err != nil {
+		t.Fatalf("GetPullRequests: %v", err)
+	}
+
+	if want := []string{"0", "200"}; !reflect.DeepEqual(skips, want) {
+		t.Errorf("$skip = %v, want %v", skips, want)
+	}
+	var ids []int
+	for _, pr := range got {
+		ids = append(ids, pr.PullRequestID)
+	}
+	if want := []int{221, 250, 249}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("pull requests = %v, want %v", ids, want)
+	}
+}
+
+func TestGetPolicyEvaluations(t *testing.T) {
+	const projectID = "6ce954b1-ce1f-45d1-b94d-e6bf2464ba2c"
+
+	var versions []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/_apis/connectionData") {
+			connectionDataHandler(w, r)
+			return
+		}
+		if r.URL.Path != "/DefaultCollection/Payments/_apis/policy/evaluations" {
+			t.Errorf("path = %s, want the policy evaluations of Payments", r.URL.Path)
+		}
+		if got, want := r.URL.Query().Get("artifactId"), "vstfs:///CodeReview/CodeReviewId/"+projectID+"/42"; got != want {
+			t.Errorf("artifactId = %q, want %q", got, want)
+		}
+
+		// The server rejects the newest preview version, as older servers do
+		version := r.URL.Query().Get("api-version")
+		versions = append(versions, version)
+		if version == "7.0-preview.1" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"message":"The requested version is out of range","typeKey":"VssVersionOutOfRangeException"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"count":1,"value":[{"evaluationId":"e1","status":"rejected",` +
+			`"configuration":{"id":3,"isBlocking":true,"isEnabled":true,` +
+			`"type":{"id":"0609b952","displayName":"Build"},"settings":{"displayName":"payments-ci"}}}]}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv, TransportConfig{})
+	got, err := c.GetPolicyEvaluations(context.Background(), "Payments", projectID, 42)
+	if err != nil {
+		t.Fatalf("GetPolicyEvaluations: %v", err)
+	}
+
+	want := []PolicyEvaluation{{
+		EvaluationID: "e1",
+		Status:       PolicyEvaluationStatusRejected,
+		Configuration: PolicyConfiguration{
+			ID:         3,
+			IsBlocking: true,
+			IsEnabled:  true,
+			Type:       PolicyType{ID: "0609b952", DisplayName: "Build"},
+			Settings:   PolicySettings{DisplayName: "payments-ci"},
+		},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("evaluations = %+v, want %+v", got, want)
+	}
+	if want := []string{"7.0-preview.1", "6.0-preview.1"}; !reflect.DeepEqual(versions, want) {
+		t.Errorf("api-version = %v, want %v", versions, want)
+	}
+	if v := c.APIVersion(); v != DefaultAPIVersion {
+		t.Errorf("APIVersion() = %q after a rejected preview version, want %q", v, DefaultAPIVersion)
+	}
+}
+
+func TestGetPullRequestsPolicyError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/_apis/connectionData"):
+			connectionDataHandler(w, r)
+		case strings.HasSuffix(r.URL.Path, "/_apis/git/pullrequests"):
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"count":2,"value":[` +
+				`{"pullRequestId":1,"status":"active","repository":{"project":{"id":"p1"}},"reviewers":[{"vote":10}],"lastMergeSourceCommit":{"commitId":"a"}},` +
+				`{"pullRequestId":2,"status":"active","repository":{"project":{"id":"p1"}},"reviewers":[{"vote":10}],"lastMergeSourceCommit":{"commitId":"b"}}]}`))
+		case strings.HasSuffix(r.URL.Query().Get("artifactId"), "/2"):
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message":"TF401027: You need the Git 'PolicyRead' permission"}`))
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"count":0,"value":[]}`))
+		}
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv, TransportConfig{})
+	prs, err := c.GetPullRequests(context.Background(), "Payments", nil, PullRequestCriteria{}, 10)
+	if err != nil {
+		t.Fatalf("GetPullRequests: %v", err)
+	}
+	if len(prs) != 2 {
+		t.Fatalf("pull requests = %+v, want 2", prs)
+	}
+
+	if prs[0].PolicyError != "" || !prs[0].CanComplete() {
+		t.Errorf("pull request 1: policy error %q, blockers %v, want it completable", prs[0].PolicyError, prs[0].CompletionBlockers())
+	}
+	if !strings.Contains(prs[1].PolicyError, "TF401027") {
+		t.Errorf("pull request 2 policy error = %q, want the API error", prs[1].PolicyError)
+	}
+	if got := prs[1].GetPolicySummary(); got != "?" {
+		t.Errorf("pull request 2 policy summary = %q, want ?", got)
+	}
+	if prs[1].CanComplete() {
+		t.Error("pull request 2 can complete with unknown policies")
+	}
+}
+
+func TestGetPullRequestIterationChanges(t *testing.T) {
+	// The server returns at most two changes per page, fewer than requested
+	paths := []string{"/a.go", "/b.go", "/c.go", "/d.go", "/e.go"}
+
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/_apis/connectionData") {
+			connectionDataHandler(w, r)
+			return
+		}
+		if !strings.HasSuffix(r.URL.Path, "/pullrequests/5/iterations/2/changes") {
+			t.Errorf("path = %s, want the changes of iteration 2", r.URL.Path)
+		}
+		skip, _ := strconv.Atoi(r.URL.Query().Get("$skip"))
+		requests = append(requests, r.URL.Query().Get("$skip")+"/"+r.URL.Query().Get("$top"))
+
+		var response PullRequestChangesResponse
+		for _, p := range paths[skip:min(skip+2, len(paths))] {
+			response.ChangeEntries = append(response.ChangeEntries, PullRequestChange{Item: GitItem{Path: p}, ChangeType: ChangeTypeEdit})
+		}
+		if skip+2 < len(paths) {
+			response.NextSkip, response.NextTop = skip+2, 2
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv, TransportConfig{})
+	changes, err := c.GetPullRequestIterationChanges(context.Background(), "Payments", "repo", 5, 2)
+	if err != nil {
+		t.Fatalf("GetPullRequestIterationChanges: %v", err)
+	}
+
+	var got []string
+	for _, change := range changes {
+		got = append(got, change.Item.Path)
+	}
+	if !reflect.DeepEqual(got, paths) {
+		t.Errorf("changes = %v, want %v", got, paths)
+	}
+	if want := []string{"0/1000", "2/2", "4/2"}; !reflect.DeepEqual(requests, want) {
+		t.Errorf("requests ($skip/$top) = %v, want %v", requests, want)
+	}
+}
+
+func TestGetSuccessfulBuilds(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/_apis/connectionData") {
+			connectionDataHandler(w, r)
+			return
+		}
+		if r.URL.Path != "/DefaultCollection/Payments/_apis/build/builds" {
+			t.Errorf("path = %s, want the builds of Payments", r.URL.Path)
+		}
+		q := r.URL.Query()
+		want := map[string]string{
+			"definitions":  "7",
+			"statusFilter": "completed",
+			"resultFilter": "succeeded",
+			"queryOrder":   "finishTimeDescending",
+			"$top":         "10",
+		}
+		for param, value := range want {
+			if got := q.Get(param); got != value {
+				t.Errorf("%s = %q, want %q", param, got, value)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"count":1,"value":[{"id":4,"buildNumber":"20240103.1","status":"completed","result":"succeeded"}]}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv, TransportConfig{})
+	got, err := c.GetSuccessfulBuilds(context.Background(), "Payments", 7, 10)
+	if err != nil {
+		t.Fatalf("GetSuccessfulBuilds: %v", err)
+	}
+	if len(got) != 1 || got[0].ID != 4 || got[0].BuildNumber != "20240103.1" {
+		t.Errorf("builds = %+v, want build 4", got)
+	}
+}
+
+func TestCreateRelease(t *testing.T) {
+	var body map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/_apis/connectionData") {
+			connectionDataHandler(w, r)
+			return
+		}
+		if r.Method != http.MethodPost || r.URL.Path != "/DefaultCollection/Payments/_apis/release/releases" {
+			t.Errorf("request = %s %s, want POST of the releases of Payments", r.Method, r.URL.Path)
+		}
+		if ct := r.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+			t.Errorf("Content-Type = %q, want JSON", ct)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding request body: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":12,"name":"Release-43","status":"active","releaseDefinition":{"id":2,"name":"payments-cd"}}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv, TransportConfig{})
+	got, err := c.CreateRelease(context.Background(), "Payments", NewRelease{
+		DefinitionID: 2,
+		Description:  "Refund fix",
+		Artifacts: []ArtifactMetadata{{
+			Alias:             "_payments-ci",
+			InstanceReference: ArtifactSourceReference{ID: "4", Name: "20240103.1"},
+		}},
+		ManualEnvironments: []string{"prod"},
+	})
+	if err != nil {
+		t.Fatalf("CreateRelease: %v", err)
+	}
+	if got.ID != 12 || got.Name != "Release-43" {
+		t.Errorf("release = %+v, want release 12", got)
+	}
+
+	want := map[string]any{
+		"definitionId": float64(2),
+		"description":  "Refund fix",
+		"artifacts": []any{map[string]any{
+			"alias":             "_payments-ci",
+			"instanceReference": map[string]any{"id": "4", "name": "20240103.1"},
+		}},
+		"manualEnvironments": []any{"prod"},
+	}
+	if !reflect.DeepEqual(body, want) {
+		t.Errorf("request body = %v, want %v", body, want)
+	}
+}
+
+func TestGetDefinitionsPaged(t *testing.T) {
+	var tokens []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/_apis/connectionData") {
+			connectionDataHandler(w, r)
+			return
+		}
+		token := r.URL.Query().Get("continuationToken")
+		tokens = append(tokens, token)
+
+		// Two pages, the second one continuing after the name of the last definition
+		w.Header().Set("Content-Type", "application/json")
+		if token == "" {
+			w.Header().Set("x-ms-continuationtoken", "payments ci")
+			_, _ = w.Write([]byte(`{"count":1,"value":[{"id":1,"name":"orders-ci"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"count":1,"value":[{"id":2,"name":"payments-ci"}]}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv, TransportConfig{})
+	builds, err := c.GetBuildDefinitions(context.Background(), "Payments")
+	if err != nil {
+		t.Fatalf("GetBuildDefinitions: %v", err)
+	}
+	if len(builds) != 2 || builds[0].ID != 1 || builds[1].ID != 2 {
+		t.Errorf("build definitions = %+v, want both pages", builds)
+	}
+
+	releases, err := c.GetReleaseDefinitions(context.Background(), "Payments")
+	if err != nil {
+		t.Fatalf("GetReleaseDefinitions: %v", err)
+	}
+	if len(releases) != 2 || releases[0].ID != 1 || releases[1].ID != 2 {
+		t.Errorf("release definitions = %+v, want both pages", releases)
+	}
+
+	if want := []string{"", "payments ci", "", "payments ci"}; !reflect.DeepEqual(tokens, want) {
+		t.Errorf("continuation tokens = %q, want %q", tokens, want)
+	}
+}
+
+func TestGetBuildsScansPages(t *testing.T) {
+	// The first page holds only dependabot builds; the release build is on the second one
+	var first []Build
+	for i := 0; i < branchScanSize; i++ {
+		first = append(first, Build{ID: 1000 - i, SourceBranch: "refs/heads/dependabot/npm/lodash"})
+	}
+	second := []Build{{ID: 7, SourceBranch: "refs/heads/release/1.2"}, {ID: 6, SourceBranch: "refs/heads/dependabot/npm/react"}}
+
+	for _, branches := range [][]string{{"release/*"}, {"!dependabot/*"}} {
+		t.Run(strings.Join(branches, ","), func(t *testing.T) {
+			var tokens []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch {
+				case strings.HasSuffix(r.URL.Path, "/_apis/connectionData"):
+					connectionDataHandler(w, r)
+				case strings.HasSuffix(r.URL.Path, "/timeline"):
+					_, _ = w.Write([]byte(`{"records":[]}`))
+				default:
+					token := r.URL.Query().Get("continuationToken")
+					tokens = append(tokens, token)
+					if token == "" {
+						w.Header().Set("x-ms-continuationtoken", "page-2")
+						_ = json.NewEncoder(w).Encode(BuildsResponse{Value: first, Count: len(first)})
+						return
+					}
+					_ = json.NewEncoder(w).Encode(BuildsResponse{Value: second, Count: len(second)})
+				}
+			}))
+			defer srv.Close()
+
+			c := newTestClient(t, srv, TransportConfig{})
+			got, err := c.GetBuilds(context.Background(), "Payments", nil, branches, 5)
+			if err != nil {
+				t.Fatalf("GetBuilds: %v", err)
+			}
+			if len(got) != 1 || got[0].ID != 7 {
+				t.Errorf("builds = %+v, want the release build of the second page", got)
+			}
+			if want := []string{"", "page-2"}; !reflect.DeepEqual(tokens, want) {
+				t.Errorf("continuation tokens = %q, want %q", tokens, want)
+			}
+		})
+	}
+}
+
+func TestGetPullRequestsScansPages(t *testing.T) {
+	// Only drafts on the first page, so the second page is needed for one ready pull request
+	var prs []PullRequest
+	for i := 0; i < branchScanSize; i++ {
+		prs = append(prs, PullRequest{PullRequestID: 500 - i, Status: PullRequestStatusActive, IsDraft: true, TargetRefName: "refs/heads/main"})
+	}
+	prs = append(prs, PullRequest{PullRequestID: 42, Status: PullRequestStatusActive, TargetRefName: "refs/heads/main"})
+
+	var skips []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/_apis/connectionData") {
+			connectionDataHandler(w, r)
+			return
+		}
+		q := r.URL.Query()
+		skip, _ := strconv.Atoi(q.Get("$skip"))
+		top, _ := strconv.Atoi(q.Get("$top"))
+		skips = append(skips, q.Get("$skip"))
+
+		page := prs[min(skip, len(prs)):min(skip+top, len(prs))]
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(PullRequestsResponse{Value: page, Count: len(page)})
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv, TransportConfig{})
+	got, err := c.GetPullRequests(context.Background(), "Payments", nil, PullRequestCriteria{ExcludeDrafts: true}, 5)
+	if err != nil {
+		t.Fatalf("GetPullRequests: %v", err)
+	}
+	if len(got) != 1 || got[0].PullRequestID != 42 {
+		t.Errorf("pull requests = %v, want the ready pull request of the second page", got)
+	}
+	if want := []string{"0", "200"}; !reflect.DeepEqual(skips, want) {
+		t.Errorf("$skip = %v, want %v", skips, want)
+	}
+}
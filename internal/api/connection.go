@@ -0,0 +1,221 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ConnectionData represents the response of the connectionData API
+type ConnectionData struct {
+	AuthenticatedUser ConnectionIdentity `json:"authenticatedUser"`
+	AuthorizedUser    ConnectionIdentity `json:"authorizedUser"`
+	InstanceID        string             `json:"instanceId"`
+	DeploymentType    string             `json:"deploymentType"` // "hosted" or "onPremises"
+}
+
+// ConnectionIdentity represents an identity returned by the connectionData and identities APIs
+type ConnectionIdentity struct {
+	ID                  string                       `json:"id"`
+	ProviderDisplayName string                       `json:"providerDisplayName"`
+	Properties          ConnectionIdentityProperties `json:"properties"`
+}
+
+// ConnectionIdentityProperties holds selected identity properties
+type ConnectionIdentityProperties struct {
+	Account PropertyValue `json:"Account"`
+	Mail    PropertyValue `json:"Mail"`
+}
+
+// PropertyValue is a property wrapped in a {"$value": ...} object
+type PropertyValue struct {
+	Value string `json:"$value"`
+}
+
+// IsAnonymous returns true if the server did not recognize the credentials
+func (i ConnectionIdentity) IsAnonymous() bool {
+	return i.ID == "" || strings.EqualFold(i.ProviderDisplayName, "Anonymous")
+}
+
+// GetConnectionData fetches information about the organization and the authenticated user
+func (c *Client) GetConnectionData(ctx context.Context) (*ConnectionData, error) {
+	// connectionData is a location service endpoint and does not take an api-version
+	body, _, err := c.doRequestWithRetry(ctx, c.orgURL+"/_apis/connectionData")
+	if err != nil {
+		return nil, err
+	}
+
+	var response ConnectionData
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse connection data response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// GetProject fetches a project by name or ID
+func (c *Client) GetProject(ctx context.Context, project string) (*TeamProject, error) {
+	reqURL := fmt.Sprintf("%s/_apis/projects/%s", c.orgURL, url.PathEscape(project))
+
+	body, err := c.doRequest(ctx, reqURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var response TeamProject
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse project response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// projectsPageSize is the number of projects requested per page
+const projectsPageSize = 500
+
+// GetProjects fetches all projects of the organization the user can access
+func (c *Client) GetProjects(ctx context.Context) ([]TeamProject, error) {
+	var projects []TeamProject
+
+	for skip := 0; ; skip += projectsPageSize {
+		reqURL := fmt.Sprintf("%s/_apis/projects?$top=%d&$skip=%d", c.orgURL, projectsPageSize, skip)
+
+		body, err := c.doRequest(ctx, reqURL)
+		if err != nil {
+			return nil, err
+		}
+
+		var response ProjectsResponse
+		if err := json.Unmarshal(body, &response); err != nil {
+			return nil, fmt.Errorf("failed to parse projects response: %w", err)
+		}
+
+		projects = append(projects, response.Value...)
+		if len(response.Value) < projectsPageSize {
+			return projects, nil
+		}
+	}
+}
+
+// GetBuildDefinition fetches a build definition by ID
+func (c *Client) GetBuildDefinition(ctx context.Context, project string, definitionID int) (*BuildDefinition, error) {
+	url := fmt.Sprintf("%s/%s/_apis/build/definitions/%d",
+		c.orgURL, project, definitionID)
+
+	body, err := c.doRequest(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	var response BuildDefinition
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse build definition response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// definitionsPerPage is the number of definitions requested per page; further pages are
+// requested with the continuation token of the previous one
+const definitionsPerPage = 1000
+
+// GetBuildDefinitions lists all build definitions of a project with their folders
+func (c *Client) GetBuildDefinitions(ctx context.Context, project string) ([]BuildDefinition, error) {
+	var definitions []BuildDefinition
+	continuation := ""
+	for {
+		reqURL := fmt.Sprintf("%s/%s/_apis/build/definitions?queryOrder=definitionNameAscending&$top=%d",
+			c.orgURL, project, definitionsPerPage)
+		if continuation != "" {
+			reqURL += "&continuationToken=" + url.QueryEscape(continuation)
+		}
+
+		body, next, err := c.doPagedRequest(ctx, reqURL)
+		if err != nil {
+			return nil, err
+		}
+
+		var response BuildDefinitionsResponse
+		if err := json.Unmarshal(body, &response); err != nil {
+			return nil, fmt.Errorf("failed to parse build definitions response: %w", err)
+		}
+
+		definitions = append(definitions, response.Value...)
+		if next == "" || next == continuation {
+			return definitions, nil
+		}
+		continuation = next
+	}
+}
+
+// GetReleaseDefinition fetches a release definition by ID
+func (c *Client) GetReleaseDefinition(ctx context.Context, project string, definitionID int) (*ReleaseDefinition, error) {
+	url := fmt.Sprintf("%s/%s/_apis/release/definitions/%d",
+		c.releaseURL, project, definitionID)
+
+	body, err := c.doRequest(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	var response ReleaseDefinition
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse release definition response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// GetReleaseDefinitions lists all release definitions of a project with their folders
+func (c *Client) GetReleaseDefinitions(ctx context.Context, project string) ([]ReleaseDefinition, error) {
+	var definitions []ReleaseDefinition
+	continuation := ""
+	for {
+		reqURL := fmt.Sprintf("%s/%s/_apis/release/definitions?queryOrder=nameAscending&$top=%d",
+			c.releaseURL, project, definitionsPerPage)
+		if continuation != "" {
+			reqURL += "&continuationToken=" + url.QueryEscape(continuation)
+		}
+
+		body, next, err := c.doPagedRequest(ctx, reqURL)
+		if err != nil {
+			return nil, err
+		}
+
+		var response ReleaseDefinitionsResponse
+		if err := json.Unmarshal(body, &response); err != nil {
+			return nil, fmt.Errorf("failed to parse release definitions response: %w", err)
+		}
+
+		definitions = append(definitions, response.Value...)
+		if next == "" || next == continuation {
+			return definitions, nil
+		}
+		continuation = next
+	}
+}
+
+// CheckBuildAccess verifies that the credentials can read builds in a project
+func (c *Client) CheckBuildAccess(ctx context.Context, project string) error {
+	_, err := c.doRequest(ctx, fmt.Sprintf("%s/%s/_apis/build/definitions?$top=1", c.orgURL, project))
+	return err
+}
+
+// CheckReleaseAccess verifies that the credentials can read releases in a project
+func (c *Client) CheckReleaseAccess(ctx context.Context, project string) error {
+	_, err := c.doRequest(ctx, fmt.Sprintf("%s/%s/_apis/release/definitions?$top=1", c.releaseURL, project))
+	return err
+}
+
+// CheckCodeAccess verifies that the credentials can read repositories in a project
+func (c *Client) CheckCodeAccess(ctx context.Context, project string) error {
+	_, err := c.doRequest(ctx, fmt.Sprintf("%s/%s/_apis/git/repositories", c.orgURL, project))
+	return err
+}
+
+// GetOrganizationURL returns the organization (or collection) URL
+func (c *Client) GetOrganizationURL() string {
+	return c.orgURL
+}
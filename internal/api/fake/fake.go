@@ -0,0 +1,634 @@
+// Package fake provides an in-memory implementation of api.Service for tests and demos.
+package fake
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/api"
+)
+
+// Operation names used as keys in Service.Errors
+const (
+	OpGetBuilds             = "GetBuilds"
+	OpGetReleases           = "GetReleases"
+	OpGetPullRequests       = "GetPullRequests"
+	OpGetPullRequest        = "GetPullRequest"
+	OpGetThreads            = "GetPullRequestThreads"
+	OpReplyToThread         = "ReplyToThread"
+	OpSetThreadStatus       = "SetThreadStatus"
+	OpGetIterations         = "GetPullRequestIterations"
+	OpGetIterationChanges   = "GetPullRequestIterationChanges"
+	OpGetBlobContent        = "GetBlobContent"
+	OpCompletePullRequest   = "CompletePullRequest"
+	OpSetAutoComplete       = "SetAutoComplete"
+	OpGetRepository         = "GetRepository"
+	OpBranchExists          = "BranchExists"
+	OpCreatePullRequest     = "CreatePullRequest"
+	OpFindIdentities        = "FindIdentities"
+	OpGetRelease            = "GetRelease"
+	OpGetReleaseTaskLog     = "GetReleaseTaskLog"
+	OpGetSuccessfulBuilds   = "GetSuccessfulBuilds"
+	OpCreateRelease         = "CreateRelease"
+	OpGetConnectionData     = "GetConnectionData"
+	OpGetProjects           = "GetProjects"
+	OpGetProject            = "GetProject"
+	OpGetBuildDefinition    = "GetBuildDefinition"
+	OpGetReleaseDefinition  = "GetReleaseDefinition"
+	OpGetBuildDefinitions   = "GetBuildDefinitions"
+	OpGetReleaseDefinitions = "GetReleaseDefinitions"
+	OpCheckBuildAccess      = "CheckBuildAccess"
+	OpCheckReleaseAccess    = "CheckReleaseAccess"
+	OpCheckCodeAccess       = "CheckCodeAccess"
+)
+
+// Service is an in-memory api.Service with configurable data, latency and errors.
+// Data is keyed by project name. Fields may be changed between calls.
+type Service struct {
+	mu sync.Mutex
+
+	Builds       map[string][]api.Build
+	Releases     map[string][]api.Release
+	PullRequests map[string][]api.PullRequest
+	Projects     []api.TeamProject // Projects listed by GetProjects
+
+	// Repositories returned by GetRepository and identities searched by FindIdentities
+	Repositories map[string][]api.GitRepository
+	Identities   []api.ConnectionIdentity
+
+	// Branch refs pushed to a repository, keyed by repository ID or name, for BranchExists
+	Branches map[string][]string
+
+	// Task logs keyed by task ID; GetRelease returns releases from Releases
+	TaskLogs map[int]string
+
+	// Requests received by CreateRelease, which also adds the release to Releases
+	NewReleases []api.NewRelease
+
+	// Comment threads keyed by pull request ID; replies and status changes are applied to them
+	Threads map[int][]api.CommentThread
+
+	// Iterations and changed files keyed by pull request ID; the changes are returned for every
+	// iteration. Blobs holds file contents keyed by object ID.
+	Iterations map[int][]api.PullRequestIteration
+	Changes    map[int][]api.PullRequestChange
+	Blobs      map[string]string
+
+	// Definitions listed by GetBuildDefinitions and GetReleaseDefinitions, keyed by project
+	BuildDefinitions   map[string][]api.BuildDefinition
+	ReleaseDefinitions map[string][]api.ReleaseDefinition
+
+	Connection api.ConnectionData
+
+	// Errors returned by operation name (see the Op constants)
+	Errors map[string]error
+
+	// Latency is added to every API call
+	Latency time.Duration
+
+	// Calls records the operations invoked, in order
+	Calls []string
+}
+
+// Ensure Service implements api.Service
+var _ api.Service = (*Service)(nil)
+
+// New creates an empty fake service with a signed-in test user
+func New() *Service {
+	return &Service{
+		Builds:       make(map[string][]api.Build),
+		Releases:     make(map[string][]api.Release),
+		PullRequests: make(map[string][]api.PullRequest),
+		Repositories: make(map[string][]api.GitRepository),
+		Branches:     make(map[string][]string),
+		Threads:      make(map[int][]api.CommentThread),
+		Iterations:   make(map[int][]api.PullRequestIteration),
+		Changes:      make(map[int][]api.PullRequestChange),
+		Blobs:        make(map[string]string),
+		TaskLogs:     make(map[int]string),
+
+		BuildDefinitions:   make(map[string][]api.BuildDefinition),
+		ReleaseDefinitions: make(map[string][]api.ReleaseDefinition),
+
+		Connection: api.ConnectionData{
+			AuthenticatedUser: api.ConnectionIdentity{ID: "fake-user", ProviderDisplayName: "Fake User"},
+		},
+		Errors: make(map[string]error),
+	}
+}
+
+// SetError makes the given operation fail with err; a nil err clears it
+func (s *Service) SetError(op string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err == nil {
+		delete(s.Errors, op)
+		return
+	}
+	s.Errors[op] = err
+}
+
+// call records an operation, waits for the configured latency and returns its error
+func (s *Service) call(ctx context.Context, op string) error {
+	s.mu.Lock()
+	s.Calls = append(s.Calls, op)
+	latency := s.Latency
+	err := s.Errors[op]
+	s.mu.Unlock()
+
+	if latency > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(latency):
+		}
+	}
+
+	return err
+}
+
+// GetBuilds returns the configured builds for a project on the given branches
+func (s *Service) GetBuilds(ctx context.Context, project string, definitionIDs []int, branches []string, maxCount int) ([]api.Build, error) {
+	if err := s.call(ctx, OpGetBuilds); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	filter := api.NewBranchFilter(branches)
+	var matching []api.Build
+	for _, b := range s.Builds[project] {
+		if filter.Matches(b.SourceBranch) {
+			matching = append(matching, b)
+		}
+	}
+	return limit(matching, maxCount), nil
+}
+
+// GetReleases returns the configured releases for a project
+func (s *Service) GetReleases(ctx context.Context, project string, definitionIDs []int, maxCount int) ([]api.Release, error) {
+	if err := s.call(ctx, OpGetReleases); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return limit(s.Releases[project], maxCount), nil
+}
+
+// GetRelease returns a configured release by ID
+func (s *Service) GetRelease(ctx context.Context, project string, releaseID int) (*api.Release, error) {
+	if err := s.call(ctx, OpGetRelease); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range s.Releases[project] {
+		if r.ID == releaseID {
+			return &r, nil
+		}
+	}
+	return nil, &api.APIError{StatusCode: 404, Body: fmt.Sprintf("release %d not found", releaseID)}
+}
+
+// GetReleaseTaskLog returns the configured log of a task
+func (s *Service) GetReleaseTaskLog(ctx context.Context, project string, releaseID, environmentID, deployPhaseID, taskID int) (string, error) {
+	if err := s.call(ctx, OpGetReleaseTaskLog); err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	log, ok := s.TaskLogs[taskID]
+	if !ok {
+		return "", &api.APIError{StatusCode: 404, Body: fmt.Sprintf("log of task %d not found", taskID)}
+	}
+	return log, nil
+}
+
+// GetSuccessfulBuilds returns the configured succeeded builds of a definition
+func (s *Service) GetSuccessfulBuilds(ctx context.Context, project string, definitionID int, maxCount int) ([]api.Build, error) {
+	if err := s.call(ctx, OpGetSuccessfulBuilds); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var builds []api.Build
+	for _, b := range s.Builds[project] {
+		if b.Definition.ID == definitionID && b.Result == api.BuildResultSucceeded {
+			builds = append(builds, b)
+		}
+	}
+	return limit(builds, maxCount), nil
+}
+
+// CreateRelease records the request and adds a release of the definition with its environments
+// not started
+func (s *Service) CreateRelease(ctx context.Context, project string, release api.NewRelease) (*api.Release, error) {
+	if err := s.call(ctx, OpCreateRelease); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.NewReleases = append(s.NewReleases, release)
+	created := api.Release{
+		ID:                1,
+		Status:            api.ReleaseStatusActive,
+		CreatedOn:         time.Now().UTC(),
+		CreatedBy:         api.Identity{ID: s.Connection.AuthenticatedUser.ID, DisplayName: s.Connection.AuthenticatedUser.ProviderDisplayName},
+		ReleaseDefinition: api.ReleaseDefinition{ID: release.DefinitionID},
+	}
+	for _, existing := range s.Releases[project] {
+		if existing.ID >= created.ID {
+			created.ID = existing.ID + 1
+		}
+	}
+	created.Name = fmt.Sprintf("Release-%d", created.ID)
+	for _, d := range s.ReleaseDefinitions[project] {
+		if d.ID == release.DefinitionID {
+			created.ReleaseDefinition = api.ReleaseDefinition{ID: d.ID, Name: d.Name, Path: d.Path}
+			for _, env := range d.Environments {
+				created.Environments = append(created.Environments,
+					api.ReleaseEnvironment{ID: env.ID, Name: env.Name, Status: api.EnvironmentStatusNotStarted})
+			}
+		}
+	}
+	s.Releases[project] = append([]api.Release{created}, s.Releases[project]...)
+	return &created, nil
+}
+
+// GetPullRequests returns the configured pull requests for a project
+func (s *Service) GetPullRequests(ctx context.Context, project string, repositories []string, criteria api.PullRequestCriteria, maxCount int) ([]api.PullRequest, error) {
+	if err := s.call(ctx, OpGetPullRequests); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var matching []api.PullRequest
+	for _, pr := range s.PullRequests[project] {
+		if criteria.Matches(pr) {
+			matching = append(matching, pr)
+		}
+	}
+	return limit(matching, maxCount), nil
+}
+
+// GetPullRequest returns the configured pull request with the given ID
+func (s *Service) GetPullRequest(ctx context.Context, project, repositoryID string, pullRequestID int) (*api.PullRequest, error) {
+	if err := s.call(ctx, OpGetPullRequest); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, pr := range s.PullRequests[project] {
+		if pr.PullRequestID == pullRequestID {
+			return &pr, nil
+		}
+	}
+	return nil, &api.APIError{StatusCode: 404, Body: fmt.Sprintf("pull request %d not found", pullRequestID)}
+}
+
+// GetPullRequestThreads returns the configured threads of a pull request
+func (s *Service) GetPullRequestThreads(ctx context.Context, project, repositoryID string, pullRequestID int) ([]api.CommentThread, error) {
+	if err := s.call(ctx, OpGetThreads); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]api.CommentThread(nil), s.Threads[pullRequestID]...), nil
+}
+
+// ReplyToThread appends a comment to a configured thread
+func (s *Service) ReplyToThread(ctx context.Context, project, repositoryID string, pullRequestID, threadID, parentCommentID int, content string) (*api.Comment, error) {
+	if err := s.call(ctx, OpReplyToThread); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	thread := s.thread(pullRequestID, threadID)
+	if thread == nil {
+		return nil, &api.APIError{StatusCode: 404, Body: fmt.Sprintf("thread %d not found", threadID)}
+	}
+	comment := api.Comment{
+		ID:              len(thread.Comments) + 1,
+		ParentCommentID: parentCommentID,
+		Author:          api.Identity{ID: s.Connection.AuthenticatedUser.ID, DisplayName: s.Connection.AuthenticatedUser.ProviderDisplayName},
+		Content:         content,
+		CommentType:     api.CommentTypeText,
+	}
+	// Copy so threads handed out earlier are not changed
+	thread.Comments = append(append([]api.Comment(nil), thread.Comments...), comment)
+	return &comment, nil
+}
+
+// SetThreadStatus changes the status of a configured thread
+func (s *Service) SetThreadStatus(ctx context.Context, project, repositoryID string, pullRequestID, threadID int, status api.CommentThreadStatus) (*api.CommentThread, error) {
+	if err := s.call(ctx, OpSetThreadStatus); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	thread := s.thread(pullRequestID, threadID)
+	if thread == nil {
+		return nil, &api.APIError{StatusCode: 404, Body: fmt.Sprintf("thread %d not found", threadID)}
+	}
+	thread.Status = status
+	updated := *thread
+	return &updated, nil
+}
+
+// GetPullRequestIterations returns the configured iterations of a pull request
+func (s *Service) GetPullRequestIterations(ctx context.Context, project, repositoryID string, pullRequestID int) ([]api.PullRequestIteration, error) {
+	if err := s.call(ctx, OpGetIterations); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]api.PullRequestIteration(nil), s.Iterations[pullRequestID]...), nil
+}
+
+// GetPullRequestIterationChanges returns the configured changes of a pull request
+func (s *Service) GetPullRequestIterationChanges(ctx context.Context, project, repositoryID string, pullRequestID, iterationID int) ([]api.PullRequestChange, error) {
+	if err := s.call(ctx, OpGetIterationChanges); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]api.PullRequestChange(nil), s.Changes[pullRequestID]...), nil
+}
+
+// GetBlobContent returns the configured content of a blob
+func (s *Service) GetBlobContent(ctx context.Context, project, repositoryID, objectID string) ([]byte, error) {
+	if err := s.call(ctx, OpGetBlobContent); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	content, ok := s.Blobs[objectID]
+	if !ok {
+		return nil, &api.APIError{StatusCode: 404, Body: fmt.Sprintf("blob %s not found", objectID)}
+	}
+	return []byte(content), nil
+}
+
+// CompletePullRequest marks a configured pull request as completed. Like the server, it rejects
+// the request if lastMergeSourceCommit does not match the pull request.
+func (s *Service) CompletePullRequest(ctx context.Context, project, repositoryID string, pullRequestID int, lastMergeSourceCommit string, options api.CompletionOptions) (*api.PullRequest, error) {
+	if err := s.call(ctx, OpCompletePullRequest); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pr := s.pullRequest(project, pullRequestID)
+	if pr == nil {
+		return nil, &api.APIError{StatusCode: 404, Body: fmt.Sprintf("pull request %d not found", pullRequestID)}
+	}
+	if pr.LastMergeSourceCommit == nil || pr.LastMergeSourceCommit.CommitID != lastMergeSourceCommit {
+		return nil, &api.APIError{StatusCode: 409, Body: "the source branch has changed since the pull request was last merged"}
+	}
+	pr.Status = api.PullRequestStatusCompleted
+	pr.ClosedDate = time.Now().UTC()
+	pr.CompletionOptions = &options
+	updated := *pr
+	return &updated, nil
+}
+
+// SetAutoComplete sets or, with an empty userID, cancels auto-complete of a configured pull request
+func (s *Service) SetAutoComplete(ctx context.Context, project, repositoryID string, pullRequestID int, userID string, options api.CompletionOptions) (*api.PullRequest, error) {
+	if err := s.call(ctx, OpSetAutoComplete); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pr := s.pullRequest(project, pullRequestID)
+	if pr == nil {
+		return nil, &api.APIError{StatusCode: 404, Body: fmt.Sprintf("pull request %d not found", pullRequestID)}
+	}
+	pr.AutoCompleteSetBy = nil
+	if userID != "" {
+		pr.AutoCompleteSetBy = &api.Identity{ID: userID}
+	}
+	pr.CompletionOptions = &options
+	updated := *pr
+	return &updated, nil
+}
+
+// GetRepository returns a configured repository by ID or name
+func (s *Service) GetRepository(ctx context.Context, project, repositoryID string) (*api.GitRepository, error) {
+	if err := s.call(ctx, OpGetRepository); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, repo := range s.Repositories[project] {
+		if repo.ID == repositoryID || strings.EqualFold(repo.Name, repositoryID) {
+			return &repo, nil
+		}
+	}
+	return nil, &api.APIError{StatusCode: 404, Body: fmt.Sprintf("repository %s not found", repositoryID)}
+}
+
+// BranchExists returns true if the branch is listed in Branches for the repository
+func (s *Service) BranchExists(ctx context.Context, project, repositoryID, branchRef string) (bool, error) {
+	if err := s.call(ctx, OpBranchExists); err != nil {
+		return false, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ref := range s.Branches[repositoryID] {
+		if ref == branchRef {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CreatePullRequest adds an active pull request to the project
+func (s *Service) CreatePullRequest(ctx context.Context, project, repositoryID string, pr api.NewPullRequest) (*api.PullRequest, error) {
+	if err := s.call(ctx, OpCreatePullRequest); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	created := api.PullRequest{
+		PullRequestID: 1,
+		Title:         pr.Title,
+		Description:   pr.Description,
+		SourceRefName: pr.SourceRefName,
+		TargetRefName: pr.TargetRefName,
+		CreationDate:  time.Now().UTC(),
+		CreatedBy:     api.Identity{ID: s.Connection.AuthenticatedUser.ID, DisplayName: s.Connection.AuthenticatedUser.ProviderDisplayName},
+		Repository:    api.PullRequestRepository{ID: repositoryID, Name: repositoryID},
+		Reviewers:     pr.Reviewers,
+		Status:        api.PullRequestStatusActive,
+		IsDraft:       pr.IsDraft,
+	}
+	for _, existing := range s.PullRequests[project] {
+		if existing.PullRequestID >= created.PullRequestID {
+			created.PullRequestID = existing.PullRequestID + 1
+		}
+	}
+	s.PullRequests[project] = append(s.PullRequests[project], created)
+	return &created, nil
+}
+
+// FindIdentities returns the configured identities whose name, account or e-mail contains query
+func (s *Service) FindIdentities(ctx context.Context, query string) ([]api.ConnectionIdentity, error) {
+	if err := s.call(ctx, OpFindIdentities); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	query = strings.ToLower(query)
+	var found []api.ConnectionIdentity
+	for _, identity := range s.Identities {
+		for _, value := range []string{identity.ProviderDisplayName, identity.Properties.Account.Value, identity.Properties.Mail.Value} {
+			if value != "" && strings.Contains(strings.ToLower(value), query) {
+				found = append(found, identity)
+				break
+			}
+		}
+	}
+	return found, nil
+}
+
+// pullRequest returns a configured pull request; the caller must hold s.mu
+func (s *Service) pullRequest(project string, pullRequestID int) *api.PullRequest {
+	pullRequests := s.PullRequests[project]
+	for i := range pullRequests {
+		if pullRequests[i].PullRequestID == pullRequestID {
+			return &pullRequests[i]
+		}
+	}
+	return nil
+}
+
+// thread returns a configured thread; the caller must hold s.mu
+func (s *Service) thread(pullRequestID, threadID int) *api.CommentThread {
+	threads := s.Threads[pullRequestID]
+	for i := range threads {
+		if threads[i].ID == threadID {
+			return &threads[i]
+		}
+	}
+	return nil
+}
+
+// GetConnectionData returns the configured connection data
+func (s *Service) GetConnectionData(ctx context.Context) (*api.ConnectionData, error) {
+	if err := s.call(ctx, OpGetConnectionData); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conn := s.Connection
+	return &conn, nil
+}
+
+// GetProjects returns the configured projects
+func (s *Service) GetProjects(ctx context.Context) ([]api.TeamProject, error) {
+	if err := s.call(ctx, OpGetProjects); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]api.TeamProject(nil), s.Projects...), nil
+}
+
+// GetProject returns a project with the given name
+func (s *Service) GetProject(ctx context.Context, project string) (*api.TeamProject, error) {
+	if err := s.call(ctx, OpGetProject); err != nil {
+		return nil, err
+	}
+	return &api.TeamProject{ID: project, Name: project}, nil
+}
+
+// GetBuildDefinition returns a build definition with the given ID
+func (s *Service) GetBuildDefinition(ctx context.Context, project string, definitionID int) (*api.BuildDefinition, error) {
+	if err := s.call(ctx, OpGetBuildDefinition); err != nil {
+		return nil, err
+	}
+	return &api.BuildDefinition{ID: definitionID, Name: fmt.Sprintf("definition-%d", definitionID)}, nil
+}
+
+// GetReleaseDefinition returns the configured release definition with the given ID, or one named after it
+func (s *Service) GetReleaseDefinition(ctx context.Context, project string, definitionID int) (*api.ReleaseDefinition, error) {
+	if err := s.call(ctx, OpGetReleaseDefinition); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, d := range s.ReleaseDefinitions[project] {
+		if d.ID == definitionID {
+			return &d, nil
+		}
+	}
+	return &api.ReleaseDefinition{ID: definitionID, Name: fmt.Sprintf("definition-%d", definitionID)}, nil
+}
+
+// GetBuildDefinitions returns the configured build definitions for a project
+func (s *Service) GetBuildDefinitions(ctx context.Context, project string) ([]api.BuildDefinition, error) {
+	if err := s.call(ctx, OpGetBuildDefinitions); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]api.BuildDefinition(nil), s.BuildDefinitions[project]...), nil
+}
+
+// GetReleaseDefinitions returns the configured release definitions for a project
+func (s *Service) GetReleaseDefinitions(ctx context.Context, project string) ([]api.ReleaseDefinition, error) {
+	if err := s.call(ctx, OpGetReleaseDefinitions); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]api.ReleaseDefinition(nil), s.ReleaseDefinitions[project]...), nil
+}
+
+// CheckBuildAccess returns the configured error, if any
+func (s *Service) CheckBuildAccess(ctx context.Context, project string) error {
+	return s.call(ctx, OpCheckBuildAccess)
+}
+
+// CheckReleaseAccess returns the configured error, if any
+func (s *Service) CheckReleaseAccess(ctx context.Context, project string) error {
+	return s.call(ctx, OpCheckReleaseAccess)
+}
+
+// CheckCodeAccess returns the configured error, if any
+func (s *Service) CheckCodeAccess(ctx context.Context, project string) error {
+	return s.call(ctx, OpCheckCodeAccess)
+}
+
+// GetOrganizationURL returns a fixed fake organization URL
+func (s *Service) GetOrganizationURL() string {
+	return "https://dev.azure.com/fake"
+}
+
+// GetBuildWebURL returns the web URL for a build
+func (s *Service) GetBuildWebURL(project string, buildID int) string {
+	return fmt.Sprintf("%s/%s/_build/results?buildId=%d", s.GetOrganizationURL(), project, buildID)
+}
+
+// GetReleaseWebURL returns the web URL for a release
+func (s *Service) GetReleaseWebURL(project string, releaseID int) string {
+	return fmt.Sprintf("%s/%s/_releaseProgress?releaseId=%d", s.GetOrganizationURL(), project, releaseID)
+}
+
+// GetPullRequestWebURL returns the web URL for a pull request
+func (s *Service) GetPullRequestWebURL(project, repoName string, prID int) string {
+	return fmt.Sprintf("%s/%s/_git/%s/pullrequest/%d", s.GetOrganizationURL(), project, repoName, prID)
+}
+
+// limit returns a copy of at most maxCount items
+func limit[T any](items []T, maxCount int) []T {
+	if maxCount > 0 && len(items) > maxCount {
+		items = items[:maxCount]
+	}
+	return append([]T(nil), items...)
+}
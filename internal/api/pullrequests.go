@@ -20,6 +20,16 @@ func (pr *PullRequest) GetBranchSummary() string {
 	return fmt.Sprintf("%s -> %s", pr.GetSourceBranch(), pr.GetTargetBranch())
 }
 
+// HasReviewer returns true if the identity is one of the reviewers
+func (pr *PullRequest) HasReviewer(id string) bool {
+	for _, r := range pr.Reviewers {
+		if strings.EqualFold(r.ID, id) {
+			return true
+		}
+	}
+	return false
+}
+
 // GetReviewerSummary returns a summary of reviewer votes
 func (pr *PullRequest) GetReviewerSummary() string {
 	if len(pr.Reviewers) == 0 {
@@ -61,15 +71,32 @@ func (pr *PullRequest) GetReviewerSummary() string {
 
 // GetStatusDisplay returns a display string for the PR status
 func (pr *PullRequest) GetStatusDisplay() string {
+	if pr.IsCompleted() || pr.IsAbandoned() {
+		return string(pr.Status)
+	}
 	if pr.IsDraft {
 		return "draft"
 	}
 	if pr.MergeStatus == "conflicts" {
 		return "conflicts"
 	}
+	if pr.IsAutoComplete() {
+		return "autocomplete"
+	}
 	return string(pr.Status)
 }
 
+// GetMergeCommitShort returns the abbreviated merge commit of a completed pull request, or "-"
+func (pr *PullRequest) GetMergeCommitShort() string {
+	if !pr.IsCompleted() || pr.MergeCommit == nil || pr.MergeCommit.CommitID == "" {
+		return "-"
+	}
+	if len(pr.MergeCommit.CommitID) > 8 {
+		return pr.MergeCommit.CommitID[:8]
+	}
+	return pr.MergeCommit.CommitID
+}
+
 // IsActive returns true if the pull request is active
 func (pr *PullRequest) IsActive() bool {
 	return pr.Status == PullRequestStatusActive
@@ -103,6 +130,49 @@ func (pr *PullRequest) IsApproved() bool {
 	return true
 }
 
+// IsAutoComplete returns true if the pull request completes once its policies pass
+func (pr *PullRequest) IsAutoComplete() bool {
+	return pr.AutoCompleteSetBy != nil && pr.AutoCompleteSetBy.ID != ""
+}
+
+// CompletionBlockers returns the reasons the pull request cannot be completed now, empty if it can
+func (pr *PullRequest) CompletionBlockers() []string {
+	if !pr.IsActive() {
+		return []string{"pull request is " + string(pr.Status)}
+	}
+
+	var reasons []string
+	if pr.IsDraft {
+		reasons = append(reasons, "pull request is a draft")
+	}
+	if pr.HasConflicts() {
+		reasons = append(reasons, "merge conflicts")
+	}
+	if !pr.IsApproved() {
+		reasons = append(reasons, "not approved by all reviewers")
+	}
+	if pr.PolicyError != "" {
+		reasons = append(reasons, "policies unknown: "+pr.PolicyError)
+	}
+	for _, p := range pr.BlockingPolicies() {
+		reasons = append(reasons, "policy not passed: "+p.Name())
+	}
+	if pr.LastMergeSourceCommit == nil || pr.LastMergeSourceCommit.CommitID == "" {
+		reasons = append(reasons, "source commit unknown")
+	}
+	return reasons
+}
+
+// CanComplete returns true if the pull request is approved and its required policies are known to pass
+func (pr *PullRequest) CanComplete() bool {
+	return len(pr.CompletionBlockers()) == 0
+}
+
+// CanAutoComplete returns true if auto-complete can be set, which waits for approvals and policies
+func (pr *PullRequest) CanAutoComplete() bool {
+	return pr.IsActive() && !pr.IsDraft
+}
+
 // HasRejections returns true if any reviewer has rejected
 func (pr *PullRequest) HasRejections() bool {
 	for _, r := range pr.Reviewers {
@@ -112,3 +182,107 @@ func (pr *PullRequest) HasRejections() bool {
 	}
 	return false
 }
+
+// GetPolicySummary returns the passed, failed and running policy counts, e.g. "✓3 ✗1 ○1",
+// or "?" if the policy evaluations could not be fetched
+func (pr *PullRequest) GetPolicySummary() string {
+	if pr.PolicyError != "" {
+		return "?"
+	}
+	var passed, failed, running int
+	for _, p := range pr.Policies {
+		switch {
+		case p.IsPassed():
+			passed++
+		case p.IsFailed():
+			failed++
+		case p.IsRunning():
+			running++
+		}
+	}
+	if passed+failed+running == 0 {
+		return "-"
+	}
+
+	parts := []string{fmt.Sprintf("✓%d", passed)}
+	if failed > 0 {
+		parts = append(parts, fmt.Sprintf("✗%d", failed))
+	}
+	if running > 0 {
+		parts = append(parts, fmt.Sprintf("○%d", running))
+	}
+	return strings.Join(parts, " ")
+}
+
+// BlockingPolicies returns the required policies that prevent the pull request from completing
+func (pr *PullRequest) BlockingPolicies() []PolicyEvaluation {
+	var blocking []PolicyEvaluation
+	for _, p := range pr.Policies {
+		if p.IsBlocking() {
+			blocking = append(blocking, p)
+		}
+	}
+	return blocking
+}
+
+// Name returns the display name of the evaluated policy, e.g. "Build (payments-ci)"
+func (p PolicyEvaluation) Name() string {
+	name := p.Configuration.Type.DisplayName
+	if name == "" {
+		name = "Policy"
+	}
+	if p.Configuration.Settings.DisplayName != "" {
+		name += " (" + p.Configuration.Settings.DisplayName + ")"
+	}
+	return name
+}
+
+// IsPassed returns true if the policy is satisfied
+func (p PolicyEvaluation) IsPassed() bool {
+	return p.Status == PolicyEvaluationStatusApproved
+}
+
+// IsFailed returns true if the policy rejected the pull request or could not be evaluated
+func (p PolicyEvaluation) IsFailed() bool {
+	return p.Status == PolicyEvaluationStatusRejected || p.Status == PolicyEvaluationStatusBroken
+}
+
+// IsRunning returns true if the policy is still being evaluated
+func (p PolicyEvaluation) IsRunning() bool {
+	return p.Status == PolicyEvaluationStatusQueued || p.Status == PolicyEvaluationStatusRunning
+}
+
+// IsBlocking returns true if the policy is required and not yet satisfied
+func (p PolicyEvaluation) IsBlocking() bool {
+	return p.Configuration.IsBlocking && (p.IsFailed() || p.IsRunning())
+}
+
+// GetDisplay returns the merge strategy as shown in the completion form
+func (s MergeStrategy) GetDisplay() string {
+	switch s {
+	case MergeStrategyNoFastForward:
+		return "merge commit"
+	case MergeStrategySquash:
+		return "squash"
+	case MergeStrategyRebase:
+		return "rebase and fast-forward"
+	default:
+		return string(s)
+	}
+}
+
+// GetVoteDisplay returns the vote of a reviewer as text
+func (r Reviewer) GetVoteDisplay() string {
+	switch {
+	case r.Vote >= 10:
+		return "approved"
+	case r.Vote == 5:
+		return "approved with suggestions"
+	case r.Vote <= -10:
+		return "rejected"
+	case r.Vote == -5:
+		return "waiting for author"
+	default:
+		return "no vote"
+	}
+}
@@ -0,0 +1,303 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// redacted replaces secret values in recordings
+const redacted = "REDACTED"
+
+// sensitiveQueryParams are query parameters whose values are removed from recorded URLs
+var sensitiveQueryParams = []string{"access_token", "token", "pat", "code", "sig", "key", "secret", "password"}
+
+var (
+	// secretAssignmentPattern matches values assigned to secret-looking names, e.g. token=abc or "password": "abc"
+	secretAssignmentPattern = regexp.MustCompile(`(?i)([\w.-]*(?:token|secret|password|pat|key|sig))(["']?\s*[:=]\s*["']?)[^\s"',;&]+`)
+	// authSchemePattern matches credentials following an HTTP auth scheme
+	authSchemePattern = regexp.MustCompile(`(?i)\b(bearer|basic)\s+[\w+/=.~-]+`)
+	// longTokenPattern matches long runs of token characters such as PATs, JWTs and base64 credentials
+	longTokenPattern = regexp.MustCompile(`[A-Za-z0-9+_=.~-]{32,}`)
+)
+
+// Recording is a single recorded API exchange, stored as one JSON file
+type Recording struct {
+	Method         string            `json:"method"`
+	URL            string            `json:"url"`
+	RequestHeaders map[string]string `json:"requestHeaders"`
+	StatusCode     int               `json:"statusCode"`
+	Headers        map[string]string `json:"headers"`
+	Body           json.RawMessage   `json:"body,omitempty"`     // Response body when it is valid JSON
+	BodyText       string            `json:"bodyText,omitempty"` // Response body otherwise
+	RecordedAt     time.Time         `json:"recordedAt"`
+}
+
+// RecordingTransport saves every response to a directory, with secrets stripped
+type RecordingTransport struct {
+	Dir  string
+	Next http.RoundTripper // Defaults to http.DefaultTransport
+}
+
+// RoundTrip performs the request and records the response
+func (t *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	bodyHash, err := requestBodyHash(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	rec := Recording{
+		Method:         req.Method,
+		URL:            SanitizeURL(req.URL),
+		RequestHeaders: sanitizeHeaders(req.Header),
+		StatusCode:     resp.StatusCode,
+		Headers:        sanitizeHeaders(resp.Header),
+		RecordedAt:     time.Now().UTC(),
+	}
+	if json.Valid(body) {
+		rec.Body = body
+	} else {
+		rec.BodyText = string(body)
+	}
+
+	if err := writeRecording(t.Dir, rec, bodyHash); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// ReplayTransport serves recorded responses instead of using the network
+type ReplayTransport struct {
+	Dir string
+}
+
+// RoundTrip returns the recorded response for the request
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	bodyHash, err := requestBodyHash(req)
+	if err != nil {
+		return nil, err
+	}
+	sanitized := SanitizeURL(req.URL)
+	path := filepath.Join(t.Dir, recordingFileName(req.Method, sanitized, bodyHash))
+
+	var rec Recording
+	data, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		// Answer with a 404 rather than a transport error so the request is not retried
+		message, _ := json.Marshal(map[string]string{"message": fmt.Sprintf("no recording for %s %s", req.Method, sanitized)})
+		rec = Recording{StatusCode: http.StatusNotFound, Body: message}
+	case err != nil:
+		return nil, fmt.Errorf("failed to read recording: %w", err)
+	default:
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return nil, fmt.Errorf("failed to parse recording %s: %w", filepath.Base(path), err)
+		}
+	}
+
+	body := []byte(rec.BodyText)
+	if len(rec.Body) > 0 {
+		body = rec.Body
+	}
+
+	header := make(http.Header)
+	for k, v := range rec.Headers {
+		header.Set(k, v)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.StatusCode, http.StatusText(rec.StatusCode)),
+		StatusCode:    rec.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// SanitizeURL returns the URL with credentials and secret query parameters removed
+func SanitizeURL(u *url.URL) string {
+	clean := *u
+	clean.User = nil
+
+	query := clean.Query()
+	changed := false
+	for key := range query {
+		if isSensitiveName(key) {
+			query.Set(key, redacted)
+			changed = true
+		}
+	}
+	if changed {
+		clean.RawQuery = query.Encode()
+	}
+
+	return clean.String()
+}
+
+// redactText masks credential-looking values in free-form text such as command output
+func redactText(text string) string {
+	text = secretAssignmentPattern.ReplaceAllString(text, "${1}${2}"+redacted)
+	text = authSchemePattern.ReplaceAllString(text, "${1} "+redacted)
+	return longTokenPattern.ReplaceAllString(text, redacted)
+}
+
+// sanitizeHeaders flattens headers and redacts credentials, cookies and token headers
+// such as X-Vss-Token or X-TFS-FedAuthToken
+func sanitizeHeaders(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for key := range h {
+		canonical := http.CanonicalHeaderKey(key)
+		switch {
+		case canonical == "Authorization", canonical == "Proxy-Authorization",
+			canonical == "Cookie", canonical == "Set-Cookie":
+			out[key] = redacted
+		case strings.HasPrefix(canonical, "X-") && strings.HasSuffix(strings.ToLower(canonical), "token"):
+			out[key] = redacted
+		default:
+			out[key] = h.Get(key)
+		}
+	}
+	return out
+}
+
+// requestBodyHash returns a hash of the redacted request body, or "" for requests without one.
+// The body is read and put back so the request can still be sent.
+func requestBodyHash(req *http.Request) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return "", nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to read request body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	if len(body) == 0 {
+		return "", nil
+	}
+
+	sum := sha256.Sum256(redactBody(body))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// redactBody replaces the values of secret fields in a JSON body.
+// Other bodies are returned unchanged.
+func redactBody(body []byte) []byte {
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return body
+	}
+	redacted, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return body
+	}
+	return redacted
+}
+
+// redactValue walks decoded JSON and redacts fields named like sensitive query parameters
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if isSensitiveName(key) {
+				v[key] = redacted
+			} else {
+				v[key] = redactValue(field)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	}
+	return value
+}
+
+// isSensitiveName reports whether a query parameter or field name holds a secret
+func isSensitiveName(name string) bool {
+	for _, sensitive := range sensitiveQueryParams {
+		if strings.EqualFold(name, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordingFileName derives a stable file name from the request method, sanitized URL
+// and the hash of the redacted request body, so writes with different payloads do not
+// overwrite each other
+func recordingFileName(method, sanitizedURL, bodyHash string) string {
+	key := method + " " + sanitizedURL
+	if bodyHash != "" {
+		key += " " + bodyHash
+	}
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:12]) + ".json"
+}
+
+// writeRecording stores a recording in the directory
+func writeRecording(dir string, rec Recording, bodyHash string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create recording directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode recording: %w", err)
+	}
+
+	// Write to a temporary file first so concurrent requests for the same URL never leave a partial file
+	tmp, err := os.CreateTemp(dir, ".recording-*")
+	if err != nil {
+		return fmt.Errorf("failed to write recording: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write recording: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write recording: %w", err)
+	}
+
+	path := filepath.Join(dir, recordingFileName(rec.Method, rec.URL, bodyHash))
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write recording: %w", err)
+	}
+	return nil
+}
@@ -1,5 +1,10 @@
 package api
 
+import (
+	"strconv"
+	"time"
+)
+
 // GetOverallStatus determines the overall status of a release based on its environments
 func (r *Release) GetOverallStatus() EnvironmentStatus {
 	if len(r.Environments) == 0 {
@@ -109,3 +114,115 @@ func (r *Release) HasInProgressEnvironment() bool {
 	}
 	return false
 }
+
+// LatestDeployStep returns the last deploy attempt of the environment, nil if it has not been deployed
+func (e *ReleaseEnvironment) LatestDeployStep() *DeployStep {
+	var latest *DeployStep
+	for i := range e.DeploySteps {
+		if latest == nil || e.DeploySteps[i].Attempt > latest.Attempt {
+			latest = &e.DeploySteps[i]
+		}
+	}
+	return latest
+}
+
+// ApprovalsForAttempt returns the pre- and post-deployment approvals of a deploy attempt
+func (e *ReleaseEnvironment) ApprovalsForAttempt(attempt int) (pre, post []ReleaseApproval) {
+	for _, a := range e.PreDeployApprovals {
+		if a.Attempt == attempt {
+			pre = append(pre, a)
+		}
+	}
+	for _, a := range e.PostDeployApprovals {
+		if a.Attempt == attempt {
+			post = append(post, a)
+		}
+	}
+	return pre, post
+}
+
+// GetApproverDisplay returns who approved, or who has to approve while the approval is pending
+func (a *ReleaseApproval) GetApproverDisplay() string {
+	if a.IsAutomated {
+		return "automatic"
+	}
+	if a.ApprovedBy != nil && a.ApprovedBy.DisplayName != "" {
+		return a.ApprovedBy.DisplayName
+	}
+	return a.Approver.DisplayName
+}
+
+// Tasks returns the tasks of all jobs of the phase, in order
+func (p *ReleaseDeployPhase) Tasks() []ReleaseTask {
+	var tasks []ReleaseTask
+	for _, job := range p.DeploymentJobs {
+		tasks = append(tasks, job.Tasks...)
+	}
+	return tasks
+}
+
+// Tasks returns the gate evaluations, one task per gate
+func (g *ReleaseGates) Tasks() []ReleaseTask {
+	var tasks []ReleaseTask
+	for _, job := range g.DeploymentJobs {
+		tasks = append(tasks, job.Tasks...)
+	}
+	return tasks
+}
+
+// IsFailed returns true if the task failed
+func (t *ReleaseTask) IsFailed() bool {
+	return t.Status == TaskStatusFailure || t.Status == TaskStatusFailed
+}
+
+// IsSucceeded returns true if the task succeeded
+func (t *ReleaseTask) IsSucceeded() bool {
+	return t.Status == TaskStatusSuccess || t.Status == TaskStatusSucceeded
+}
+
+// GetDuration returns how long the task ran, up to now while it is running
+func (t *ReleaseTask) GetDuration() time.Duration {
+	if t.StartTime.IsZero() {
+		return 0
+	}
+
+	endTime := t.FinishTime
+	if endTime.IsZero() {
+		endTime = time.Now()
+	}
+
+	return endTime.Sub(t.StartTime)
+}
+
+// BuildArtifacts returns the artifacts of the definition that are produced by build pipelines
+func (d *ReleaseDefinition) BuildArtifacts() []DefinitionArtifact {
+	var artifacts []DefinitionArtifact
+	for _, a := range d.Artifacts {
+		if a.Type == "Build" && a.BuildDefinitionID() != 0 {
+			artifacts = append(artifacts, a)
+		}
+	}
+	return artifacts
+}
+
+// BuildDefinitionID returns the ID of the build definition producing the artifact, 0 if unknown
+func (a *DefinitionArtifact) BuildDefinitionID() int {
+	id, _ := strconv.Atoi(a.DefinitionReference["definition"].ID)
+	return id
+}
+
+// SourceProject returns the name of the project the artifact is produced in, empty if unknown
+func (a *DefinitionArtifact) SourceProject() string {
+	return a.DefinitionReference["project"].Name
+}
+
+// IsAutomated returns true if the environment is deployed without a manual request, when the
+// release is created or after another environment
+func (e *DefinitionEnvironment) IsAutomated() bool {
+	for _, c := range e.Conditions {
+		if c.ConditionType != "" && c.ConditionType != "undefined" {
+			return true
+		}
+	}
+	return false
+}
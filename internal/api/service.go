@@ -0,0 +1,58 @@
+package api
+
+import "context"
+
+// Service is the set of Azure DevOps operations used by the dashboard.
+// Client implements it against the REST API; the fake package provides an in-memory version.
+type Service interface {
+	// Builds, releases and pull requests
+	GetBuilds(ctx context.Context, project string, definitionIDs []int, branches []string, maxCount int) ([]Build, error)
+	GetReleases(ctx context.Context, project string, definitionIDs []int, maxCount int) ([]Release, error)
+	GetPullRequests(ctx context.Context, project string, repositories []string, criteria PullRequestCriteria, maxCount int) ([]PullRequest, error)
+
+	// Pull request details and review
+	GetPullRequest(ctx context.Context, project, repositoryID string, pullRequestID int) (*PullRequest, error)
+	GetPullRequestThreads(ctx context.Context, project, repositoryID string, pullRequestID int) ([]CommentThread, error)
+	ReplyToThread(ctx context.Context, project, repositoryID string, pullRequestID, threadID, parentCommentID int, content string) (*Comment, error)
+	SetThreadStatus(ctx context.Context, project, repositoryID string, pullRequestID, threadID int, status CommentThreadStatus) (*CommentThread, error)
+	GetPullRequestIterations(ctx context.Context, project, repositoryID string, pullRequestID int) ([]PullRequestIteration, error)
+	GetPullRequestIterationChanges(ctx context.Context, project, repositoryID string, pullRequestID, iterationID int) ([]PullRequestChange, error)
+	GetBlobContent(ctx context.Context, project, repositoryID, objectID string) ([]byte, error)
+	CompletePullRequest(ctx context.Context, project, repositoryID string, pullRequestID int, lastMergeSourceCommit string, options CompletionOptions) (*PullRequest, error)
+	SetAutoComplete(ctx context.Context, project, repositoryID string, pullRequestID int, userID string, options CompletionOptions) (*PullRequest, error)
+
+	// Creating pull requests
+	GetRepository(ctx context.Context, project, repositoryID string) (*GitRepository, error)
+	BranchExists(ctx context.Context, project, repositoryID, branchRef string) (bool, error)
+	CreatePullRequest(ctx context.Context, project, repositoryID string, pr NewPullRequest) (*PullRequest, error)
+	FindIdentities(ctx context.Context, query string) ([]ConnectionIdentity, error)
+
+	// Release details
+	GetRelease(ctx context.Context, project string, releaseID int) (*Release, error)
+	GetReleaseTaskLog(ctx context.Context, project string, releaseID, environmentID, deployPhaseID, taskID int) (string, error)
+
+	// Release creation
+	GetSuccessfulBuilds(ctx context.Context, project string, definitionID int, maxCount int) ([]Build, error)
+	CreateRelease(ctx context.Context, project string, release NewRelease) (*Release, error)
+
+	// Connection and configuration checks
+	GetConnectionData(ctx context.Context) (*ConnectionData, error)
+	GetProjects(ctx context.Context) ([]TeamProject, error)
+	GetProject(ctx context.Context, project string) (*TeamProject, error)
+	GetBuildDefinition(ctx context.Context, project string, definitionID int) (*BuildDefinition, error)
+	GetReleaseDefinition(ctx context.Context, project string, definitionID int) (*ReleaseDefinition, error)
+	GetBuildDefinitions(ctx context.Context, project string) ([]BuildDefinition, error)
+	GetReleaseDefinitions(ctx context.Context, project string) ([]ReleaseDefinition, error)
+	CheckBuildAccess(ctx context.Context, project string) error
+	CheckReleaseAccess(ctx context.Context, project string) error
+	CheckCodeAccess(ctx context.Context, project string) error
+
+	// Web URLs
+	GetOrganizationURL() string
+	GetBuildWebURL(project string, buildID int) string
+	GetReleaseWebURL(project string, releaseID int) string
+	GetPullRequestWebURL(project, repoName string, prID int) string
+}
+
+// Ensure Client implements Service
+var _ Service = (*Client)(nil)
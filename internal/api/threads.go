@@ -0,0 +1,78 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IsActive returns true if the thread is waiting to be resolved
+func (t *CommentThread) IsActive() bool {
+	return !t.IsDeleted && (t.Status == CommentThreadStatusActive || t.Status == CommentThreadStatusPending)
+}
+
+// IsSystem returns true if the thread only holds system comments, e.g. vote or push notifications
+func (t *CommentThread) IsSystem() bool {
+	for _, c := range t.Comments {
+		if c.CommentType != CommentTypeSystem {
+			return false
+		}
+	}
+	return true
+}
+
+// GetLocation returns the file and line a thread refers to, e.g. "/src/refunds.go:42",
+// or an empty string for threads on the pull request itself
+func (t *CommentThread) GetLocation() string {
+	if t.ThreadContext == nil || t.ThreadContext.FilePath == "" {
+		return ""
+	}
+	switch {
+	case t.ThreadContext.RightFileStart != nil:
+		return fmt.Sprintf("%s:%d", t.ThreadContext.FilePath, t.ThreadContext.RightFileStart.Line)
+	case t.ThreadContext.LeftFileStart != nil:
+		return fmt.Sprintf("%s:%d (deleted)", t.ThreadContext.FilePath, t.ThreadContext.LeftFileStart.Line)
+	default:
+		return t.ThreadContext.FilePath
+	}
+}
+
+// VisibleComments returns the comments that are not deleted
+func (t *CommentThread) VisibleComments() []Comment {
+	var comments []Comment
+	for _, c := range t.Comments {
+		if !c.IsDeleted {
+			comments = append(comments, c)
+		}
+	}
+	return comments
+}
+
+// RootCommentID returns the ID of the first comment that is not deleted, which replies are
+// attached to, or 0 if there is none
+func (t *CommentThread) RootCommentID() int {
+	if comments := t.VisibleComments(); len(comments) > 0 {
+		return comments[0].ID
+	}
+	return 0
+}
+
+// GetSummary returns the first line of the first comment
+func (t *CommentThread) GetSummary() string {
+	comments := t.VisibleComments()
+	if len(comments) == 0 {
+		return ""
+	}
+	summary, _, _ := strings.Cut(strings.TrimSpace(comments[0].Content), "\n")
+	return summary
+}
+
+// ActiveThreads returns the threads of a pull request that are waiting to be resolved
+func ActiveThreads(threads []CommentThread) []CommentThread {
+	var active []CommentThread
+	for _, t := range threads {
+		if t.IsActive() && !t.IsSystem() {
+			active = append(active, t)
+		}
+	}
+	return active
+}
@@ -0,0 +1,217 @@
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeServerCA writes the certificate of a TLS test server to a PEM file
+func writeServerCA(t *testing.T, srv *httptest.Server) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// writeClientCert generates a self-signed client certificate and returns its cert, key and parsed form
+func writeClientCert(t *testing.T) (string, string, *x509.Certificate) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "azdo-tui test client"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "client.pem")
+	keyPath := filepath.Join(dir, "client-key.pem")
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certPath, keyPath, cert
+}
+
+// newTestClient creates a client for a test server using the given transport settings
+func newTestClient(t *testing.T, srv *httptest.Server, cfg TransportConfig) *Client {
+	t.Helper()
+	transport, err := NewTransport(cfg)
+	if err != nil {
+		t.Fatalf("NewTransport: %v", err)
+	}
+	return NewClient(ClientConfig{
+		CollectionURL:     srv.URL + "/DefaultCollection",
+		Server:            true,
+		PAT:               "test",
+		RequestsPerSecond: 100,
+		BurstSize:         100,
+		Timeout:           5 * time.Second,
+		Transport:         transport,
+	})
+}
+
+func connectionDataHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(`{"authenticatedUser":{"id":"1","providerDisplayName":"Test User"}}`))
+}
+
+func TestNewTransportCABundle(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(connectionDataHandler))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		cfg     TransportConfig
+		wantErr bool
+	}{
+		{name: "untrusted server", cfg: TransportConfig{}, wantErr: true},
+		{name: "trusted via CA bundle", cfg: TransportConfig{CABundle: writeServerCA(t, srv)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, srv, tt.cfg)
+			conn, err := client.GetConnectionData(context.Background())
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected a certificate error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetConnectionData: %v", err)
+			}
+			if conn.AuthenticatedUser.ProviderDisplayName != "Test User" {
+				t.Errorf("user = %q, want %q", conn.AuthenticatedUser.ProviderDisplayName, "Test User")
+			}
+		})
+	}
+}
+
+func TestNewTransportClientCertificate(t *testing.T) {
+	certPath, keyPath, cert := writeClientCert(t)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(cert)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(connectionDataHandler))
+	srv.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	}
+	srv.StartTLS()
+	defer srv.Close()
+
+	caBundle := writeServerCA(t, srv)
+
+	withoutCert := newTestClient(t, srv, TransportConfig{CABundle: caBundle})
+	if _, err := withoutCert.GetConnectionData(context.Background()); err == nil {
+		t.Error("expected handshake failure without a client certificate")
+	}
+
+	withCert := newTestClient(t, srv, TransportConfig{
+		CABundle:   caBundle,
+		ClientCert: certPath,
+		ClientKey:  keyPath,
+	})
+	if _, err := withCert.GetConnectionData(context.Background()); err != nil {
+		t.Errorf("GetConnectionData with client certificate: %v", err)
+	}
+}
+
+func TestNewTransportTLSMinVersion(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(connectionDataHandler))
+	srv.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	srv.StartTLS()
+	defer srv.Close()
+
+	caBundle := writeServerCA(t, srv)
+
+	tls12 := newTestClient(t, srv, TransportConfig{CABundle: caBundle, TLSMinVersion: "1.2"})
+	if _, err := tls12.GetConnectionData(context.Background()); err != nil {
+		t.Errorf("TLS 1.2 client: %v", err)
+	}
+
+	tls13 := newTestClient(t, srv, TransportConfig{CABundle: caBundle, TLSMinVersion: "1.3"})
+	if _, err := tls13.GetConnectionData(context.Background()); err == nil {
+		t.Error("expected TLS 1.3 client to reject a TLS 1.2 server")
+	}
+}
+
+func TestNewTransportProxy(t *testing.T) {
+	transport, err := NewTransport(TransportConfig{ProxyURL: "http://proxy.example.com:8080"})
+	if err != nil {
+		t.Fatalf("NewTransport: %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://dev.azure.com/org/_apis/connectionData", nil)
+	proxyURL, err := transport.Proxy(req)
+	if err != nil {
+		t.Fatalf("Proxy: %v", err)
+	}
+	want, _ := url.Parse("http://proxy.example.com:8080")
+	if proxyURL == nil || proxyURL.String() != want.String() {
+		t.Errorf("proxy = %v, want %v", proxyURL, want)
+	}
+}
+
+func TestNewTransportErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  TransportConfig
+	}{
+		{name: "unknown TLS version", cfg: TransportConfig{TLSMinVersion: "2.0"}},
+		{name: "missing CA bundle", cfg: TransportConfig{CABundle: filepath.Join(t.TempDir(), "missing.pem")}},
+		{name: "cert without key", cfg: TransportConfig{ClientCert: "client.pem"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewTransport(tt.cfg); err == nil {
+				t.Error("expected an error, got nil")
+			}
+		})
+	}
+}
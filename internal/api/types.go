@@ -84,6 +84,7 @@ type Build struct {
 type BuildDefinition struct {
 	ID   int    `json:"id"`
 	Name string `json:"name"`
+	Path string `json:"path,omitempty"` // Folder, e.g. \Services\Payments
 }
 
 // BuildLinks contains links related to a build
@@ -131,6 +132,7 @@ const (
 type Release struct {
 	ID                int                  `json:"id"`
 	Name              string               `json:"name"`
+	Description       string               `json:"description,omitempty"`
 	Status            ReleaseStatus        `json:"status"`
 	CreatedOn         time.Time            `json:"createdOn"`
 	ModifiedOn        time.Time            `json:"modifiedOn"`
@@ -145,6 +147,56 @@ type Release struct {
 type ReleaseDefinition struct {
 	ID   int    `json:"id"`
 	Name string `json:"name"`
+	Path string `json:"path,omitempty"` // Folder, e.g. \Services\Payments
+
+	// Only returned when fetching a single definition
+	Artifacts    []DefinitionArtifact    `json:"artifacts,omitempty"`
+	Environments []DefinitionEnvironment `json:"environments,omitempty"`
+}
+
+// DefinitionArtifact represents an artifact source linked to a release definition
+type DefinitionArtifact struct {
+	Alias     string `json:"alias"` // e.g. "_payments-api-ci"
+	Type      string `json:"type"`  // e.g. "Build", "Git" or "PackageManagement"
+	IsPrimary bool   `json:"isPrimary"`
+
+	// Source of the artifact by key, e.g. "definition" and "project" for build artifacts
+	DefinitionReference map[string]ArtifactSourceReference `json:"definitionReference"`
+}
+
+// ArtifactSourceReference identifies an artifact source or version by ID and name
+type ArtifactSourceReference struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// DefinitionEnvironment represents an environment (stage) of a release definition
+type DefinitionEnvironment struct {
+	ID         int                    `json:"id"`
+	Name       string                 `json:"name"`
+	Rank       int                    `json:"rank"`
+	Conditions []EnvironmentCondition `json:"conditions"` // Triggers of the environment, empty when only deployed manually
+}
+
+// EnvironmentCondition represents a trigger of an environment
+type EnvironmentCondition struct {
+	Name          string `json:"name"`          // e.g. "ReleaseStarted" or the name of the previous environment
+	ConditionType string `json:"conditionType"` // "event", "environmentState", "artifact" or "undefined"
+	Value         string `json:"value"`
+}
+
+// NewRelease is the request body creating a release
+type NewRelease struct {
+	DefinitionID       int                `json:"definitionId"`
+	Description        string             `json:"description,omitempty"`
+	Artifacts          []ArtifactMetadata `json:"artifacts,omitempty"`          // Versions of the artifacts; others use their default version
+	ManualEnvironments []string           `json:"manualEnvironments,omitempty"` // Environments not deployed automatically in this release
+}
+
+// ArtifactMetadata selects the version of an artifact of a new release
+type ArtifactMetadata struct {
+	Alias             string                  `json:"alias"`
+	InstanceReference ArtifactSourceReference `json:"instanceReference"` // For build artifacts the build ID and number
 }
 
 // ReleaseEnvironment represents an environment/stage in a release
@@ -153,13 +205,119 @@ type ReleaseEnvironment struct {
 	Name        string            `json:"name"`
 	Status      EnvironmentStatus `json:"status"`
 	DeploySteps []DeployStep      `json:"deploySteps"`
+
+	// Approvals of every deploy attempt; only returned when fetching a single release
+	PreDeployApprovals  []ReleaseApproval `json:"preDeployApprovals"`
+	PostDeployApprovals []ReleaseApproval `json:"postDeployApprovals"`
 }
 
 // DeployStep represents a deployment attempt in an environment
 type DeployStep struct {
 	ID              int               `json:"id"`
+	Attempt         int               `json:"attempt"`
 	Status          EnvironmentStatus `json:"status"`
 	OperationStatus string            `json:"operationStatus"`
+	Reason          string            `json:"reason"` // e.g. "automated", "manual", "redeploy"
+	QueuedOn        time.Time         `json:"queuedOn"`
+	LastModifiedOn  time.Time         `json:"lastModifiedOn"`
+	RequestedFor    Identity          `json:"requestedFor"`
+
+	// Only returned when fetching a single release
+	ReleaseDeployPhases []ReleaseDeployPhase `json:"releaseDeployPhases"`
+	PreDeploymentGates  *ReleaseGates        `json:"preDeploymentGates,omitempty"`
+	PostDeploymentGates *ReleaseGates        `json:"postDeploymentGates,omitempty"`
+}
+
+// ApprovalStatus represents the status of a release approval
+type ApprovalStatus string
+
+const (
+	ApprovalStatusPending    ApprovalStatus = "pending"
+	ApprovalStatusApproved   ApprovalStatus = "approved"
+	ApprovalStatusRejected   ApprovalStatus = "rejected"
+	ApprovalStatusReassigned ApprovalStatus = "reassigned"
+	ApprovalStatusCanceled   ApprovalStatus = "canceled"
+	ApprovalStatusSkipped    ApprovalStatus = "skipped"
+	ApprovalStatusUndefined  ApprovalStatus = "undefined"
+)
+
+// ReleaseApproval represents a pre- or post-deployment approval of an environment
+type ReleaseApproval struct {
+	ID           int            `json:"id"`
+	ApprovalType string         `json:"approvalType"` // "preDeploy" or "postDeploy"
+	Status       ApprovalStatus `json:"status"`
+	Attempt      int            `json:"attempt"` // Deploy attempt the approval belongs to
+	IsAutomated  bool           `json:"isAutomated"`
+	Approver     Identity       `json:"approver"`
+	ApprovedBy   *Identity      `json:"approvedBy,omitempty"` // Who acted on the approval, e.g. a member of the approver group
+	Comments     string         `json:"comments"`
+	CreatedOn    time.Time      `json:"createdOn"`
+	ModifiedOn   time.Time      `json:"modifiedOn"`
+}
+
+// ReleaseGates represents the gates evaluated before or after a deployment
+type ReleaseGates struct {
+	ID                       int             `json:"id"`
+	Status                   string          `json:"status"` // "pending", "inProgress", "succeeded", "failed", "canceled" or "none"
+	StartedOn                time.Time       `json:"startedOn"`
+	LastModifiedOn           time.Time       `json:"lastModifiedOn"`
+	StabilizationCompletedOn time.Time       `json:"stabilizationCompletedOn"`
+	SucceedingSince          time.Time       `json:"succeedingSince"`
+	DeploymentJobs           []DeploymentJob `json:"deploymentJobs"` // One task per gate
+}
+
+// ReleaseDeployPhase represents a phase (agent, server or deployment group job) of a deployment
+type ReleaseDeployPhase struct {
+	ID             int             `json:"id"`
+	PhaseID        string          `json:"phaseId"`
+	Name           string          `json:"name"`
+	Rank           int             `json:"rank"`
+	PhaseType      string          `json:"phaseType"`
+	Status         string          `json:"status"` // e.g. "notStarted", "inProgress", "succeeded", "partiallySucceeded", "failed", "canceled", "skipped"
+	StartedOn      time.Time       `json:"startedOn"`
+	ErrorLog       string          `json:"errorLog"`
+	DeploymentJobs []DeploymentJob `json:"deploymentJobs"`
+}
+
+// DeploymentJob represents a job of a deploy phase and its tasks
+type DeploymentJob struct {
+	Job   ReleaseTask   `json:"job"`
+	Tasks []ReleaseTask `json:"tasks"`
+}
+
+// TaskStatus represents the status of a release task
+type TaskStatus string
+
+const (
+	TaskStatusPending            TaskStatus = "pending"
+	TaskStatusInProgress         TaskStatus = "inProgress"
+	TaskStatusSuccess            TaskStatus = "success"
+	TaskStatusSucceeded          TaskStatus = "succeeded"
+	TaskStatusPartiallySucceeded TaskStatus = "partiallySucceeded"
+	TaskStatusFailure            TaskStatus = "failure"
+	TaskStatusFailed             TaskStatus = "failed"
+	TaskStatusCanceled           TaskStatus = "canceled"
+	TaskStatusSkipped            TaskStatus = "skipped"
+	TaskStatusUnknown            TaskStatus = "unknown"
+)
+
+// ReleaseTask represents a task, job or gate of a deployment
+type ReleaseTask struct {
+	ID         int         `json:"id"`
+	Name       string      `json:"name"`
+	Rank       int         `json:"rank"`
+	Status     TaskStatus  `json:"status"`
+	StartTime  time.Time   `json:"startTime"`
+	FinishTime time.Time   `json:"finishTime"`
+	AgentName  string      `json:"agentName"`
+	LogURL     string      `json:"logUrl"`
+	Issues     []TaskIssue `json:"issues"`
+}
+
+// TaskIssue represents an error or warning logged by a task
+type TaskIssue struct {
+	IssueType string `json:"issueType"` // "error" or "warning"
+	Message   string `json:"message"`
 }
 
 // ReleaseLinks contains links related to a release
@@ -175,6 +333,7 @@ type ReleasesResponse struct {
 
 // Identity represents a user identity
 type Identity struct {
+	ID          string `json:"id"`
 	DisplayName string `json:"displayName"`
 	UniqueName  string `json:"uniqueName"`
 }
@@ -199,12 +358,14 @@ const (
 	PullRequestStatusAbandoned PullRequestStatus = "abandoned"
 	PullRequestStatusCompleted PullRequestStatus = "completed"
 	PullRequestStatusNotSet    PullRequestStatus = "notSet"
+	PullRequestStatusAll       PullRequestStatus = "all" // Search criteria only: every status
 )
 
 // PullRequest represents an Azure DevOps pull request
 type PullRequest struct {
 	PullRequestID int                   `json:"pullRequestId"`
 	Title         string                `json:"title"`
+	Description   string                `json:"description"`
 	SourceRefName string                `json:"sourceRefName"`
 	TargetRefName string                `json:"targetRefName"`
 	CreationDate  time.Time             `json:"creationDate"`
@@ -214,14 +375,84 @@ type PullRequest struct {
 	Status        PullRequestStatus     `json:"status"`
 	IsDraft       bool                  `json:"isDraft"`
 	MergeStatus   string                `json:"mergeStatus"`
+	ClosedDate    time.Time             `json:"closedDate"`
+	MergeCommit   *GitCommitRef         `json:"lastMergeCommit,omitempty"`
 	URL           string                `json:"url"`
+	Policies      []PolicyEvaluation    `json:"-"` // Populated separately via policy evaluations API
+	PolicyError   string                `json:"-"` // Set when the policy evaluations could not be fetched
+
+	// Source commit of the last merge attempt; completing requires it to match the reviewed changes
+	LastMergeSourceCommit *GitCommitRef      `json:"lastMergeSourceCommit,omitempty"`
+	AutoCompleteSetBy     *Identity          `json:"autoCompleteSetBy,omitempty"`
+	CompletionOptions     *CompletionOptions `json:"completionOptions,omitempty"`
+}
+
+// GitRepository represents a git repository
+type GitRepository struct {
+	ID            string      `json:"id"`
+	Name          string      `json:"name"`
+	DefaultBranch string      `json:"defaultBranch"` // Full ref, e.g. "refs/heads/main"; empty for an empty repository
+	Project       TeamProject `json:"project"`
+	WebURL        string      `json:"webUrl"`
+}
+
+// GitRef is a branch or tag of a repository
+type GitRef struct {
+	Name     string `json:"name"` // Full ref, e.g. "refs/heads/main"
+	ObjectID string `json:"objectId"`
+}
+
+// GitRefsResponse represents the API response for repository refs
+type GitRefsResponse struct {
+	Count int      `json:"count"`
+	Value []GitRef `json:"value"`
+}
+
+// NewPullRequest holds the fields of a pull request to create
+type NewPullRequest struct {
+	SourceRefName string     `json:"sourceRefName"`
+	TargetRefName string     `json:"targetRefName"`
+	Title         string     `json:"title"`
+	Description   string     `json:"description,omitempty"`
+	IsDraft       bool       `json:"isDraft"`
+	Reviewers     []Reviewer `json:"reviewers,omitempty"` // Only the ID is used
+}
+
+// IdentitiesResponse represents the API response for an identity search
+type IdentitiesResponse struct {
+	Count int                  `json:"count"`
+	Value []ConnectionIdentity `json:"value"`
+}
+
+// MergeStrategy represents how a pull request is merged into its target branch
+type MergeStrategy string
+
+const (
+	MergeStrategyNoFastForward MergeStrategy = "noFastForward" // Merge commit
+	MergeStrategySquash        MergeStrategy = "squash"
+	MergeStrategyRebase        MergeStrategy = "rebase" // Rebase and fast-forward
+)
+
+// CompletionOptions controls how a pull request is completed
+type CompletionOptions struct {
+	MergeStrategy       MergeStrategy `json:"mergeStrategy,omitempty"`
+	DeleteSourceBranch  bool          `json:"deleteSourceBranch"`
+	TransitionWorkItems bool          `json:"transitionWorkItems"`
+	MergeCommitMessage  string        `json:"mergeCommitMessage,omitempty"`
+}
+
+// GitCommitRef references a commit
+type GitCommitRef struct {
+	CommitID string `json:"commitId"`
 }
 
 // Reviewer represents a pull request reviewer
 type Reviewer struct {
+	ID          string `json:"id"`
 	DisplayName string `json:"displayName"`
 	UniqueName  string `json:"uniqueName"`
 	Vote        int    `json:"vote"` // 10=approved, 5=approved with suggestions, 0=no vote, -5=waiting, -10=rejected
+	IsRequired  bool   `json:"isRequired"`
 }
 
 // PullRequestRepository represents the repository for a pull request
@@ -237,3 +468,181 @@ type PullRequestsResponse struct {
 	Count int           `json:"count"`
 	Value []PullRequest `json:"value"`
 }
+
+// ProjectsResponse represents the API response for projects
+type ProjectsResponse struct {
+	Count int           `json:"count"`
+	Value []TeamProject `json:"value"`
+}
+
+// BuildDefinitionsResponse represents the API response for build definitions
+type BuildDefinitionsResponse struct {
+	Count int               `json:"count"`
+	Value []BuildDefinition `json:"value"`
+}
+
+// ReleaseDefinitionsResponse represents the API response for release definitions
+type ReleaseDefinitionsResponse struct {
+	Count int                 `json:"count"`
+	Value []ReleaseDefinition `json:"value"`
+}
+
+// PolicyEvaluationStatus represents the status of a policy evaluation
+type PolicyEvaluationStatus string
+
+const (
+	PolicyEvaluationStatusQueued        PolicyEvaluationStatus = "queued"
+	PolicyEvaluationStatusRunning       PolicyEvaluationStatus = "running"
+	PolicyEvaluationStatusApproved      PolicyEvaluationStatus = "approved"
+	PolicyEvaluationStatusRejected      PolicyEvaluationStatus = "rejected"
+	PolicyEvaluationStatusNotApplicable PolicyEvaluationStatus = "notApplicable"
+	PolicyEvaluationStatusBroken        PolicyEvaluationStatus = "broken"
+)
+
+// PolicyEvaluation represents the evaluation of a branch policy for a pull request
+type PolicyEvaluation struct {
+	EvaluationID  string                 `json:"evaluationId"`
+	Status        PolicyEvaluationStatus `json:"status"`
+	Configuration PolicyConfiguration    `json:"configuration"`
+}
+
+// PolicyConfiguration represents a branch policy
+type PolicyConfiguration struct {
+	ID         int            `json:"id"`
+	IsBlocking bool           `json:"isBlocking"`
+	IsEnabled  bool           `json:"isEnabled"`
+	Type       PolicyType     `json:"type"`
+	Settings   PolicySettings `json:"settings"`
+}
+
+// PolicyType identifies the kind of a policy, e.g. "Build" or "Minimum number of reviewers"
+type PolicyType struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+}
+
+// PolicySettings holds the policy settings used for display
+type PolicySettings struct {
+	DisplayName string `json:"displayName"` // Name of a build validation policy, empty for most other types
+}
+
+// PolicyEvaluationsResponse represents the API response for policy evaluations
+type PolicyEvaluationsResponse struct {
+	Count int                `json:"count"`
+	Value []PolicyEvaluation `json:"value"`
+}
+
+// CommentThreadStatus represents the status of a pull request comment thread
+type CommentThreadStatus string
+
+const (
+	CommentThreadStatusActive   CommentThreadStatus = "active"
+	CommentThreadStatusPending  CommentThreadStatus = "pending"
+	CommentThreadStatusFixed    CommentThreadStatus = "fixed"
+	CommentThreadStatusWontFix  CommentThreadStatus = "wontFix"
+	CommentThreadStatusClosed   CommentThreadStatus = "closed"
+	CommentThreadStatusByDesign CommentThreadStatus = "byDesign"
+	CommentThreadStatusUnknown  CommentThreadStatus = "unknown"
+)
+
+// CommentThread represents a comment thread of a pull request
+type CommentThread struct {
+	ID              int                 `json:"id"`
+	Status          CommentThreadStatus `json:"status,omitempty"` // Empty for system threads such as vote changes
+	ThreadContext   *ThreadContext      `json:"threadContext,omitempty"`
+	Comments        []Comment           `json:"comments"`
+	PublishedDate   time.Time           `json:"publishedDate"`
+	LastUpdatedDate time.Time           `json:"lastUpdatedDate"`
+	IsDeleted       bool                `json:"isDeleted"`
+}
+
+// ThreadContext locates a comment thread in a file, nil for threads on the pull request itself
+type ThreadContext struct {
+	FilePath       string           `json:"filePath"`
+	RightFileStart *CommentPosition `json:"rightFileStart,omitempty"` // Position in the changed file
+	LeftFileStart  *CommentPosition `json:"leftFileStart,omitempty"`  // Position in the original file (deleted lines)
+}
+
+// CommentPosition is a position in a file
+type CommentPosition struct {
+	Line   int `json:"line"`
+	Offset int `json:"offset"`
+}
+
+// CommentType represents the kind of a comment
+type CommentType string
+
+const (
+	CommentTypeText       CommentType = "text"
+	CommentTypeCodeChange CommentType = "codeChange"
+	CommentTypeSystem     CommentType = "system"
+)
+
+// Comment represents a comment in a pull request thread
+type Comment struct {
+	ID              int         `json:"id"`
+	ParentCommentID int         `json:"parentCommentId"`
+	Author          Identity    `json:"author"`
+	Content         string      `json:"content"`
+	CommentType     CommentType `json:"commentType"`
+	PublishedDate   time.Time   `json:"publishedDate"`
+	IsDeleted       bool        `json:"isDeleted"`
+}
+
+// CommentThreadsResponse represents the API response for pull request threads
+type CommentThreadsResponse struct {
+	Count int             `json:"count"`
+	Value []CommentThread `json:"value"`
+}
+
+// PullRequestIteration represents a push to the source branch of a pull request
+type PullRequestIteration struct {
+	ID              int          `json:"id"`
+	Description     string       `json:"description"`
+	Author          Identity     `json:"author"`
+	CreatedDate     time.Time    `json:"createdDate"`
+	SourceRefCommit GitCommitRef `json:"sourceRefCommit"`
+	TargetRefCommit GitCommitRef `json:"targetRefCommit"`
+	CommonRefCommit GitCommitRef `json:"commonRefCommit"` // Merge base the iteration is compared against
+}
+
+// PullRequestIterationsResponse represents the API response for pull request iterations
+type PullRequestIterationsResponse struct {
+	Count int                    `json:"count"`
+	Value []PullRequestIteration `json:"value"`
+}
+
+// VersionControlChangeType represents how a file changed, e.g. "edit" or "edit, rename"
+type VersionControlChangeType string
+
+const (
+	ChangeTypeAdd    VersionControlChangeType = "add"
+	ChangeTypeEdit   VersionControlChangeType = "edit"
+	ChangeTypeDelete VersionControlChangeType = "delete"
+	ChangeTypeRename VersionControlChangeType = "rename"
+)
+
+// PullRequestChange represents a changed file of a pull request iteration
+type PullRequestChange struct {
+	ChangeTrackingID int                      `json:"changeTrackingId"`
+	ChangeID         int                      `json:"changeId"`
+	Item             GitItem                  `json:"item"`
+	ChangeType       VersionControlChangeType `json:"changeType"`
+	OriginalPath     string                   `json:"originalPath,omitempty"` // Path before a rename
+}
+
+// GitItem represents a file or folder in a git repository
+type GitItem struct {
+	ObjectID         string `json:"objectId"`                   // Blob of the new version, empty for deletions
+	OriginalObjectID string `json:"originalObjectId,omitempty"` // Blob of the old version, empty for additions
+	Path             string `json:"path"`
+	GitObjectType    string `json:"gitObjectType"` // "blob" or "tree"
+	IsFolder         bool   `json:"isFolder"`
+}
+
+// PullRequestChangesResponse represents the API response for the changes of an iteration
+type PullRequestChangesResponse struct {
+	ChangeEntries []PullRequestChange `json:"changeEntries"`
+	NextSkip      int                 `json:"nextSkip"` // Where the next page starts, 0 on the last page
+	NextTop       int                 `json:"nextTop"`  // Size of the next page
+}
@@ -0,0 +1,187 @@
+// Package cache persists the last successfully fetched dashboard data so the TUI can start
+// instantly and keep working read-only while Azure DevOps is unreachable.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/api"
+)
+
+// Snapshot holds the cached data of every project, keyed by "organization/project"
+// (see config.ProjectConfig.Key)
+type Snapshot struct {
+	Projects map[string]*ProjectSnapshot `json:"projects"`
+}
+
+// ProjectSnapshot holds the cached data of a single project with the time it was fetched
+type ProjectSnapshot struct {
+	Builds         []Build       `json:"builds,omitempty"`
+	BuildsAt       time.Time     `json:"buildsAt,omitempty"`
+	Releases       []api.Release `json:"releases,omitempty"`
+	ReleasesAt     time.Time     `json:"releasesAt,omitempty"`
+	PullRequests   []PullRequest `json:"pullRequests,omitempty"`
+	PullRequestsAt time.Time     `json:"pullRequestsAt,omitempty"`
+}
+
+// Build is a cached build including its timeline stages, which api.Build does not serialize
+type Build struct {
+	api.Build
+	Stages []api.BuildTimelineRecord `json:"stages,omitempty"`
+}
+
+// PullRequest is a cached pull request including its policy evaluations or the error fetching them,
+// which api.PullRequest does not serialize
+type PullRequest struct {
+	api.PullRequest
+	Policies    []api.PolicyEvaluation `json:"policies,omitempty"`
+	PolicyError string                 `json:"policyError,omitempty"`
+}
+
+// New creates an empty snapshot
+func New() *Snapshot {
+	return &Snapshot{Projects: make(map[string]*ProjectSnapshot)}
+}
+
+// DefaultPath returns the snapshot file for an organization under the user cache directory
+// (e.g. $XDG_CACHE_HOME/azdo-tui on Linux)
+func DefaultPath(organizationURL string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve cache directory: %w", err)
+	}
+
+	sum := sha256.Sum256([]byte(organizationURL))
+	return filepath.Join(dir, "azdo-tui", "snapshot-"+hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// Load reads a snapshot file. A missing file results in an empty snapshot.
+func Load(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return New(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	snapshot := New()
+	if err := json.Unmarshal(data, snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot: %w", err)
+	}
+	if snapshot.Projects == nil {
+		snapshot.Projects = make(map[string]*ProjectSnapshot)
+	}
+	return snapshot, nil
+}
+
+// Encode serializes the snapshot
+func (s *Snapshot) Encode() ([]byte, error) {
+	return json.Marshal(s)
+}
+
+// Write atomically stores encoded snapshot data at path
+func Write(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".snapshot-*")
+	if err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+// project returns the snapshot of a project, creating it if needed
+func (s *Snapshot) project(name string) *ProjectSnapshot {
+	p, ok := s.Projects[name]
+	if !ok {
+		p = &ProjectSnapshot{}
+		s.Projects[name] = p
+	}
+	return p
+}
+
+// Rename moves the cached data of a project from an old key to a new one, for snapshots
+// written before the key format changed. Data already stored under the new key is kept.
+func (s *Snapshot) Rename(oldKey, newKey string) {
+	p, ok := s.Projects[oldKey]
+	if !ok || oldKey == newKey {
+		return
+	}
+	delete(s.Projects, oldKey)
+	if _, exists := s.Projects[newKey]; !exists {
+		s.Projects[newKey] = p
+	}
+}
+
+// SetBuilds stores the builds of a project
+func (s *Snapshot) SetBuilds(project string, builds []api.Build, at time.Time) {
+	cached := make([]Build, len(builds))
+	for i, b := range builds {
+		cached[i] = Build{Build: b, Stages: b.Stages}
+	}
+	p := s.project(project)
+	p.Builds = cached
+	p.BuildsAt = at
+}
+
+// SetReleases stores the releases of a project
+func (s *Snapshot) SetReleases(project string, releases []api.Release, at time.Time) {
+	p := s.project(project)
+	p.Releases = releases
+	p.ReleasesAt = at
+}
+
+// SetPullRequests stores the pull requests of a project
+func (s *Snapshot) SetPullRequests(project string, pullRequests []api.PullRequest, at time.Time) {
+	cached := make([]PullRequest, len(pullRequests))
+	for i, pr := range pullRequests {
+		cached[i] = PullRequest{PullRequest: pr, Policies: pr.Policies, PolicyError: pr.PolicyError}
+	}
+	p := s.project(project)
+	p.PullRequests = cached
+	p.PullRequestsAt = at
+}
+
+// GetBuilds returns the cached builds of a project, with their stages restored
+func (p *ProjectSnapshot) GetBuilds() []api.Build {
+	builds := make([]api.Build, len(p.Builds))
+	for i, b := range p.Builds {
+		builds[i] = b.Build
+		builds[i].Stages = b.Stages
+	}
+	return builds
+}
+
+// GetPullRequests returns the cached pull requests of a project, with their policies restored
+func (p *ProjectSnapshot) GetPullRequests() []api.PullRequest {
+	pullRequests := make([]api.PullRequest, len(p.PullRequests))
+	for i, pr := range p.PullRequests {
+		pullRequests[i] = pr.PullRequest
+		pullRequests[i].Policies = pr.Policies
+		pullRequests[i].PolicyError = pr.PolicyError
+	}
+	return pullRequests
+}
@@ -0,0 +1,183 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/api"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	at := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+	builds := []api.Build{{
+		ID:          1,
+		BuildNumber: "20240131.1",
+		Status:      api.BuildStatusCompleted,
+		Stages:      []api.BuildTimelineRecord{{Name: "Build", State: api.BuildTimelineRecordStateCompleted, Result: api.BuildTimelineRecordResultSucceeded}},
+	}}
+	releases := []api.Release{{ID: 3, Name: "Release-3"}}
+	pullRequests := []api.PullRequest{{
+		PullRequestID: 5,
+		Title:         "Add refunds",
+		Policies:      []api.PolicyEvaluation{{Status: api.PolicyEvaluationStatusApproved}},
+	}}
+
+	snapshot := New()
+	snapshot.SetBuilds("contoso/Payments", builds, at)
+	snapshot.SetReleases("contoso/Payments", releases, at.Add(time.Minute))
+	snapshot.SetPullRequests("contoso/Payments", pullRequests, at.Add(2*time.Minute))
+
+	data, err := snapshot.Encode()
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "nested", "snapshot.json")
+	if err := Write(path, data); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	p, ok := loaded.Projects["contoso/Payments"]
+	if !ok {
+		t.Fatalf("projects = %v, want contoso/Payments", loaded.Projects)
+	}
+
+	if got := p.GetBuilds(); !reflect.DeepEqual(got, builds) {
+		t.Errorf("builds = %+v, want %+v", got, builds)
+	}
+	if !reflect.DeepEqual(p.Releases, releases) {
+		t.Errorf("releases = %+v, want %+v", p.Releases, releases)
+	}
+	if got := p.GetPullRequests(); !reflect.DeepEqual(got, pullRequests) {
+		t.Errorf("pull requests = %+v, want %+v", got, pullRequests)
+	}
+	if !p.BuildsAt.Equal(at) || !p.ReleasesAt.Equal(at.Add(time.Minute)) || !p.PullRequestsAt.Equal(at.Add(2*time.Minute)) {
+		t.Errorf("fetch times = %v, %v, %v", p.BuildsAt, p.ReleasesAt, p.PullRequestsAt)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		contents *string // nil leaves the file missing
+		wantErr  string
+	}{
+		{name: "missing file"},
+		{name: "empty object", contents: ptr(`{}`)},
+		{name: "null projects", contents: ptr(`{"projects":null}`)},
+		{name: "corrupt file", contents: ptr(`{"projects":{"Payments":`), wantErr: "failed to parse snapshot"},
+		{name: "not json", contents: ptr("\x00\x01"), wantErr: "failed to parse snapshot"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "-")+".json")
+			if tt.contents != nil {
+				if err := os.WriteFile(path, []byte(*tt.contents), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			snapshot, err := Load(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load(): %v", err)
+			}
+			if snapshot.Projects == nil || len(snapshot.Projects) != 0 {
+				t.Errorf("projects = %v, want an empty map", snapshot.Projects)
+			}
+		})
+	}
+}
+
+func TestSnapshotProjectIsolation(t *testing.T) {
+	at := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+
+	snapshot := New()
+	snapshot.SetBuilds("contoso/Payments", []api.Build{{ID: 1}}, at)
+	snapshot.SetBuilds("fabrikam/Payments", []api.Build{{ID: 2}}, at)
+	snapshot.SetReleases("contoso/Payments", []api.Release{{ID: 3}}, at)
+	snapshot.SetBuilds("contoso/Payments", []api.Build{{ID: 4}}, at.Add(time.Minute))
+
+	contoso := snapshot.Projects["contoso/Payments"]
+	fabrikam := snapshot.Projects["fabrikam/Payments"]
+	if len(snapshot.Projects) != 2 || contoso == nil || fabrikam == nil {
+		t.Fatalf("projects = %v, want contoso/Payments and fabrikam/Payments", snapshot.Projects)
+	}
+
+	if got := contoso.GetBuilds(); len(got) != 1 || got[0].ID != 4 {
+		t.Errorf("contoso builds = %+v, want build 4", got)
+	}
+	if len(contoso.Releases) != 1 || contoso.Releases[0].ID != 3 {
+		t.Errorf("contoso releases = %+v, want release 3 kept after updating builds", contoso.Releases)
+	}
+	if got := fabrikam.GetBuilds(); len(got) != 1 || got[0].ID != 2 {
+		t.Errorf("fabrikam builds = %+v, want build 2", got)
+	}
+	if len(fabrikam.Releases) != 0 || !fabrikam.BuildsAt.Equal(at) {
+		t.Errorf("fabrikam = %+v, want only its own builds", fabrikam)
+	}
+}
+
+func TestDefaultPath(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	a, err := DefaultPath("https://dev.azure.com/contoso")
+	if err != nil {
+		t.Skipf("no user cache directory: %v", err)
+	}
+	b, _ := DefaultPath("https://dev.azure.com/fabrikam")
+	again, _ := DefaultPath("https://dev.azure.com/contoso")
+
+	if a == b {
+		t.Errorf("organizations share the snapshot file %s", a)
+	}
+	if a != again {
+		t.Errorf("DefaultPath is not stable: %s != %s", a, again)
+	}
+	if filepath.Base(filepath.Dir(a)) != "azdo-tui" {
+		t.Errorf("DefaultPath() = %s, want a file in the azdo-tui cache directory", a)
+	}
+}
+
+func ptr(s string) *string {
+	return &s
+}
+
+func TestSnapshotRename(t *testing.T) {
+	at := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+
+	snapshot := New()
+	snapshot.SetBuilds("Payments", []api.Build{{ID: 1}}, at)
+	snapshot.SetBuilds("Orders", []api.Build{{ID: 2}}, at)
+	snapshot.SetBuilds("contoso/Orders", []api.Build{{ID: 3}}, at)
+
+	snapshot.Rename("Payments", "contoso/Payments")
+	snapshot.Rename("Orders", "contoso/Orders")
+	snapshot.Rename("Missing", "contoso/Missing")
+
+	if len(snapshot.Projects) != 2 {
+		t.Fatalf("projects = %v, want contoso/Payments and contoso/Orders", snapshot.Projects)
+	}
+	if got := snapshot.Projects["contoso/Payments"].GetBuilds(); len(got) != 1 || got[0].ID != 1 {
+		t.Errorf("contoso/Payments builds = %+v, want the builds moved from Payments", got)
+	}
+	if got := snapshot.Projects["contoso/Orders"].GetBuilds(); len(got) != 1 || got[0].ID != 3 {
+		t.Errorf("contoso/Orders builds = %+v, want the newer data kept", got)
+	}
+}
@@ -12,33 +12,104 @@ import (
 
 // Config represents the application configuration
 type Config struct {
-	AzureDevOps  AzureDevOpsConfig `yaml:"azure_devops"`
-	Projects     []ProjectConfig   `yaml:"projects"`
-	Display      DisplayConfig     `yaml:"display"`
-	RateLimiting RateLimitConfig   `yaml:"rate_limiting"`
+	AzureDevOps   AzureDevOpsConfig    `yaml:"azure_devops"`
+	Organizations []OrganizationConfig `yaml:"organizations"` // Multiple organizations, replaces azure_devops and projects
+	ProjectSource ProjectsSetting      `yaml:"projects"`      // Projects of the single azure_devops organization
+	Projects      []ProjectConfig      `yaml:"-"`             // The projects of every organization, set by Load
+	Display       DisplayConfig        `yaml:"display"`
+	RateLimiting  RateLimitConfig      `yaml:"rate_limiting"`
+	HTTP          HTTPConfig           `yaml:"http"`
+	Cache         CacheConfig          `yaml:"cache"`
+	Webhook       WebhookConfig        `yaml:"webhook"`
+	PullRequests  PullRequestsConfig   `yaml:"pull_requests"`
 }
 
+// OrganizationConfig holds the connection settings, credentials and projects of one organization
+type OrganizationConfig struct {
+	Name              string `yaml:"name"` // Unique name used as prefix (default: organization)
+	AzureDevOpsConfig `yaml:",inline"`
+	ProjectSource     ProjectsSetting `yaml:"projects"`
+	Projects          []ProjectConfig `yaml:"-"` // Configured or discovered projects, set by Load and discovery
+}
+
+// DiscoversProjects returns true if the projects of the organization are discovered through the API
+func (o OrganizationConfig) DiscoversProjects() bool {
+	return o.ProjectSource.Discovery != nil
+}
+
+// Azure DevOps deployment modes
+const (
+	ModeCloud  = "cloud"  // Azure DevOps Services (dev.azure.com)
+	ModeServer = "server" // Azure DevOps Server / TFS (on-premises)
+)
+
 // AzureDevOpsConfig holds Azure DevOps connection settings
 type AzureDevOpsConfig struct {
-	Organization string `yaml:"organization"`
-	BaseURL      string `yaml:"base_url"`
-	PAT          string `yaml:"pat"`
+	Mode           string        `yaml:"mode"` // "cloud" (default) or "server"
+	Organization   string        `yaml:"organization"`
+	BaseURL        string        `yaml:"base_url"`
+	CollectionURL  string        `yaml:"collection_url"`   // Server mode: e.g. https://tfs.example.com/tfs/DefaultCollection
+	ReleaseBaseURL string        `yaml:"release_base_url"` // Optional: override the release management URL
+	APIVersion     string        `yaml:"api_version"`      // Optional: pin the REST API version (negotiated by default)
+	PAT            string        `yaml:"pat"`
+	PATCommand     string        `yaml:"pat_command"` // Command printing the PAT, e.g. "pass show azdo"
+	PATFile        string        `yaml:"pat_file"`    // File containing the PAT
+	PATKeyring     KeyringConfig `yaml:"pat_keyring"` // PAT stored in the OS keyring
+	Auth           AuthConfig    `yaml:"auth"`
+}
+
+// Authentication types
+const (
+	AuthTypePAT     = "pat"     // Personal Access Token (Basic auth)
+	AuthTypeBearer  = "bearer"  // Static OAuth / Entra ID access token
+	AuthTypeCommand = "command" // Access token printed by a command, refreshed before expiry
+)
+
+// AuthConfig holds authentication settings
+type AuthConfig struct {
+	Type    string `yaml:"type"`    // "pat" (default), "bearer" or "command"
+	Token   string `yaml:"token"`   // Bearer: the access token
+	Command string `yaml:"command"` // Command: prints a token (default: az account get-access-token)
+}
+
+// IsServer returns true if the configuration targets Azure DevOps Server
+func (c AzureDevOpsConfig) IsServer() bool {
+	return c.Mode == ModeServer
 }
 
 // ProjectConfig holds project-specific settings
 type ProjectConfig struct {
-	Name               string   `yaml:"name"`
-	BuildDefinitions   []int    `yaml:"build_definitions"`
-	ReleaseDefinitions []int    `yaml:"release_definitions"`
-	Branches           []string `yaml:"branches"`     // Filter builds by branch names (e.g., "main", "develop")
-	Repositories       []string `yaml:"repositories"` // Filter PRs by repository names
+	Organization       string              `yaml:"-"` // Name of the organization the project belongs to, set by Load
+	Name               string              `yaml:"name"`
+	BuildSelectors     DefinitionSelectors `yaml:"build_definitions"`   // Build definition IDs, names, globs or folders
+	ReleaseSelectors   DefinitionSelectors `yaml:"release_definitions"` // Release definition IDs, names, globs or folders
+	BuildDefinitions   []int               `yaml:"-"`                   // Resolved build definition IDs
+	ReleaseDefinitions []int               `yaml:"-"`                   // Resolved release definition IDs
+	Branches           []string            `yaml:"branches"`            // Filter builds by branch patterns (e.g., "main", "release/*", "!dependabot/*")
+	TargetBranches     []string            `yaml:"target_branches"`     // Filter PRs by target branch patterns
+	Repositories       []string            `yaml:"repositories"`        // Filter PRs by repository names
+}
+
+// HasDefinitionPatterns returns true if build or release definitions are selected by
+// name, glob or folder and have to be resolved through the API
+func (p ProjectConfig) HasDefinitionPatterns() bool {
+	return p.BuildSelectors.HasPatterns() || p.ReleaseSelectors.HasPatterns()
+}
+
+// Key returns the identifier of the project across organizations
+func (p ProjectConfig) Key() string {
+	if p.Organization == "" {
+		return p.Name
+	}
+	return p.Organization + "/" + p.Name
 }
 
 // DisplayConfig holds display settings
 type DisplayConfig struct {
-	RefreshInterval    time.Duration `yaml:"refresh_interval"`
-	MaxItemsPerProject int           `yaml:"max_items_per_project"`
-	DateFormat         string        `yaml:"date_format"`
+	RefreshInterval           time.Duration `yaml:"refresh_interval"`
+	MaxItemsPerProject        int           `yaml:"max_items_per_project"`
+	DateFormat                string        `yaml:"date_format"`
+	DefinitionRefreshInterval time.Duration `yaml:"definition_refresh_interval"` // How often definition names and globs are resolved again (default: 15m)
 }
 
 // RateLimitConfig holds rate limiting settings
@@ -47,8 +118,67 @@ type RateLimitConfig struct {
 	BurstSize         int     `yaml:"burst_size"`
 }
 
+// HTTPConfig holds network settings for connecting to Azure DevOps
+type HTTPConfig struct {
+	Timeout       time.Duration `yaml:"timeout"`         // Request timeout (default: 30s)
+	Proxy         string        `yaml:"proxy"`           // HTTP proxy URL (default: HTTP_PROXY / HTTPS_PROXY environment)
+	CABundle      string        `yaml:"ca_bundle"`       // PEM file with additional trusted root CAs
+	ClientCert    string        `yaml:"client_cert"`     // PEM client certificate for mutual TLS
+	ClientKey     string        `yaml:"client_key"`      // PEM private key for the client certificate
+	TLSMinVersion string        `yaml:"tls_min_version"` // "1.0", "1.1", "1.2" or "1.3" (default: 1.2)
+}
+
+// CacheConfig holds settings for the on-disk snapshot of the last fetched data
+type CacheConfig struct {
+	Enabled *bool  `yaml:"enabled"` // Default: true
+	Path    string `yaml:"path"`    // Default: snapshot file under the user cache directory
+}
+
+// IsEnabled returns true if the snapshot cache is enabled
+func (c CacheConfig) IsEnabled() bool {
+	return c.Enabled != nil && *c.Enabled
+}
+
+// PullRequestsConfig holds the pull request filters applied at startup; each can be toggled in the dashboard
+type PullRequestsConfig struct {
+	Status           string `yaml:"status"`             // "active" (default), "completed", "abandoned" or "all"
+	CreatedByMe      bool   `yaml:"created_by_me"`      // Only pull requests created by the signed-in user
+	AwaitingMyReview bool   `yaml:"awaiting_my_review"` // Only pull requests with the signed-in user as a reviewer
+	HideDrafts       bool   `yaml:"hide_drafts"`        // Leave out draft pull requests
+}
+
+// WebhookConfig holds settings for the embedded Azure DevOps service hook listener
+type WebhookConfig struct {
+	Enabled          bool          `yaml:"enabled"`
+	Listen           string        `yaml:"listen"`            // Listen address (default: ":8787")
+	Secret           string        `yaml:"secret"`            // Shared secret sent in the X-Azdo-Tui-Secret header
+	Username         string        `yaml:"username"`          // Basic auth user name
+	Password         string        `yaml:"password"`          // Basic auth password
+	FallbackInterval time.Duration `yaml:"fallback_interval"` // Polling interval while the listener is enabled (default: 10m)
+}
+
+// RefreshInterval returns the polling interval, which is a slow fallback while the
+// service hook listener is enabled
+func (c *Config) RefreshInterval() time.Duration {
+	if c.Webhook.Enabled {
+		return c.Webhook.FallbackInterval
+	}
+	return c.Display.RefreshInterval
+}
+
 // Load reads and parses the configuration from the given file path
 func Load(path string) (*Config, error) {
+	return load(path, true)
+}
+
+// LoadForReplay reads the configuration like Load, but neither resolves nor requires the
+// credentials, since recorded responses are replayed without authentication
+func LoadForReplay(path string) (*Config, error) {
+	return load(path, false)
+}
+
+// load reads, parses and prepares the configuration, resolving the credentials if requested
+func load(path string, credentials bool) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
@@ -62,36 +192,143 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
-	// Apply defaults
-	applyDefaults(&cfg)
+	if err := prepare(&cfg, credentials); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
 
-	// Validate configuration
-	if err := Validate(&cfg); err != nil {
-		return nil, fmt.Errorf("invalid configuration: %w", err)
+// ForProject builds the configuration used without a config file: a single organization
+// and project with the default settings. Unless the connection sets credentials, the PAT is
+// read from the AZURE_DEVOPS_PAT environment variable or, on Azure DevOps Services, an Entra
+// ID token is fetched from the Azure CLI.
+func ForProject(ado AzureDevOpsConfig, project string) (*Config, error) {
+	if ado.PAT == "" && ado.Auth.Type == "" {
+		ado.PAT = os.Getenv("AZURE_DEVOPS_PAT")
+		if ado.PAT == "" && !ado.IsServer() {
+			ado.Auth.Type = AuthTypeCommand
+		}
 	}
 
+	cfg := Config{
+		AzureDevOps:   ado,
+		ProjectSource: ProjectsSetting{List: []ProjectConfig{{Name: project}}},
+	}
+	if err := prepare(&cfg, true); err != nil {
+		return nil, err
+	}
 	return &cfg, nil
 }
 
-// expandEnvVars replaces ${VAR} or $VAR patterns with environment variable values
+// prepare collects the organizations and projects, applies defaults, resolves the PATs
+// and validates the configuration. Without credentials, the PATs are neither resolved nor required.
+func prepare(cfg *Config, credentials bool) error {
+	// Collect the organizations and their projects
+	if err := normalizeOrganizations(cfg); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	// Apply defaults
+	applyDefaults(cfg)
+
+	// Resolve the PATs from a credential command, file or keyring. Other authentication
+	// types never use the PAT, so its sources are left alone.
+	for i := range cfg.Organizations {
+		org := &cfg.Organizations[i]
+		if !credentials || org.Auth.Type != AuthTypePAT {
+			continue
+		}
+		if err := resolvePAT(&org.AzureDevOpsConfig); err != nil {
+			if len(cfg.Organizations) > 1 {
+				return fmt.Errorf("failed to resolve PAT for organization %q: %w", org.Name, err)
+			}
+			return fmt.Errorf("failed to resolve PAT: %w", err)
+		}
+	}
+
+	// Validate configuration
+	if err := validate(cfg, credentials); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	return nil
+}
+
+// expandEnvVars replaces ${VAR} patterns with environment variable values. Unset variables
+// expand to the empty string, so e.g. pat: "${AZURE_DEVOPS_PAT}" counts as not set.
 func expandEnvVars(s string) string {
 	// Match ${VAR} pattern
 	re := regexp.MustCompile(`\$\{([^}]+)\}`)
 	result := re.ReplaceAllStringFunc(s, func(match string) string {
 		varName := strings.TrimPrefix(strings.TrimSuffix(match, "}"), "${")
-		if val := os.Getenv(varName); val != "" {
-			return val
-		}
-		return match
+		return os.Getenv(varName)
 	})
 
 	return result
 }
 
+// normalizeOrganizations turns the single azure_devops/projects configuration into an
+// organization, names every organization and collects all projects in cfg.Projects
+func normalizeOrganizations(cfg *Config) error {
+	if len(cfg.Organizations) == 0 {
+		cfg.Organizations = []OrganizationConfig{{
+			AzureDevOpsConfig: cfg.AzureDevOps,
+			ProjectSource:     cfg.ProjectSource,
+		}}
+	} else if !cfg.ProjectSource.IsZero() || cfg.AzureDevOps != (AzureDevOpsConfig{}) {
+		return fmt.Errorf("organizations cannot be combined with azure_devops and projects")
+	}
+
+	for i := range cfg.Organizations {
+		org := &cfg.Organizations[i]
+		if org.Name == "" {
+			org.Name = defaultOrganizationName(org.AzureDevOpsConfig)
+		}
+		org.Projects = append([]ProjectConfig(nil), org.ProjectSource.List...)
+		for j := range org.Projects {
+			p := &org.Projects[j]
+			p.Organization = org.Name
+			// Numeric IDs need no lookup; patterns are resolved by the discovery package
+			p.BuildDefinitions, _ = p.BuildSelectors.IDs()
+			p.ReleaseDefinitions, _ = p.ReleaseSelectors.IDs()
+		}
+	}
+	cfg.CollectProjects()
+	return nil
+}
+
+// CollectProjects rebuilds Projects from the projects of every organization
+func (c *Config) CollectProjects() {
+	c.Projects = nil
+	for _, org := range c.Organizations {
+		c.Projects = append(c.Projects, org.Projects...)
+	}
+}
+
+// defaultOrganizationName returns the organization, or the collection name in server mode
+func defaultOrganizationName(c AzureDevOpsConfig) string {
+	if c.Organization != "" {
+		return c.Organization
+	}
+	collection := strings.TrimRight(c.CollectionURL, "/")
+	return collection[strings.LastIndex(collection, "/")+1:]
+}
+
 // applyDefaults sets default values for unspecified configuration options
 func applyDefaults(cfg *Config) {
-	if cfg.AzureDevOps.BaseURL == "" {
-		cfg.AzureDevOps.BaseURL = "https://dev.azure.com"
+	for i := range cfg.Organizations {
+		org := &cfg.Organizations[i]
+
+		if org.Mode == "" {
+			org.Mode = ModeCloud
+		}
+
+		if org.Auth.Type == "" {
+			org.Auth.Type = AuthTypePAT
+		}
+
+		if org.BaseURL == "" {
+			org.BaseURL = "https://dev.azure.com"
+		}
 	}
 
 	if cfg.Display.RefreshInterval == 0 {
@@ -102,6 +339,14 @@ func applyDefaults(cfg *Config) {
 		cfg.Display.MaxItemsPerProject = 10
 	}
 
+	if cfg.PullRequests.Status == "" {
+		cfg.PullRequests.Status = "active"
+	}
+
+	if cfg.Display.DefinitionRefreshInterval == 0 {
+		cfg.Display.DefinitionRefreshInterval = 15 * time.Minute
+	}
+
 	if cfg.Display.DateFormat == "" {
 		cfg.Display.DateFormat = "2006-01-02 15:04:05"
 	}
@@ -113,4 +358,21 @@ func applyDefaults(cfg *Config) {
 	if cfg.RateLimiting.BurstSize == 0 {
 		cfg.RateLimiting.BurstSize = 10
 	}
+
+	if cfg.HTTP.Timeout == 0 {
+		cfg.HTTP.Timeout = 30 * time.Second
+	}
+
+	if cfg.Webhook.Listen == "" {
+		cfg.Webhook.Listen = ":8787"
+	}
+
+	if cfg.Webhook.FallbackInterval == 0 {
+		cfg.Webhook.FallbackInterval = 10 * time.Minute
+	}
+
+	if cfg.Cache.Enabled == nil {
+		enabled := true
+		cfg.Cache.Enabled = &enabled
+	}
 }
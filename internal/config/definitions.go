@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefinitionSelector selects build or release definitions by ID, name, glob or folder path.
+// Patterns starting with a backslash match the folder path and name, e.g. `\Services\Payments\*`
+// for the definitions in a folder or `\Services\**` to include subfolders. Other patterns are
+// matched against the definition name. Matching is case-insensitive.
+type DefinitionSelector struct {
+	ID      int
+	Pattern string
+}
+
+// DefinitionSelectors is a list of definition selectors
+type DefinitionSelectors []DefinitionSelector
+
+// UnmarshalYAML accepts a numeric ID or a name, glob or folder pattern. Only unquoted
+// numbers are IDs, so a pipeline named "2024" is selected by quoting its name.
+func (s *DefinitionSelector) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d: definition must be an ID, a name, a glob or a folder path", node.Line)
+	}
+	if node.ShortTag() == "!!int" {
+		return node.Decode(&s.ID)
+	}
+	s.Pattern = node.Value
+	return nil
+}
+
+// String returns the ID or pattern
+func (s DefinitionSelector) String() string {
+	if s.Pattern != "" {
+		return s.Pattern
+	}
+	return strconv.Itoa(s.ID)
+}
+
+// Matches returns true if the definition with the given ID, name and folder path is selected
+func (s DefinitionSelector) Matches(id int, name, folder string) bool {
+	if s.Pattern == "" {
+		return s.ID == id
+	}
+
+	pattern := strings.ToLower(s.Pattern)
+	name = strings.ToLower(name)
+	if !strings.HasPrefix(pattern, `\`) {
+		ok, _ := path.Match(pattern, name)
+		return ok
+	}
+
+	// Compare folder paths with forward slashes so path.Match treats them as separators
+	pattern = strings.ReplaceAll(pattern, `\`, "/")
+	full := strings.TrimSuffix(strings.ReplaceAll(strings.ToLower(folder), `\`, "/"), "/") + "/" + name
+	if !strings.HasPrefix(full, "/") {
+		full = "/" + full
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "/**"); ok {
+		return strings.HasPrefix(full, prefix+"/")
+	}
+	ok, _ := path.Match(pattern, full)
+	return ok
+}
+
+// IDs returns the selected IDs if every selector is a numeric ID.
+// ok is false if some selectors are patterns that have to be resolved through the API.
+func (s DefinitionSelectors) IDs() (ids []int, ok bool) {
+	for _, selector := range s {
+		if selector.Pattern != "" {
+			return nil, false
+		}
+		ids = append(ids, selector.ID)
+	}
+	return ids, true
+}
+
+// HasPatterns returns true if some selectors have to be resolved through the API
+func (s DefinitionSelectors) HasPatterns() bool {
+	_, ok := s.IDs()
+	return !ok
+}
+
+// validDefinitionPatterns returns an error message for every malformed pattern
+func validDefinitionPatterns(field string, selectors DefinitionSelectors) []string {
+	var errs []string
+	for _, s := range selectors {
+		if s.Pattern == "" {
+			continue
+		}
+		pattern := strings.TrimSuffix(strings.ReplaceAll(s.Pattern, `\`, "/"), "/**")
+		if _, err := path.Match(pattern, ""); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: invalid pattern %q", field, s.Pattern))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestDefinitionSelectorsUnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want DefinitionSelectors
+	}{
+		{"IDs", "[1, 5, 12]", DefinitionSelectors{{ID: 1}, {ID: 5}, {ID: 12}}},
+		{"names and globs", `["payments-*", '\Services\**']`, DefinitionSelectors{{Pattern: "payments-*"}, {Pattern: `\Services\**`}}},
+		{"quoted number is a name", `["2024", '42']`, DefinitionSelectors{{Pattern: "2024"}, {Pattern: "42"}}},
+		{"mixed", "- 7\n- \"7\"\n- nightly\n", DefinitionSelectors{{ID: 7}, {Pattern: "7"}, {Pattern: "nightly"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got DefinitionSelectors
+			if err := yaml.Unmarshal([]byte(tt.yaml), &got); err != nil {
+				t.Fatalf("Unmarshal(): %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectors = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	var invalid DefinitionSelectors
+	if err := yaml.Unmarshal([]byte("- {id: 1}"), &invalid); err == nil {
+		t.Error("mapping selector accepted, want an error")
+	}
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectsSetting is the projects setting: a list of projects, "auto" to discover every
+// project of the organization, or a mapping with auto: true and include/exclude globs
+type ProjectsSetting struct {
+	List      []ProjectConfig
+	Discovery *DiscoveryConfig // Set when projects are discovered through the API
+}
+
+// DiscoveryConfig selects discovered projects by name
+type DiscoveryConfig struct {
+	Include []string `yaml:"include"` // Globs of project names to show (default: all)
+	Exclude []string `yaml:"exclude"` // Globs of project names to hide
+}
+
+// UnmarshalYAML accepts a list of projects, "auto" or an auto-discovery mapping
+func (p *ProjectsSetting) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Tag == "!!null" {
+			return nil
+		}
+		if !strings.EqualFold(node.Value, "auto") {
+			return fmt.Errorf("line %d: projects must be a list or \"auto\"", node.Line)
+		}
+		p.Discovery = &DiscoveryConfig{}
+		return nil
+
+	case yaml.MappingNode:
+		var discovery struct {
+			Auto            bool `yaml:"auto"`
+			DiscoveryConfig `yaml:",inline"`
+		}
+		if err := node.Decode(&discovery); err != nil {
+			return err
+		}
+		if !discovery.Auto {
+			return fmt.Errorf("line %d: projects.auto must be true when include/exclude are used", node.Line)
+		}
+		p.Discovery = &discovery.DiscoveryConfig
+		return nil
+
+	default:
+		return node.Decode(&p.List)
+	}
+}
+
+// IsZero reports whether the setting is empty
+func (p ProjectsSetting) IsZero() bool {
+	return len(p.List) == 0 && p.Discovery == nil
+}
+
+// Matches returns true if a discovered project with the given name should be shown.
+// Globs use path.Match syntax and are case-insensitive.
+func (d DiscoveryConfig) Matches(name string) bool {
+	name = strings.ToLower(name)
+
+	if len(d.Include) > 0 && !matchAny(d.Include, name) {
+		return false
+	}
+	return !matchAny(d.Exclude, name)
+}
+
+// matchAny returns true if name matches one of the globs
+func matchAny(globs []string, name string) bool {
+	for _, glob := range globs {
+		if ok, _ := path.Match(strings.ToLower(glob), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// validGlobs returns an error message for every malformed glob
+func validGlobs(field string, globs []string) []string {
+	var errs []string
+	for _, glob := range globs {
+		if _, err := path.Match(glob, ""); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: invalid pattern %q", field, glob))
+		}
+	}
+	return errs
+}
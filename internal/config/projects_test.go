@@ -0,0 +1,75 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestProjectsSettingUnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    ProjectsSetting
+		wantErr string
+	}{
+		{
+			name: "list",
+			yaml: "projects:\n  - name: Payments\n  - name: Web\n",
+			want: ProjectsSetting{List: []ProjectConfig{{Name: "Payments"}, {Name: "Web"}}},
+		},
+		{
+			name: "auto",
+			yaml: "projects: auto\n",
+			want: ProjectsSetting{Discovery: &DiscoveryConfig{}},
+		},
+		{
+			name: "auto is case-insensitive",
+			yaml: "projects: Auto\n",
+			want: ProjectsSetting{Discovery: &DiscoveryConfig{}},
+		},
+		{
+			name: "discovery mapping",
+			yaml: "projects:\n  auto: true\n  include: [\"pay*\"]\n  exclude: [\"*-archive\"]\n",
+			want: ProjectsSetting{Discovery: &DiscoveryConfig{Include: []string{"pay*"}, Exclude: []string{"*-archive"}}},
+		},
+		{
+			name: "null",
+			yaml: "projects:\n",
+			want: ProjectsSetting{},
+		},
+		{
+			name:    "other scalar",
+			yaml:    "projects: all\n",
+			wantErr: `line 1: projects must be a list or "auto"`,
+		},
+		{
+			name:    "mapping without auto",
+			yaml:    "projects:\n  include: [\"pay*\"]\n",
+			wantErr: "line 2: projects.auto must be true",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg struct {
+				Projects ProjectsSetting `yaml:"projects"`
+			}
+			err := yaml.Unmarshal([]byte(tt.yaml), &cfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Unmarshal() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal(): %v", err)
+			}
+			if !reflect.DeepEqual(cfg.Projects, tt.want) {
+				t.Errorf("projects = %+v, want %+v", cfg.Projects, tt.want)
+			}
+		})
+	}
+}
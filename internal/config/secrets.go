@@ -0,0 +1,145 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/shell"
+)
+
+// secretCommandTimeout limits how long a credential command may run
+const secretCommandTimeout = 30 * time.Second
+
+// KeyringConfig identifies a secret stored in the OS keyring
+type KeyringConfig struct {
+	Service string `yaml:"service"`
+	Account string `yaml:"account"`
+}
+
+// resolvePAT fills in AzureDevOps.PAT from pat_command, pat_file or pat_keyring.
+// Errors never include the secret itself or the output of the credential command.
+func resolvePAT(cfg *AzureDevOpsConfig) error {
+	sources := 0
+	for _, set := range []bool{
+		cfg.PAT != "",
+		cfg.PATCommand != "",
+		cfg.PATFile != "",
+		cfg.PATKeyring.Service != "",
+	} {
+		if set {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return errors.New("only one of azure_devops.pat, pat_command, pat_file and pat_keyring may be set")
+	}
+
+	var (
+		pat string
+		err error
+	)
+	switch {
+	case cfg.PATCommand != "":
+		pat, err = readSecretFromCommand(cfg.PATCommand)
+		if err != nil {
+			return fmt.Errorf("azure_devops.pat_command: %w", err)
+		}
+	case cfg.PATFile != "":
+		pat, err = readSecretFromFile(cfg.PATFile)
+		if err != nil {
+			return fmt.Errorf("azure_devops.pat_file: %w", err)
+		}
+	case cfg.PATKeyring.Service != "":
+		pat, err = readSecretFromKeyring(cfg.PATKeyring)
+		if err != nil {
+			return fmt.Errorf("azure_devops.pat_keyring: %w", err)
+		}
+	default:
+		return nil
+	}
+
+	if pat == "" {
+		return errors.New("azure_devops PAT source returned an empty value")
+	}
+	cfg.PAT = pat
+	return nil
+}
+
+// readSecretFromCommand runs a command through the shell and returns its trimmed stdout
+func readSecretFromCommand(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), secretCommandTimeout)
+	defer cancel()
+
+	return runSecretCommand(shell.Command(ctx, command))
+}
+
+// readSecretFromFile reads a secret from a file, supporting a leading ~/
+func readSecretFromFile(path string) (string, error) {
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve home directory: %w", err)
+		}
+		path = filepath.Join(home, path[2:])
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
+
+// readSecretFromKeyring looks up a secret in the OS keyring.
+// Linux uses the Secret Service through secret-tool, macOS uses the login keychain.
+func readSecretFromKeyring(kr KeyringConfig) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), secretCommandTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "linux", "freebsd", "openbsd":
+		args := []string{"lookup", "service", kr.Service}
+		if kr.Account != "" {
+			args = append(args, "account", kr.Account)
+		}
+		cmd = exec.CommandContext(ctx, "secret-tool", args...)
+	case "darwin":
+		args := []string{"find-generic-password", "-s", kr.Service, "-w"}
+		if kr.Account != "" {
+			args = append(args, "-a", kr.Account)
+		}
+		cmd = exec.CommandContext(ctx, "security", args...)
+	default:
+		return "", fmt.Errorf("keyring is not supported on %s", runtime.GOOS)
+	}
+
+	return runSecretCommand(cmd)
+}
+
+// runSecretCommand runs a credential command and returns its trimmed stdout.
+// Output is deliberately left out of errors so secrets cannot leak into logs or the UI.
+func runSecretCommand(cmd *exec.Cmd) (string, error) {
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = nil
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", fmt.Errorf("command exited with code %d", exitErr.ExitCode())
+		}
+		return "", fmt.Errorf("failed to run command: %w", err)
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadPATSources(t *testing.T) {
+	dir := t.TempDir()
+	patFile := filepath.Join(dir, "pat")
+	if err := os.WriteFile(patFile, []byte("file-pat\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		source  string // Lines added to the azure_devops section
+		wantPAT string
+		wantErr string
+	}{
+		{
+			name:    "literal pat",
+			source:  `pat: "literal-pat"`,
+			wantPAT: "literal-pat",
+		},
+		{
+			name:    "pat from environment",
+			env:     map[string]string{"TEST_AZDO_PAT": "env-pat"},
+			source:  `pat: "${TEST_AZDO_PAT}"`,
+			wantPAT: "env-pat",
+		},
+		{
+			name:    "pat_command",
+			source:  `pat_command: "echo command-pat"`,
+			wantPAT: "command-pat",
+		},
+		{
+			name:    "pat_file",
+			source:  "pat_file: " + patFile,
+			wantPAT: "file-pat",
+		},
+		{
+			name:    "unset environment pat falls back to pat_command",
+			source:  "pat: \"${TEST_AZDO_PAT}\"\n  pat_command: \"echo command-pat\"",
+			wantPAT: "command-pat",
+		},
+		{
+			name:    "environment pat and pat_command",
+			env:     map[string]string{"TEST_AZDO_PAT": "env-pat"},
+			source:  "pat: \"${TEST_AZDO_PAT}\"\n  pat_command: \"echo command-pat\"",
+			wantErr: "only one of",
+		},
+		{
+			name:    "pat and pat_file",
+			source:  "pat: \"literal-pat\"\n  pat_file: " + patFile,
+			wantErr: "only one of",
+		},
+		{
+			name:    "failing pat_command",
+			source:  `pat_command: "echo leaked-secret && exit 3"`,
+			wantErr: "command exited with code 3",
+		},
+		{
+			name:    "empty pat_command output",
+			source:  `pat_command: "echo"`,
+			wantErr: "returned an empty value",
+		},
+		{
+			name:    "no pat",
+			source:  `pat: "${TEST_AZDO_PAT}"`,
+			wantErr: "azure_devops.pat is required",
+		},
+		{
+			name:   "bearer auth does not run pat_command",
+			source: "pat_command: \"exit 3\"\n  auth:\n    type: bearer\n    token: token",
+		},
+		{
+			name:   "command auth ignores conflicting pat sources",
+			source: "pat: \"literal-pat\"\n  pat_command: \"exit 3\"\n  auth:\n    type: command",
+			// The PAT is not resolved, so it is left as configured
+			wantPAT: "literal-pat",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TEST_AZDO_PAT", "")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			data := "azure_devops:\n  organization: contoso\n  " + tt.source + "\nprojects:\n  - name: Payments\n"
+			if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := Load(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				if strings.Contains(err.Error(), "leaked-secret") {
+					t.Errorf("error leaks the command output: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load(): %v", err)
+			}
+			if got := cfg.Organizations[0].PAT; got != tt.wantPAT {
+				t.Errorf("PAT = %q, want %q", got, tt.wantPAT)
+			}
+		})
+	}
+}
+
+func TestExpandEnvVars(t *testing.T) {
+	t.Setenv("TEST_AZDO_SET", "value")
+	t.Setenv("TEST_AZDO_EMPTY", "")
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`pat: "${TEST_AZDO_SET}"`, `pat: "value"`},
+		{`pat: "${TEST_AZDO_EMPTY}"`, `pat: ""`},
+		{`url: "https://${TEST_AZDO_SET}.example.com/${TEST_AZDO_SET}"`, `url: "https://value.example.com/value"`},
+		{`pat: "$TEST_AZDO_SET"`, `pat: "$TEST_AZDO_SET"`},
+	}
+
+	for _, tt := range tests {
+		if got := expandEnvVars(tt.in); got != tt.want {
+			t.Errorf("expandEnvVars(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLoadForReplay(t *testing.T) {
+	t.Setenv("TEST_AZDO_PAT", "")
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := "azure_devops:\n  organization: contoso\n  pat: \"${TEST_AZDO_PAT}\"\nprojects:\n  - name: Payments\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(path); err == nil {
+		t.Error("Load() without a PAT succeeded, want an error")
+	}
+	cfg, err := LoadForReplay(path)
+	if err != nil {
+		t.Fatalf("LoadForReplay(): %v", err)
+	}
+	if cfg.Organizations[0].PAT != "" {
+		t.Errorf("PAT = %q, want empty", cfg.Organizations[0].PAT)
+	}
+
+	// Credential commands are not run when replaying
+	data = "azure_devops:\n  organization: contoso\n  pat_command: \"exit 3\"\nprojects:\n  - name: Payments\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadForReplay(path); err != nil {
+		t.Errorf("LoadForReplay() with a failing pat_command: %v", err)
+	}
+}
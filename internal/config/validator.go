@@ -18,32 +18,50 @@ func (e ValidationError) Error() string {
 
 // Validate checks if the configuration is valid
 func Validate(cfg *Config) error {
-	var errs []string
-
-	// Validate Azure DevOps settings
-	if cfg.AzureDevOps.Organization == "" {
-		errs = append(errs, "azure_devops.organization is required")
-	}
+	return validate(cfg, true)
+}
 
-	if cfg.AzureDevOps.PAT == "" {
-		errs = append(errs, "azure_devops.pat is required (set AZURE_DEVOPS_PAT environment variable)")
-	}
+// validate checks the configuration, including the credentials if requested
+func validate(cfg *Config, credentials bool) error {
+	var errs []string
 
-	if cfg.AzureDevOps.BaseURL != "" {
-		if !strings.HasPrefix(cfg.AzureDevOps.BaseURL, "https://") &&
-			!strings.HasPrefix(cfg.AzureDevOps.BaseURL, "http://") {
-			errs = append(errs, "azure_devops.base_url must start with http:// or https://")
+	// Validate the organizations and their projects
+	names := make(map[string]bool)
+	for i, org := range cfg.Organizations {
+		prefix := "azure_devops"
+		if len(cfg.Organizations) > 1 {
+			prefix = fmt.Sprintf("organizations[%d]", i)
+			if names[org.Name] {
+				errs = append(errs, fmt.Sprintf("%s.name %q is used by more than one organization", prefix, org.Name))
+			}
+			names[org.Name] = true
 		}
-	}
+		errs = append(errs, validateAzureDevOps(prefix, org.AzureDevOpsConfig, credentials)...)
 
-	// Validate projects
-	if len(cfg.Projects) == 0 {
-		errs = append(errs, "at least one project must be configured")
-	}
+		projects := "projects"
+		if len(cfg.Organizations) > 1 {
+			projects = prefix + ".projects"
+		}
+		if discovery := org.ProjectSource.Discovery; discovery != nil {
+			errs = append(errs, validGlobs(projects+".include", discovery.Include)...)
+			errs = append(errs, validGlobs(projects+".exclude", discovery.Exclude)...)
+		} else if len(org.Projects) == 0 {
+			if len(cfg.Organizations) > 1 {
+				errs = append(errs, prefix+": at least one project must be configured")
+			} else {
+				errs = append(errs, "at least one project must be configured")
+			}
+		}
 
-	for i, p := range cfg.Projects {
-		if p.Name == "" {
-			errs = append(errs, fmt.Sprintf("projects[%d].name is required", i))
+		for j, p := range org.Projects {
+			field := fmt.Sprintf("%s[%d]", projects, j)
+			if p.Name == "" {
+				errs = append(errs, field+".name is required")
+			}
+			errs = append(errs, validDefinitionPatterns(field+".build_definitions", p.BuildSelectors)...)
+			errs = append(errs, validDefinitionPatterns(field+".release_definitions", p.ReleaseSelectors)...)
+			errs = append(errs, validBranchPatterns(field+".branches", p.Branches)...)
+			errs = append(errs, validBranchPatterns(field+".target_branches", p.TargetBranches)...)
 		}
 	}
 
@@ -56,6 +74,13 @@ func Validate(cfg *Config) error {
 		errs = append(errs, "display.max_items_per_project must be at least 1")
 	}
 
+	// Validate pull request filters
+	switch cfg.PullRequests.Status {
+	case "active", "completed", "abandoned", "all":
+	default:
+		errs = append(errs, "pull_requests.status must be one of active, completed, abandoned or all")
+	}
+
 	// Validate rate limiting settings
 	if cfg.RateLimiting.RequestsPerSecond <= 0 {
 		errs = append(errs, "rate_limiting.requests_per_second must be positive")
@@ -65,9 +90,106 @@ func Validate(cfg *Config) error {
 		errs = append(errs, "rate_limiting.burst_size must be at least 1")
 	}
 
+	// Validate HTTP settings
+	if cfg.HTTP.Timeout < 0 {
+		errs = append(errs, "http.timeout cannot be negative")
+	}
+
+	if cfg.HTTP.Proxy != "" && !isHTTPURL(cfg.HTTP.Proxy) {
+		errs = append(errs, "http.proxy must start with http:// or https://")
+	}
+
+	if (cfg.HTTP.ClientCert == "") != (cfg.HTTP.ClientKey == "") {
+		errs = append(errs, "http.client_cert and http.client_key must be set together")
+	}
+
+	switch cfg.HTTP.TLSMinVersion {
+	case "", "1.0", "1.1", "1.2", "1.3":
+	default:
+		errs = append(errs, "http.tls_min_version must be one of 1.0, 1.1, 1.2 or 1.3")
+	}
+
+	// Validate service hook listener settings
+	if cfg.Webhook.Enabled {
+		if cfg.Webhook.Secret == "" && (cfg.Webhook.Username == "" || cfg.Webhook.Password == "") {
+			errs = append(errs, "webhook.secret or webhook.username and webhook.password are required when the listener is enabled")
+		}
+		if cfg.Webhook.FallbackInterval < 0 {
+			errs = append(errs, "webhook.fallback_interval cannot be negative")
+		}
+	}
+
 	if len(errs) > 0 {
 		return errors.New(strings.Join(errs, "; "))
 	}
 
 	return nil
 }
+
+// validateAzureDevOps checks the connection and authentication settings of one organization.
+// prefix is the configuration path used in messages, e.g. "azure_devops". Without credentials,
+// the PAT and token may be missing.
+func validateAzureDevOps(prefix string, c AzureDevOpsConfig, credentials bool) []string {
+	var errs []string
+
+	switch c.Mode {
+	case ModeCloud:
+		if c.Organization == "" {
+			errs = append(errs, prefix+".organization is required")
+		}
+	case ModeServer:
+		if c.CollectionURL == "" && c.Organization == "" {
+			errs = append(errs, prefix+".collection_url (or base_url and organization) is required in server mode")
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("%s.mode must be %q or %q", prefix, ModeCloud, ModeServer))
+	}
+
+	switch c.Auth.Type {
+	case AuthTypePAT:
+		if credentials && c.PAT == "" {
+			errs = append(errs, prefix+".pat is required (set AZURE_DEVOPS_PAT environment variable, or use pat_command, pat_file or pat_keyring)")
+		}
+	case AuthTypeBearer:
+		if credentials && c.Auth.Token == "" {
+			errs = append(errs, prefix+".auth.token is required for bearer authentication")
+		}
+	case AuthTypeCommand:
+		// An empty command defaults to the Azure CLI
+	default:
+		errs = append(errs, fmt.Sprintf("%s.auth.type must be %q, %q or %q",
+			prefix, AuthTypePAT, AuthTypeBearer, AuthTypeCommand))
+	}
+
+	urls := []struct {
+		field string
+		value string
+	}{
+		{prefix + ".base_url", c.BaseURL},
+		{prefix + ".collection_url", c.CollectionURL},
+		{prefix + ".release_base_url", c.ReleaseBaseURL},
+	}
+	for _, u := range urls {
+		if u.value != "" && !isHTTPURL(u.value) {
+			errs = append(errs, u.field+" must start with http:// or https://")
+		}
+	}
+
+	return errs
+}
+
+// isHTTPURL returns true if the value looks like an http or https URL
+func isHTTPURL(value string) bool {
+	return strings.HasPrefix(value, "https://") || strings.HasPrefix(value, "http://")
+}
+
+// validBranchPatterns returns an error message for every empty branch pattern
+func validBranchPatterns(field string, patterns []string) []string {
+	var errs []string
+	for _, p := range patterns {
+		if strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(p), "!")) == "" {
+			errs = append(errs, fmt.Sprintf("%s: empty pattern %q", field, p))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateProjectPaths(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "single organization",
+			data: `azure_devops:
+  organization: contoso
+  pat: pat
+projects:
+  - name: Payments
+  - name: Orders
+    branches: [""]
+`,
+			want: []string{`projects[1].branches: empty pattern ""`},
+		},
+		{
+			name: "two organizations",
+			data: `organizations:
+  - organization: contoso
+    pat: pat
+    projects:
+      - name: Payments
+      - name: Orders
+  - organization: fabrikam
+    pat: pat
+    projects:
+      - name: Billing
+        target_branches: ["!"]
+      - name: ""
+`,
+			want: []string{
+				`organizations[1].projects[0].target_branches: empty pattern "!"`,
+				"organizations[1].projects[1].name is required",
+			},
+			// Indexes into the projects of all organizations point at the wrong entry
+			notWant: []string{"projects[2]", "projects[3]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := Load(path)
+			if err == nil {
+				t.Fatal("Load() succeeded, want a validation error")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not contain %q", err, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(err.Error(), notWant) {
+					t.Errorf("error %q contains %q", err, notWant)
+				}
+			}
+		})
+	}
+}
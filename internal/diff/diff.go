@@ -0,0 +1,215 @@
+// Package diff builds line-based unified diffs of two file versions.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxLines is the number of lines per side above which Unified gives up
+const MaxLines = 5000
+
+// MaxCells caps the longest common subsequence table, which has a cell for every pair of
+// changed lines, at 16 MB. Files with larger changed regions are not diffed.
+const MaxCells = 4 << 20
+
+// Kind describes how a line of a diff changed
+type Kind int
+
+const (
+	Context Kind = iota
+	Added
+	Removed
+)
+
+// Line is a line of a unified diff. OldLine and NewLine are 1-based line numbers,
+// zero on the side the line does not exist in.
+type Line struct {
+	Kind    Kind
+	Text    string
+	OldLine int
+	NewLine int
+}
+
+// String returns the line with its unified diff prefix
+func (l Line) String() string {
+	switch l.Kind {
+	case Added:
+		return "+" + l.Text
+	case Removed:
+		return "-" + l.Text
+	default:
+		return " " + l.Text
+	}
+}
+
+// Hunk is a group of changed lines with their surrounding context
+type Hunk struct {
+	OldStart, OldLines int
+	NewStart, NewLines int
+	Lines              []Line
+}
+
+// Header returns the unified diff header of the hunk, e.g. "@@ -1,4 +1,5 @@"
+func (h Hunk) Header() string {
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@", h.OldStart, h.OldLines, h.NewStart, h.NewLines)
+}
+
+// SplitLines splits file content into lines without their line endings
+func SplitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	content = strings.TrimSuffix(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	return strings.Split(content, "\n")
+}
+
+// IsBinary returns true if the content looks like a binary file
+func IsBinary(content []byte) bool {
+	if len(content) > 8000 {
+		content = content[:8000]
+	}
+	for _, b := range content {
+		if b == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Unified returns the hunks that turn oldText into newText, each with up to
+// context unchanged lines around the changes. It returns an error if either
+// side has more than MaxLines lines or the changed regions exceed MaxCells.
+func Unified(oldText, newText string, context int) ([]Hunk, error) {
+	a, b := SplitLines(oldText), SplitLines(newText)
+	if len(a) > MaxLines || len(b) > MaxLines {
+		return nil, fmt.Errorf("file too large to diff (more than %d lines)", MaxLines)
+	}
+	lines, err := compare(a, b)
+	if err != nil {
+		return nil, err
+	}
+	return group(lines, context), nil
+}
+
+// compare returns every line of both sides in order, using the longest common subsequence
+func compare(a, b []string) ([]Line, error) {
+	// Skip the common prefix and suffix so the table only covers the changed region
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	width := len(midB) + 1
+	if (len(midA)+1)*width > MaxCells {
+		return nil, fmt.Errorf("file too large to diff (%d changed lines against %d)", len(midA), len(midB))
+	}
+
+	// lcs[i*width+j] is the length of the longest common subsequence of midA[i:] and midB[j:]
+	lcs := make([]int32, (len(midA)+1)*width)
+	for i := len(midA) - 1; i >= 0; i-- {
+		for j := len(midB) - 1; j >= 0; j-- {
+			if midA[i] == midB[j] {
+				lcs[i*width+j] = lcs[(i+1)*width+j+1] + 1
+			} else {
+				lcs[i*width+j] = max(lcs[(i+1)*width+j], lcs[i*width+j+1])
+			}
+		}
+	}
+
+	lines := make([]Line, 0, len(a)+len(b)-prefix-suffix)
+	oldLine, newLine := 1, 1
+	keep := func(text string) {
+		lines = append(lines, Line{Kind: Context, Text: text, OldLine: oldLine, NewLine: newLine})
+		oldLine++
+		newLine++
+	}
+
+	for _, text := range a[:prefix] {
+		keep(text)
+	}
+	i, j := 0, 0
+	for i < len(midA) || j < len(midB) {
+		switch {
+		case i < len(midA) && j < len(midB) && midA[i] == midB[j]:
+			keep(midA[i])
+			i++
+			j++
+		case j == len(midB) || (i < len(midA) && lcs[(i+1)*width+j] >= lcs[i*width+j+1]):
+			lines = append(lines, Line{Kind: Removed, Text: midA[i], OldLine: oldLine})
+			oldLine++
+			i++
+		default:
+			lines = append(lines, Line{Kind: Added, Text: midB[j], NewLine: newLine})
+			newLine++
+			j++
+		}
+	}
+	for _, text := range a[len(a)-suffix:] {
+		keep(text)
+	}
+	return lines, nil
+}
+
+// group collects changed lines into hunks with the given number of context lines.
+// Changes separated by at most 2*context unchanged lines share a hunk.
+func group(lines []Line, context int) []Hunk {
+	var hunks []Hunk
+	oldBefore, newBefore := 0, 0 // Lines of each side before lines[i]
+	for i := 0; i < len(lines); {
+		if lines[i].Kind == Context {
+			oldBefore++
+			newBefore++
+			i++
+			continue
+		}
+
+		start := max(i-context, 0)
+		end := i
+		for end < len(lines) {
+			if lines[end].Kind != Context {
+				end++
+				continue
+			}
+			// Find the next change and see whether it is close enough to join this hunk
+			next := end
+			for next < len(lines) && lines[next].Kind == Context {
+				next++
+			}
+			if next == len(lines) || next-end > 2*context {
+				end = min(end+context, len(lines))
+				break
+			}
+			end = next
+		}
+
+		// Context lines before the change were counted already
+		h := Hunk{OldStart: oldBefore - (i - start), NewStart: newBefore - (i - start), Lines: lines[start:end]}
+		for _, l := range h.Lines {
+			if l.Kind != Added {
+				h.OldLines++
+			}
+			if l.Kind != Removed {
+				h.NewLines++
+			}
+		}
+		oldBefore = h.OldStart + h.OldLines
+		newBefore = h.NewStart + h.NewLines
+		// Ranges start at their first line, or at the line before an empty range as in GNU diff
+		if h.OldLines > 0 {
+			h.OldStart++
+		}
+		if h.NewLines > 0 {
+			h.NewStart++
+		}
+
+		hunks = append(hunks, h)
+		i = end
+	}
+	return hunks
+}
@@ -0,0 +1,127 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// render formats hunks the way diff -u prints them, without file headers
+func render(hunks []Hunk) string {
+	var b strings.Builder
+	for _, h := range hunks {
+		b.WriteString(h.Header() + "\n")
+		for _, l := range h.Lines {
+			b.WriteString(l.String() + "\n")
+		}
+	}
+	return b.String()
+}
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		context  int
+		want     string
+	}{
+		{
+			name: "identical",
+			old:  "a\nb\n",
+			new:  "a\nb\n",
+			want: "",
+		},
+		{
+			name:    "changed line",
+			old:     "a\nb\nc\n",
+			new:     "a\nB\nc\n",
+			context: 3,
+			want:    "@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name:    "added file",
+			old:     "",
+			new:     "a\nb\n",
+			context: 3,
+			want:    "@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name:    "deleted file",
+			old:     "a\nb\n",
+			new:     "",
+			context: 3,
+			want:    "@@ -1,2 +0,0 @@\n-a\n-b\n",
+		},
+		{
+			name:    "insertion without context",
+			old:     "a\nb\n",
+			new:     "a\nx\nb\n",
+			context: 0,
+			want:    "@@ -1,0 +2,1 @@\n+x\n",
+		},
+		{
+			name:    "distant changes in separate hunks",
+			old:     "1\n2\n3\n4\n5\n6\n7\n8\n",
+			new:     "one\n2\n3\n4\n5\n6\n7\neight\n",
+			context: 1,
+			want:    "@@ -1,2 +1,2 @@\n-1\n+one\n 2\n@@ -7,2 +7,2 @@\n 7\n-8\n+eight\n",
+		},
+		{
+			name:    "close changes share a hunk",
+			old:     "1\n2\n3\n4\n",
+			new:     "one\n2\n3\nfour\n",
+			context: 1,
+			want:    "@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n-4\n+four\n",
+		},
+		{
+			name:    "crlf line endings",
+			old:     "a\r\nb\r\n",
+			new:     "a\nb\nc\n",
+			context: 1,
+			want:    "@@ -2,1 +2,2 @@\n b\n+c\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hunks, err := Unified(tt.old, tt.new, tt.context)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := render(hunks); got != tt.want {
+				t.Errorf("Unified() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnifiedTooLarge(t *testing.T) {
+	large := strings.Repeat("x\n", MaxLines+1)
+	if _, err := Unified(large, "x\n", 3); err == nil {
+		t.Error("expected an error for a file above MaxLines")
+	}
+
+	// Small enough by lines, but every line changed
+	var oldText, newText strings.Builder
+	for i := 0; i < MaxLines; i++ {
+		fmt.Fprintf(&oldText, "old %d\n", i)
+		fmt.Fprintf(&newText, "new %d\n", i)
+	}
+	if _, err := Unified(oldText.String(), newText.String(), 3); err == nil || !strings.Contains(err.Error(), "too large") {
+		t.Errorf("Unified() error = %v, want too large to diff", err)
+	}
+
+	// The same file with a few changed lines is fine, as the common lines are skipped
+	if _, err := Unified(oldText.String(), strings.Replace(oldText.String(), "old 42\n", "new 42\n", 1), 3); err != nil {
+		t.Errorf("Unified() with one changed line: %v", err)
+	}
+}
+
+func TestIsBinary(t *testing.T) {
+	if IsBinary([]byte("package main\n")) {
+		t.Error("text detected as binary")
+	}
+	if !IsBinary([]byte{0x89, 'P', 'N', 'G', 0x00, 0x01}) {
+		t.Error("binary not detected")
+	}
+}
@@ -0,0 +1,166 @@
+// Package discovery resolves configuration that needs the Azure DevOps API, such as
+// projects selected with "projects: auto".
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/api"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/config"
+)
+
+// Projects lists the projects of every organization configured with "projects: auto",
+// keeps those matching the include/exclude globs and updates cfg.Projects.
+// clients holds one API client per organization name. Organizations whose projects cannot
+// be listed keep no projects; their errors are joined in the returned error.
+func Projects(ctx context.Context, cfg *config.Config, clients map[string]api.Service) error {
+	var errs []error
+	for i := range cfg.Organizations {
+		org := &cfg.Organizations[i]
+		if !org.DiscoversProjects() {
+			continue
+		}
+		org.Projects = nil
+
+		client, ok := clients[org.Name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("no API client for organization %q", org.Name))
+			continue
+		}
+
+		projects, err := client.GetProjects(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to list projects of %s: %w", client.GetOrganizationURL(), err))
+			continue
+		}
+
+		for _, p := range Select(projects, *org.ProjectSource.Discovery) {
+			org.Projects = append(org.Projects, config.ProjectConfig{Organization: org.Name, Name: p.Name})
+		}
+		if len(org.Projects) == 0 {
+			errs = append(errs, fmt.Errorf("no projects of %s match the configured include/exclude patterns", client.GetOrganizationURL()))
+		}
+	}
+
+	cfg.CollectProjects()
+	return errors.Join(errs...)
+}
+
+// Select returns the projects matching the discovery globs, sorted by name
+func Select(projects []api.TeamProject, discovery config.DiscoveryConfig) []api.TeamProject {
+	var selected []api.TeamProject
+	for _, p := range projects {
+		if discovery.Matches(p.Name) {
+			selected = append(selected, p)
+		}
+	}
+
+	sort.Slice(selected, func(i, j int) bool {
+		return strings.ToLower(selected[i].Name) < strings.ToLower(selected[j].Name)
+	})
+	return selected
+}
+
+// Resolution holds the definition IDs selected by a project's definition selectors
+type Resolution struct {
+	BuildDefinitions   []int
+	ReleaseDefinitions []int
+	Unmatched          []string // Selectors that matched no definition, e.g. "build definition \Old\*"
+}
+
+// ResolveDefinitions looks up the build and release definitions selected by name, glob or
+// folder. Numeric IDs are kept without a lookup.
+func ResolveDefinitions(ctx context.Context, client api.Service, project config.ProjectConfig) (Resolution, error) {
+	var res Resolution
+
+	if ids, ok := project.BuildSelectors.IDs(); ok {
+		res.BuildDefinitions = ids
+	} else {
+		defs, err := client.GetBuildDefinitions(ctx, project.Name)
+		if err != nil {
+			return Resolution{}, fmt.Errorf("failed to list build definitions of %s: %w", project.Name, err)
+		}
+		for _, selector := range project.BuildSelectors {
+			var matched bool
+			for _, d := range defs {
+				if selector.Matches(d.ID, d.Name, d.Path) {
+					res.BuildDefinitions = appendUnique(res.BuildDefinitions, d.ID)
+					matched = true
+				}
+			}
+			if !matched {
+				res.Unmatched = append(res.Unmatched, "build definition "+selector.String())
+			}
+		}
+	}
+
+	if ids, ok := project.ReleaseSelectors.IDs(); ok {
+		res.ReleaseDefinitions = ids
+	} else {
+		defs, err := client.GetReleaseDefinitions(ctx, project.Name)
+		if err != nil {
+			return Resolution{}, fmt.Errorf("failed to list release definitions of %s: %w", project.Name, err)
+		}
+		for _, selector := range project.ReleaseSelectors {
+			var matched bool
+			for _, d := range defs {
+				if selector.Matches(d.ID, d.Name, d.Path) {
+					res.ReleaseDefinitions = appendUnique(res.ReleaseDefinitions, d.ID)
+					matched = true
+				}
+			}
+			if !matched {
+				res.Unmatched = append(res.Unmatched, "release definition "+selector.String())
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// Definitions resolves the definition selectors of every project that uses names, globs
+// or folders and updates the configuration. The returned warnings name the selectors
+// that match no definition. Projects whose definitions cannot be listed keep no definition
+// IDs and show nothing until a later lookup succeeds; their errors are joined in the
+// returned error.
+func Definitions(ctx context.Context, cfg *config.Config, clients map[string]api.Service) ([]string, error) {
+	var warnings []string
+	var errs []error
+	for i := range cfg.Organizations {
+		org := &cfg.Organizations[i]
+		for j := range org.Projects {
+			p := &org.Projects[j]
+			if !p.HasDefinitionPatterns() {
+				continue
+			}
+
+			res, err := ResolveDefinitions(ctx, clients[org.Name], *p)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			p.BuildDefinitions = res.BuildDefinitions
+			p.ReleaseDefinitions = res.ReleaseDefinitions
+			for _, selector := range res.Unmatched {
+				warnings = append(warnings, fmt.Sprintf("%s: %s matches no definition", p.Key(), selector))
+			}
+		}
+	}
+
+	cfg.CollectProjects()
+	return warnings, errors.Join(errs...)
+}
+
+// appendUnique appends id unless it is already present
+func appendUnique(ids []int, id int) []int {
+	for _, existing := range ids {
+		if existing == id {
+			return ids
+		}
+	}
+	return append(ids, id)
+}
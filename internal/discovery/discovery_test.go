@@ -0,0 +1,195 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/api"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/api/fake"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/config"
+)
+
+func TestResolveDefinitions(t *testing.T) {
+	svc := fake.New()
+	svc.BuildDefinitions["Payments"] = []api.BuildDefinition{
+		{ID: 1, Name: "payments-api-ci", Path: `\Services\Payments`},
+		{ID: 2, Name: "payments-worker-ci", Path: `\Services\Payments\Workers`},
+		{ID: 3, Name: "Docs", Path: `\`},
+		{ID: 4, Name: "legacy-ci", Path: `\Archive`},
+	}
+	svc.ReleaseDefinitions["Payments"] = []api.ReleaseDefinition{
+		{ID: 10, Name: "Payments API", Path: `\`},
+	}
+
+	tests := []struct {
+		name      string
+		builds    config.DefinitionSelectors
+		releases  config.DefinitionSelectors
+		wantBuild []int
+		wantRel   []int
+		unmatched []string
+	}{
+		{
+			name:      "name glob",
+			builds:    config.DefinitionSelectors{{Pattern: "payments-*"}},
+			wantBuild: []int{1, 2},
+		},
+		{
+			name:      "folder",
+			builds:    config.DefinitionSelectors{{Pattern: `\services\payments\*`}},
+			wantBuild: []int{1},
+		},
+		{
+			name:      "folder with subfolders",
+			builds:    config.DefinitionSelectors{{Pattern: `\Services\**`}},
+			wantBuild: []int{1, 2},
+		},
+		{
+			name:      "root folder",
+			builds:    config.DefinitionSelectors{{Pattern: `\*`}},
+			wantBuild: []int{3},
+		},
+		{
+			name:      "mixed IDs and names without duplicates",
+			builds:    config.DefinitionSelectors{{ID: 4}, {Pattern: "legacy-ci"}, {Pattern: "*-api-*"}},
+			releases:  config.DefinitionSelectors{{ID: 10}},
+			wantBuild: []int{4, 1},
+			wantRel:   []int{10},
+		},
+		{
+			name:      "unmatched selectors",
+			builds:    config.DefinitionSelectors{{Pattern: `\Old\*`}},
+			releases:  config.DefinitionSelectors{{Pattern: "nightly"}},
+			unmatched: []string{`build definition \Old\*`, "release definition nightly"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := config.ProjectConfig{Name: "Payments", BuildSelectors: tt.builds, ReleaseSelectors: tt.releases}
+			res, err := ResolveDefinitions(context.Background(), svc, project)
+			if err != nil {
+				t.Fatalf("ResolveDefinitions: %v", err)
+			}
+			if !reflect.DeepEqual(res.BuildDefinitions, tt.wantBuild) {
+				t.Errorf("build definitions = %v, want %v", res.BuildDefinitions, tt.wantBuild)
+			}
+			if !reflect.DeepEqual(res.ReleaseDefinitions, tt.wantRel) {
+				t.Errorf("release definitions = %v, want %v", res.ReleaseDefinitions, tt.wantRel)
+			}
+			if !reflect.DeepEqual(res.Unmatched, tt.unmatched) {
+				t.Errorf("unmatched = %v, want %v", res.Unmatched, tt.unmatched)
+			}
+		})
+	}
+}
+
+func TestDefinitionsWarnings(t *testing.T) {
+	svc := fake.New()
+	svc.BuildDefinitions["Payments"] = []api.BuildDefinition{{ID: 1, Name: "2024"}, {ID: 2, Name: "payments-ci"}}
+
+	cfg := &config.Config{Organizations: []config.OrganizationConfig{{
+		Name:              "contoso",
+		AzureDevOpsConfig: config.AzureDevOpsConfig{Organization: "contoso"},
+		Projects: []config.ProjectConfig{{
+			Organization:   "contoso",
+			Name:           "Payments",
+			BuildSelectors: config.DefinitionSelectors{{Pattern: "2024"}, {Pattern: "orders-*"}},
+		}},
+	}}}
+
+	warnings, err := Definitions(context.Background(), cfg, map[string]api.Service{"contoso": svc})
+	if err != nil {
+		t.Fatalf("Definitions: %v", err)
+	}
+	if want := []string{"contoso/Payments: build definition orders-* matches no definition"}; !reflect.DeepEqual(warnings, want) {
+		t.Errorf("warnings = %q, want %q", warnings, want)
+	}
+	if got := cfg.Projects[0].BuildDefinitions; !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("build definitions = %v, want [1]", got)
+	}
+}
+
+func TestDefinitionsPartialFailure(t *testing.T) {
+	contoso, fabrikam := fake.New(), fake.New()
+	contoso.BuildDefinitions["Payments"] = []api.BuildDefinition{{ID: 2, Name: "payments-ci"}}
+	fabrikam.SetError(fake.OpGetBuildDefinitions, errors.New("unauthorized"))
+
+	project := func(org string) config.ProjectConfig {
+		return config.ProjectConfig{Organization: org, Name: "Payments", BuildSelectors: config.DefinitionSelectors{{Pattern: "payments-*"}}}
+	}
+	cfg := &config.Config{Organizations: []config.OrganizationConfig{
+		{Name: "fabrikam", Projects: []config.ProjectConfig{project("fabrikam")}},
+		{Name: "contoso", Projects: []config.ProjectConfig{project("contoso")}},
+	}}
+
+	_, err := Definitions(context.Background(), cfg, map[string]api.Service{"contoso": contoso, "fabrikam": fabrikam})
+	if err == nil || !strings.Contains(err.Error(), "unauthorized") {
+		t.Fatalf("Definitions error = %v, want the failed lookup", err)
+	}
+	// The failed project is kept without definitions and the other one is still resolved
+	if len(cfg.Projects) != 2 {
+		t.Fatalf("%d projects, want 2", len(cfg.Projects))
+	}
+	if got := cfg.Projects[0].BuildDefinitions; len(got) != 0 {
+		t.Errorf("unresolved build definitions = %v, want none", got)
+	}
+	if got := cfg.Projects[1].BuildDefinitions; !reflect.DeepEqual(got, []int{2}) {
+		t.Errorf("build definitions = %v, want [2]", got)
+	}
+}
+
+func TestProjectsPartialFailure(t *testing.T) {
+	contoso, fabrikam := fake.New(), fake.New()
+	contoso.Projects = []api.TeamProject{{Name: "Payments"}, {Name: "Orders"}}
+	fabrikam.SetError(fake.OpGetProjects, errors.New("connection refused"))
+
+	cfg := &config.Config{Organizations: []config.OrganizationConfig{
+		{Name: "fabrikam", ProjectSource: config.ProjectsSetting{Discovery: &config.DiscoveryConfig{}}},
+		{Name: "contoso", ProjectSource: config.ProjectsSetting{Discovery: &config.DiscoveryConfig{}}},
+	}}
+
+	err := Projects(context.Background(), cfg, map[string]api.Service{"contoso": contoso, "fabrikam": fabrikam})
+	if err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Fatalf("Projects error = %v, want the failed listing", err)
+	}
+	// The failed organization is kept without projects and the other one is still discovered
+	var keys []string
+	for _, p := range cfg.Projects {
+		keys = append(keys, p.Key())
+	}
+	if want := []string{"contoso/Orders", "contoso/Payments"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("projects = %v, want %v", keys, want)
+	}
+}
+
+func TestSelect(t *testing.T) {
+	projects := []api.TeamProject{{Name: "web"}, {Name: "Payments"}, {Name: "payments-archive"}, {Name: "Orders"}}
+
+	tests := []struct {
+		name      string
+		discovery config.DiscoveryConfig
+		want      []string
+	}{
+		{"all, sorted case-insensitively", config.DiscoveryConfig{}, []string{"Orders", "Payments", "payments-archive", "web"}},
+		{"include", config.DiscoveryConfig{Include: []string{"PAY*"}}, []string{"Payments", "payments-archive"}},
+		{"exclude", config.DiscoveryConfig{Exclude: []string{"*-archive"}}, []string{"Orders", "Payments", "web"}},
+		{"include and exclude", config.DiscoveryConfig{Include: []string{"pay*", "web"}, Exclude: []string{"*-archive"}}, []string{"Payments", "web"}},
+		{"nothing matches", config.DiscoveryConfig{Include: []string{"billing"}}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, p := range Select(projects, tt.discovery) {
+				got = append(got, p.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Select() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
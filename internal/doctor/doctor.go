@@ -0,0 +1,206 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/api"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/config"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/discovery"
+)
+
+// Status represents the outcome of a single check
+type Status int
+
+const (
+	StatusOK Status = iota
+	StatusWarning
+	StatusFailed
+)
+
+// Icon returns the icon shown next to a check
+func (s Status) Icon() string {
+	switch s {
+	case StatusOK:
+		return "✓"
+	case StatusWarning:
+		return "!"
+	default:
+		return "✗"
+	}
+}
+
+// Check is the result of a single preflight check
+type Check struct {
+	Name    string
+	Status  Status
+	Message string
+}
+
+// Report holds the results of a preflight run
+type Report struct {
+	User   string // Display name of the authenticated user, empty if the connection failed
+	Checks []Check
+}
+
+// HasFailures returns true if any check failed
+func (r Report) HasFailures() bool {
+	return len(r.Failures()) > 0
+}
+
+// Failures returns the failed checks
+func (r Report) Failures() []Check {
+	var failed []Check
+	for _, c := range r.Checks {
+		if c.Status == StatusFailed {
+			failed = append(failed, c)
+		}
+	}
+	return failed
+}
+
+// String formats the report as plain text, one check per line
+func (r Report) String() string {
+	var b strings.Builder
+	for _, c := range r.Checks {
+		fmt.Fprintf(&b, "%s %s: %s\n", c.Status.Icon(), c.Name, c.Message)
+	}
+	return b.String()
+}
+
+// Run checks the connection, the credentials and the given projects and their definitions
+func Run(ctx context.Context, client api.Service, projects []config.ProjectConfig) Report {
+	var report Report
+
+	add := func(name string, status Status, message string) {
+		report.Checks = append(report.Checks, Check{Name: name, Status: status, Message: message})
+	}
+
+	orgURL := client.GetOrganizationURL()
+
+	conn, err := client.GetConnectionData(ctx)
+	if err != nil {
+		add("Connection", StatusFailed, explainConnectionError(err, orgURL))
+		return report
+	}
+	if conn.AuthenticatedUser.IsAnonymous() {
+		add("Connection", StatusFailed, "the server did not accept the credentials (signed in as anonymous)")
+		return report
+	}
+
+	report.User = conn.AuthenticatedUser.ProviderDisplayName
+	account := conn.AuthenticatedUser.Properties.Account.Value
+	if account != "" && account != report.User {
+		add("Connection", StatusOK, fmt.Sprintf("connected to %s as %s (%s)", orgURL, report.User, account))
+	} else {
+		add("Connection", StatusOK, fmt.Sprintf("connected to %s as %s", orgURL, report.User))
+	}
+
+	for _, p := range projects {
+		if _, err := client.GetProject(ctx, p.Name); err != nil {
+			add("Project "+p.Name, StatusFailed, explainError(err, "Project and Team (Read)",
+				fmt.Sprintf("project %q does not exist in %s or you have no access to it", p.Name, orgURL)))
+			continue
+		}
+		add("Project "+p.Name, StatusOK, "found")
+
+		if p.HasDefinitionPatterns() {
+			res, err := discovery.ResolveDefinitions(ctx, client, p)
+			if err != nil {
+				add(p.Name+": definitions", StatusFailed, explainError(err, "Build (Read) and Release (Read)", "definitions cannot be listed"))
+			}
+			for _, selector := range res.Unmatched {
+				add(p.Name+": "+selector, StatusWarning, "matches no definition")
+			}
+		}
+
+		if err := client.CheckBuildAccess(ctx, p.Name); err != nil {
+			add(p.Name+": builds", StatusFailed, explainError(err, "Build (Read)", "builds are not available"))
+		} else {
+			for _, id := range p.BuildDefinitions {
+				if _, err := client.GetBuildDefinition(ctx, p.Name, id); err != nil {
+					add(fmt.Sprintf("%s: build definition %d", p.Name, id), StatusFailed, explainError(err, "Build (Read)",
+						fmt.Sprintf("build definition %d does not exist in project %q", id, p.Name)))
+				}
+			}
+		}
+
+		if err := client.CheckReleaseAccess(ctx, p.Name); err != nil {
+			if isStatus(err, http.StatusNotFound) && len(p.ReleaseDefinitions) == 0 {
+				add(p.Name+": releases", StatusWarning, "classic release pipelines are not available")
+			} else {
+				add(p.Name+": releases", StatusFailed, explainError(err, "Release (Read)", "releases are not available"))
+			}
+		} else {
+			for _, id := range p.ReleaseDefinitions {
+				if _, err := client.GetReleaseDefinition(ctx, p.Name, id); err != nil {
+					add(fmt.Sprintf("%s: release definition %d", p.Name, id), StatusFailed, explainError(err, "Release (Read)",
+						fmt.Sprintf("release definition %d does not exist in project %q", id, p.Name)))
+				}
+			}
+		}
+
+		if err := client.CheckCodeAccess(ctx, p.Name); err != nil {
+			add(p.Name+": pull requests", StatusFailed, explainError(err, "Code (Read)", "repositories are not available"))
+		}
+	}
+
+	return report
+}
+
+// explainConnectionError describes why the initial connection failed
+func explainConnectionError(err error, orgURL string) string {
+	var apiErr *api.APIError
+	if !errors.As(err, &apiErr) {
+		return fmt.Sprintf("cannot reach %s: %v", orgURL, err)
+	}
+
+	switch {
+	case isExpired(apiErr):
+		return "the personal access token has expired or was revoked; create a new one and update the configuration"
+	case apiErr.IsUnauthorized():
+		return "the credentials were rejected; check that the PAT (or access token) is valid and not revoked"
+	case apiErr.StatusCode == http.StatusNotFound:
+		return fmt.Sprintf("organization or collection not found at %s; check azure_devops.organization", orgURL)
+	default:
+		return apiErr.Message()
+	}
+}
+
+// explainError describes a failed check in plain language.
+// scope names the PAT scope needed for the call, notFound describes a 404.
+func explainError(err error, scope, notFound string) string {
+	var apiErr *api.APIError
+	if !errors.As(err, &apiErr) {
+		return err.Error()
+	}
+
+	switch {
+	case isExpired(apiErr):
+		return "the personal access token has expired or was revoked"
+	case apiErr.IsUnauthorized() || apiErr.StatusCode == http.StatusForbidden:
+		return fmt.Sprintf("access denied; the PAT is probably missing the %s scope", scope)
+	case apiErr.StatusCode == http.StatusNotFound:
+		return notFound
+	default:
+		return apiErr.Message()
+	}
+}
+
+// expiredTokenTypeKey is the error type Azure DevOps reports (as TF400813) for a personal
+// access token that has expired or was revoked, as opposed to missing or malformed credentials
+const expiredTokenTypeKey = "UnauthorizedRequestException"
+
+// isExpired returns true if the server reported an expired token
+func isExpired(apiErr *api.APIError) bool {
+	return apiErr.StatusCode == http.StatusUnauthorized && apiErr.TypeKey() == expiredTokenTypeKey
+}
+
+// isStatus returns true if err is an API error with the given status code
+func isStatus(err error, statusCode int) bool {
+	var apiErr *api.APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == statusCode
+}
@@ -0,0 +1,126 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/api"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/api/fake"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/config"
+)
+
+// apiError builds an API error with a JSON body like the ones Azure DevOps returns
+func apiError(status int, typeKey, message string) error {
+	return &api.APIError{
+		StatusCode: status,
+		Body:       `{"$id":"1","message":"` + message + `","typeKey":"` + typeKey + `","errorCode":0}`,
+	}
+}
+
+func TestRunConnection(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		user api.ConnectionIdentity
+		want string
+	}{
+		{
+			name: "expired token",
+			err:  apiError(http.StatusUnauthorized, "UnauthorizedRequestException", "TF400813: The user is not authorized to access this resource."),
+			want: "has expired or was revoked",
+		},
+		{
+			name: "rejected credentials",
+			err:  apiError(http.StatusUnauthorized, "VssServiceException", "Access denied, the token has expired in a way we do not report"),
+			want: "credentials were rejected",
+		},
+		{
+			name: "sign-in page",
+			err:  &api.APIError{StatusCode: http.StatusNonAuthoritativeInfo, Body: "<html>Sign in</html>"},
+			want: "credentials were rejected",
+		},
+		{
+			name: "unknown organization",
+			err:  &api.APIError{StatusCode: http.StatusNotFound},
+			want: "organization or collection not found at https://dev.azure.com/fake",
+		},
+		{
+			name: "network error",
+			err:  errors.New("dial tcp: no such host"),
+			want: "cannot reach https://dev.azure.com/fake: dial tcp: no such host",
+		},
+		{
+			name: "anonymous user",
+			user: api.ConnectionIdentity{ID: "anon", ProviderDisplayName: "Anonymous"},
+			want: "signed in as anonymous",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := fake.New()
+			svc.SetError(fake.OpGetConnectionData, tt.err)
+			if tt.user.ID != "" {
+				svc.Connection.AuthenticatedUser = tt.user
+			}
+
+			report := Run(context.Background(), svc, []config.ProjectConfig{{Name: "Payments"}})
+			if len(report.Checks) != 1 {
+				t.Fatalf("checks = %+v, want only the connection check", report.Checks)
+			}
+			check := report.Checks[0]
+			if check.Status != StatusFailed || !strings.Contains(check.Message, tt.want) {
+				t.Errorf("connection check = %+v, want a failure containing %q", check, tt.want)
+			}
+			if report.User != "" {
+				t.Errorf("User = %q, want empty", report.User)
+			}
+		})
+	}
+}
+
+func TestRunProjects(t *testing.T) {
+	svc := fake.New()
+	svc.SetError(fake.OpGetBuildDefinition, &api.APIError{StatusCode: http.StatusNotFound})
+	svc.SetError(fake.OpCheckReleaseAccess, &api.APIError{StatusCode: http.StatusNotFound})
+	svc.SetError(fake.OpCheckCodeAccess, apiError(http.StatusForbidden, "UnauthorizedRequestException", "TF401019"))
+
+	report := Run(context.Background(), svc, []config.ProjectConfig{{Name: "Payments", BuildDefinitions: []int{7}}})
+
+	want := []Check{
+		{Name: "Connection", Status: StatusOK, Message: "connected to https://dev.azure.com/fake as Fake User"},
+		{Name: "Project Payments", Status: StatusOK, Message: "found"},
+		{Name: "Payments: build definition 7", Status: StatusFailed, Message: `build definition 7 does not exist in project "Payments"`},
+		{Name: "Payments: releases", Status: StatusWarning, Message: "classic release pipelines are not available"},
+		{Name: "Payments: pull requests", Status: StatusFailed, Message: "access denied; the PAT is probably missing the Code (Read) scope"},
+	}
+	if len(report.Checks) != len(want) {
+		t.Fatalf("checks = %+v, want %+v", report.Checks, want)
+	}
+	for i := range want {
+		if report.Checks[i] != want[i] {
+			t.Errorf("check %d = %+v, want %+v", i, report.Checks[i], want[i])
+		}
+	}
+	if !report.HasFailures() || len(report.Failures()) != 2 {
+		t.Errorf("Failures() = %+v, want the build definition and pull request checks", report.Failures())
+	}
+}
+
+func TestRunMissingProject(t *testing.T) {
+	svc := fake.New()
+	svc.SetError(fake.OpGetProject, &api.APIError{StatusCode: http.StatusNotFound})
+
+	report := Run(context.Background(), svc, []config.ProjectConfig{{Name: "Payments"}})
+
+	if len(report.Checks) != 2 {
+		t.Fatalf("checks = %+v, want the connection and project checks", report.Checks)
+	}
+	want := `project "Payments" does not exist in https://dev.azure.com/fake or you have no access to it`
+	if got := report.Checks[1]; got.Status != StatusFailed || got.Message != want {
+		t.Errorf("project check = %+v, want a failure %q", got, want)
+	}
+}
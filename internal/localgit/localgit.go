@@ -0,0 +1,107 @@
+// Package localgit reads the git checkout the dashboard is started in and parses its
+// Azure DevOps remote, using the git command line.
+package localgit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ErrNotRepository is returned when the directory is not inside a git checkout
+var ErrNotRepository = errors.New("not a git repository")
+
+// ErrNoAzureRemote is returned when no remote of the checkout points to Azure DevOps
+var ErrNoAzureRemote = errors.New("no Azure DevOps remote")
+
+// Repository is a local git checkout with an Azure DevOps remote
+type Repository struct {
+	Root       string // Top-level directory of the checkout
+	Branch     string // Checked out branch, e.g. "feature/refunds"; empty when HEAD is detached
+	RemoteName string // e.g. "origin"
+	Remote     Remote
+
+	// Last commit on HEAD
+	LastCommitSubject string
+	LastCommitBody    string
+}
+
+// BranchRef returns the full ref of the checked out branch, e.g. "refs/heads/feature/refunds"
+func (r *Repository) BranchRef() string {
+	if r.Branch == "" {
+		return ""
+	}
+	return "refs/heads/" + r.Branch
+}
+
+// Detect reads the git checkout containing dir. It prefers the remote the checked out branch
+// tracks, then "origin", then any other remote pointing to Azure DevOps.
+func Detect(ctx context.Context, dir string) (*Repository, error) {
+	root, err := git(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, ErrNotRepository
+	}
+	repo := &Repository{Root: root}
+
+	// Fails on a detached HEAD, which leaves the branch empty
+	if branch, err := git(ctx, dir, "symbolic-ref", "--quiet", "--short", "HEAD"); err == nil {
+		repo.Branch = branch
+	}
+
+	names, err := git(ctx, dir, "remote")
+	if err != nil {
+		return nil, err
+	}
+	candidates := []string{"origin"}
+	if repo.Branch != "" {
+		if tracked, err := git(ctx, dir, "config", "--get", "branch."+repo.Branch+".remote"); err == nil {
+			candidates = append([]string{tracked}, candidates...)
+		}
+	}
+	candidates = append(candidates, strings.Fields(names)...)
+
+	for _, name := range candidates {
+		rawURL, err := git(ctx, dir, "remote", "get-url", name)
+		if err != nil {
+			continue
+		}
+		if remote, err := ParseRemote(rawURL); err == nil {
+			repo.RemoteName = name
+			repo.Remote = remote
+			break
+		}
+	}
+	if repo.RemoteName == "" {
+		return nil, ErrNoAzureRemote
+	}
+
+	// A new repository has no commits yet
+	if message, err := git(ctx, dir, "log", "-1", "--format=%B"); err == nil {
+		subject, body, _ := strings.Cut(message, "\n")
+		repo.LastCommitSubject = strings.TrimSpace(subject)
+		repo.LastCommitBody = strings.TrimSpace(body)
+	}
+
+	return repo, nil
+}
+
+// git runs a git command in dir and returns its trimmed output
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			return "", fmt.Errorf("git %s failed: %w", args[0], err)
+		}
+		return "", fmt.Errorf("git %s failed: %w: %s", args[0], err, msg)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,57 @@
+package localgit
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	// Keep the user's and system git config, e.g. commit signing or url rewrites, out of the test
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(cmd.Environ(), "GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+
+	ctx := context.Background()
+	if _, err := Detect(ctx, dir); err != ErrNotRepository {
+		t.Fatalf("Detect outside a repository: %v, want ErrNotRepository", err)
+	}
+
+	run("init", "--quiet", "--initial-branch", "feature/refunds")
+	run("remote", "add", "upstream", "https://github.com/contoso/payments-api.git")
+	if _, err := Detect(ctx, dir); err != ErrNoAzureRemote {
+		t.Fatalf("Detect without an Azure DevOps remote: %v, want ErrNoAzureRemote", err)
+	}
+
+	run("remote", "add", "origin", "git@ssh.dev.azure.com:v3/contoso/Payments/payments-api")
+	run("commit", "--quiet", "--allow-empty", "-m", "Add refunds endpoint\n\nSupports partial refunds.")
+
+	repo, err := Detect(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.Branch != "feature/refunds" || repo.BranchRef() != "refs/heads/feature/refunds" {
+		t.Errorf("branch = %q (%q)", repo.Branch, repo.BranchRef())
+	}
+	if repo.RemoteName != "origin" || repo.Remote.Repository != "payments-api" {
+		t.Errorf("remote = %s %+v", repo.RemoteName, repo.Remote)
+	}
+	if repo.LastCommitSubject != "Add refunds endpoint" || repo.LastCommitBody != "Supports partial refunds." {
+		t.Errorf("last commit = %q / %q", repo.LastCommitSubject, repo.LastCommitBody)
+	}
+}
@@ -0,0 +1,216 @@
+package localgit
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Remote is an Azure DevOps git remote
+type Remote struct {
+	// Azure DevOps Server over HTTP(S): "http" or "https"; empty for Services and SSH remotes,
+	// whose web URL uses https
+	Scheme string
+	// dev.azure.com for Azure DevOps Services, the server host otherwise, including the port
+	// of HTTP(S) remotes on a non-default one, e.g. "tfs.example.com:8080"
+	Host         string
+	Organization string // Organization, or the collection on Azure DevOps Server
+	Collection   string // Azure DevOps Server: path of the collection, e.g. "tfs/DefaultCollection"
+	Project      string
+	Repository   string
+}
+
+// cloudHost is the host of Azure DevOps Services organizations
+const cloudHost = "dev.azure.com"
+
+// IsCloud returns true if the remote is hosted on Azure DevOps Services
+func (r Remote) IsCloud() bool {
+	return r.Host == cloudHost
+}
+
+// CollectionURL returns the API URL of the organization or collection of the remote,
+// e.g. "https://dev.azure.com/contoso" or "http://tfs.example.com:8080/tfs/DefaultCollection"
+func (r Remote) CollectionURL() string {
+	if r.IsCloud() {
+		return "https://" + cloudHost + "/" + r.Organization
+	}
+	scheme := r.Scheme
+	if scheme == "" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + "/" + r.Collection
+}
+
+// MatchesOrganization returns true if the remote belongs to the organization or collection
+// with the given API URL, e.g. "https://dev.azure.com/contoso"
+func (r Remote) MatchesOrganization(organizationURL string) bool {
+	u, err := url.Parse(strings.TrimRight(organizationURL, "/"))
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	path := strings.Trim(u.Path, "/")
+
+	if r.IsCloud() {
+		if org, ok := strings.CutSuffix(host, ".visualstudio.com"); ok {
+			return strings.EqualFold(org, r.Organization)
+		}
+		return host == cloudHost && strings.EqualFold(path, r.Organization)
+	}
+	if !strings.EqualFold(path, r.Collection) {
+		return false
+	}
+	// The port of an SSH remote is not the one of the web server
+	if r.Scheme == "" {
+		return host == r.Host
+	}
+	return strings.EqualFold(u.Scheme, r.Scheme) && hostPort(u) == r.Host
+}
+
+// ParseRemote parses the URL of an Azure DevOps git remote. It accepts HTTPS and SSH URLs of
+// Azure DevOps Services (dev.azure.com and *.visualstudio.com) and of Azure DevOps Server
+// ({collection}/{project}/_git/{repository}).
+func ParseRemote(rawURL string) (Remote, error) {
+	rawURL = strings.TrimSpace(rawURL)
+
+	// SCP-like SSH URLs: git@ssh.dev.azure.com:v3/{org}/{project}/{repo}
+	if !strings.Contains(rawURL, "://") {
+		if at := strings.Index(rawURL, "@"); at >= 0 {
+			if host, path, ok := strings.Cut(rawURL[at+1:], ":"); ok {
+				return parseSSH(host, path, rawURL)
+			}
+		}
+		return Remote{}, fmt.Errorf("not an Azure DevOps remote: %s", rawURL)
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return Remote{}, fmt.Errorf("invalid remote URL %q: %w", rawURL, err)
+	}
+	if u.Scheme == "ssh" {
+		return parseSSH(u.Hostname(), u.Path, rawURL)
+	}
+	return parseHTTPS(u, rawURL)
+}
+
+// parseSSH parses the host and path of an SSH remote: v3/{org}/{project}/{repo}
+func parseSSH(host, path, rawURL string) (Remote, error) {
+	host = strings.ToLower(host)
+	if host != "ssh."+cloudHost && !strings.HasSuffix(host, "vs-ssh.visualstudio.com") {
+		// Azure DevOps Server SSH remotes use the same path layout as HTTPS ones
+		return parsePath(strings.ToLower(host), strings.Trim(path, "/"), rawURL)
+	}
+
+	parts := splitPath(path)
+	if len(parts) != 4 || parts[0] != "v3" {
+		return Remote{}, fmt.Errorf("not an Azure DevOps remote: %s", rawURL)
+	}
+	return Remote{Host: cloudHost, Organization: parts[1], Project: parts[2], Repository: parts[3]}, nil
+}
+
+// parseHTTPS parses an HTTP(S) remote of Azure DevOps Services or Server
+func parseHTTPS(u *url.URL, rawURL string) (Remote, error) {
+	host := strings.ToLower(u.Hostname())
+	path := strings.Trim(u.Path, "/")
+
+	switch {
+	case host == cloudHost:
+		// dev.azure.com/{org}/{project}/_git/{repo}
+		org, rest, ok := strings.Cut(path, "/")
+		if !ok {
+			return Remote{}, fmt.Errorf("not an Azure DevOps remote: %s", rawURL)
+		}
+		r, err := parseGitPath(rest, rawURL)
+		if err != nil {
+			return Remote{}, err
+		}
+		r.Host, r.Organization = cloudHost, unescape(org)
+		return r, nil
+
+	case strings.HasSuffix(host, ".visualstudio.com"):
+		// {org}.visualstudio.com[/DefaultCollection]/{project}/_git/{repo}
+		path = strings.TrimPrefix(path, "DefaultCollection/")
+		r, err := parseGitPath(path, rawURL)
+		if err != nil {
+			return Remote{}, err
+		}
+		r.Host, r.Organization = cloudHost, strings.TrimSuffix(host, ".visualstudio.com")
+		return r, nil
+
+	default:
+		r, err := parsePath(hostPort(u), path, rawURL)
+		if err != nil {
+			return Remote{}, err
+		}
+		r.Scheme = strings.ToLower(u.Scheme)
+		return r, nil
+	}
+}
+
+// hostPort returns the lowercase host of a URL with its port, unless it is the default one
+// of the scheme
+func hostPort(u *url.URL) string {
+	host, port := strings.ToLower(u.Hostname()), u.Port()
+	switch {
+	case port == "",
+		port == "443" && strings.EqualFold(u.Scheme, "https"),
+		port == "80" && strings.EqualFold(u.Scheme, "http"):
+		return host
+	default:
+		return host + ":" + port
+	}
+}
+
+// parsePath parses an Azure DevOps Server path: {collection path}/{project}/_git/{repo}
+func parsePath(host, path, rawURL string) (Remote, error) {
+	idx := strings.Index(path, "/_git/")
+	if idx < 0 {
+		return Remote{}, fmt.Errorf("not an Azure DevOps remote: %s", rawURL)
+	}
+	// The project is the last segment before _git and the collection everything before it
+	before := splitPath(path[:idx])
+	if len(before) < 2 {
+		return Remote{}, fmt.Errorf("not an Azure DevOps remote: %s", rawURL)
+	}
+	collection := strings.Join(before[:len(before)-1], "/")
+	r, err := parseGitPath(before[len(before)-1]+path[idx:], rawURL)
+	if err != nil {
+		return Remote{}, err
+	}
+	r.Host, r.Collection = host, collection
+	r.Organization = unescape(before[len(before)-2])
+	return r, nil
+}
+
+// parseGitPath parses "{project}/_git/{repo}" or "_git/{repo}", where the project is named like the repository
+func parseGitPath(path, rawURL string) (Remote, error) {
+	parts := splitPath(path)
+	switch {
+	case len(parts) == 3 && parts[1] == "_git":
+		return Remote{Project: unescape(parts[0]), Repository: unescape(parts[2])}, nil
+	case len(parts) == 2 && parts[0] == "_git":
+		repo := unescape(parts[1])
+		return Remote{Project: repo, Repository: repo}, nil
+	default:
+		return Remote{}, fmt.Errorf("not an Azure DevOps remote: %s", rawURL)
+	}
+}
+
+// splitPath splits a URL path into its non-empty segments
+func splitPath(path string) []string {
+	var parts []string
+	for _, p := range strings.Split(path, "/") {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return parts
+}
+
+// unescape decodes percent-encoded names such as "My%20Project"
+func unescape(s string) string {
+	if decoded, err := url.PathUnescape(s); err == nil {
+		return decoded
+	}
+	return s
+}
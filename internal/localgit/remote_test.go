@@ -0,0 +1,132 @@
+package localgit
+
+import "testing"
+
+func TestParseRemote(t *testing.T) {
+	tests := []struct {
+		url  string
+		want Remote
+	}{
+		{
+			url:  "https://dev.azure.com/contoso/Payments/_git/payments-api",
+			want: Remote{Host: "dev.azure.com", Organization: "contoso", Project: "Payments", Repository: "payments-api"},
+		},
+		{
+			url:  "https://contoso@dev.azure.com/contoso/My%20Project/_git/payments-api",
+			want: Remote{Host: "dev.azure.com", Organization: "contoso", Project: "My Project", Repository: "payments-api"},
+		},
+		{
+			url:  "https://dev.azure.com/contoso/_git/Payments",
+			want: Remote{Host: "dev.azure.com", Organization: "contoso", Project: "Payments", Repository: "Payments"},
+		},
+		{
+			url:  "https://contoso.visualstudio.com/DefaultCollection/Payments/_git/payments-api",
+			want: Remote{Host: "dev.azure.com", Organization: "contoso", Project: "Payments", Repository: "payments-api"},
+		},
+		{
+			url:  "git@ssh.dev.azure.com:v3/contoso/Payments/payments-api",
+			want: Remote{Host: "dev.azure.com", Organization: "contoso", Project: "Payments", Repository: "payments-api"},
+		},
+		{
+			url:  "contoso@vs-ssh.visualstudio.com:v3/contoso/Payments/payments-api",
+			want: Remote{Host: "dev.azure.com", Organization: "contoso", Project: "Payments", Repository: "payments-api"},
+		},
+		{
+			url: "https://tfs.example.com/tfs/DefaultCollection/Payments/_git/payments-api",
+			want: Remote{Scheme: "https", Host: "tfs.example.com", Organization: "DefaultCollection", Collection: "tfs/DefaultCollection",
+				Project: "Payments", Repository: "payments-api"},
+		},
+		{
+			url: "http://TFS:8080/tfs/Collection/Payments/_git/payments-api",
+			want: Remote{Scheme: "http", Host: "tfs:8080", Organization: "Collection", Collection: "tfs/Collection",
+				Project: "Payments", Repository: "payments-api"},
+		},
+		{
+			url: "https://tfs.example.com:443/DefaultCollection/Payments/_git/payments-api",
+			want: Remote{Scheme: "https", Host: "tfs.example.com", Organization: "DefaultCollection", Collection: "DefaultCollection",
+				Project: "Payments", Repository: "payments-api"},
+		},
+		{
+			url: "ssh://tfs.example.com:22/tfs/DefaultCollection/Payments/_git/payments-api",
+			want: Remote{Host: "tfs.example.com", Organization: "DefaultCollection", Collection: "tfs/DefaultCollection",
+				Project: "Payments", Repository: "payments-api"},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseRemote(tt.url)
+		if err != nil {
+			t.Errorf("ParseRemote(%q) error: %v", tt.url, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseRemote(%q) = %+v, want %+v", tt.url, got, tt.want)
+		}
+	}
+
+	for _, url := range []string{
+		"https://github.com/contoso/payments-api.git",
+		"git@github.com:contoso/payments-api.git",
+		"https://dev.azure.com/contoso",
+		"/srv/git/payments-api",
+	} {
+		if _, err := ParseRemote(url); err == nil {
+			t.Errorf("ParseRemote(%q) should fail", url)
+		}
+	}
+}
+
+func TestMatchesOrganization(t *testing.T) {
+	cloud := Remote{Host: "dev.azure.com", Organization: "contoso"}
+	server := Remote{Host: "tfs.example.com", Organization: "DefaultCollection", Collection: "tfs/DefaultCollection"}
+	http := Remote{Scheme: "http", Host: "tfs:8080", Organization: "Collection", Collection: "tfs/Collection"}
+
+	tests := []struct {
+		remote Remote
+		url    string
+		want   bool
+	}{
+		{cloud, "https://dev.azure.com/contoso", true},
+		{cloud, "https://dev.azure.com/Contoso/", true},
+		{cloud, "https://contoso.visualstudio.com", true},
+		{cloud, "https://dev.azure.com/fabrikam", false},
+		{server, "https://tfs.example.com/tfs/DefaultCollection", true},
+		{server, "https://tfs.example.com/tfs/OtherCollection", false},
+		{server, "https://dev.azure.com/DefaultCollection", false},
+		{http, "http://tfs:8080/tfs/Collection", true},
+		{http, "http://tfs:8080/tfs/Collection/", true},
+		{http, "http://tfs/tfs/Collection", false},
+		{http, "https://tfs:8080/tfs/Collection", false},
+	}
+	for _, tt := range tests {
+		if got := tt.remote.MatchesOrganization(tt.url); got != tt.want {
+			t.Errorf("%+v.MatchesOrganization(%q) = %v, want %v", tt.remote, tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestCollectionURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://dev.azure.com/contoso/Payments/_git/payments-api", "https://dev.azure.com/contoso"},
+		{"https://contoso.visualstudio.com/DefaultCollection/Payments/_git/payments-api", "https://dev.azure.com/contoso"},
+		{"https://tfs.example.com/tfs/DefaultCollection/Payments/_git/payments-api", "https://tfs.example.com/tfs/DefaultCollection"},
+		{"http://tfs:8080/tfs/Collection/Payments/_git/payments-api", "http://tfs:8080/tfs/Collection"},
+		{"ssh://tfs.example.com:22/tfs/DefaultCollection/Payments/_git/payments-api", "https://tfs.example.com/tfs/DefaultCollection"},
+	}
+
+	for _, tt := range tests {
+		remote, err := ParseRemote(tt.url)
+		if err != nil {
+			t.Fatalf("ParseRemote(%q): %v", tt.url, err)
+		}
+		if got := remote.CollectionURL(); got != tt.want {
+			t.Errorf("CollectionURL() of %q = %q, want %q", tt.url, got, tt.want)
+		}
+		if !remote.MatchesOrganization(tt.want) {
+			t.Errorf("remote %q does not match its own collection URL %q", tt.url, tt.want)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+// Package shell runs user-configured command lines, such as credential and token
+// commands, through the system shell.
+package shell
+
+import (
+	"context"
+	"os/exec"
+	"runtime"
+)
+
+// Command creates a command that runs the given command line through the system shell
+func Command(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
@@ -128,6 +128,8 @@ func GetPullRequestStatusStyle(status string) lipgloss.Style {
 		return lipgloss.NewStyle().Foreground(ColorDimGray)
 	case "conflicts":
 		return FailedStyle
+	case "autocomplete":
+		return SucceededStyle.Bold(false)
 	default:
 		return lipgloss.NewStyle().Foreground(ColorGray)
 	}
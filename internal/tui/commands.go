@@ -2,92 +2,196 @@ package tui
 
 import (
 	"context"
+	"errors"
 	"os/exec"
 	"runtime"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/polakv93/azure_devops_tui_dashboard/internal/api"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/cache"
 	"github.com/polakv93/azure_devops_tui_dashboard/internal/config"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/discovery"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/doctor"
 )
 
-// fetchBuilds creates a command to fetch builds for a project
-func fetchBuilds(client *api.Client, project config.ProjectConfig, maxItems int) tea.Cmd {
+// fetchBuilds creates a command to fetch builds for a project. While the current branch
+// filter is on for the project, only the builds of that branch are fetched.
+func fetchBuilds(client api.Service, project config.ProjectConfig, branch branchFilter, maxItems int) tea.Cmd {
+	if branch.appliesTo(project) {
+		project.Branches = []string{branch.Ref}
+	} else {
+		branch = branchFilter{}
+	}
+
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 
+		// Definition patterns that match nothing select no builds rather than all of them
+		if len(project.BuildSelectors) > 0 && len(project.BuildDefinitions) == 0 {
+			return BuildsLoadedMsg{Project: project.Key(), Branch: branch}
+		}
+
 		builds, err := client.GetBuilds(ctx, project.Name, project.BuildDefinitions, project.Branches, maxItems)
 		if err != nil {
 			return BuildsLoadedMsg{
-				Project: project.Name,
+				Project: project.Key(),
+				Branch:  branch,
 				Err:     err,
 			}
 		}
 
 		return BuildsLoadedMsg{
-			Project: project.Name,
+			Project: project.Key(),
+			Branch:  branch,
 			Builds:  builds,
 		}
 	}
 }
 
 // fetchReleases creates a command to fetch releases for a project
-func fetchReleases(client *api.Client, project config.ProjectConfig, maxItems int) tea.Cmd {
+func fetchReleases(client api.Service, project config.ProjectConfig, maxItems int) tea.Cmd {
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 
+		if len(project.ReleaseSelectors) > 0 && len(project.ReleaseDefinitions) == 0 {
+			return ReleasesLoadedMsg{Project: project.Key()}
+		}
+
 		releases, err := client.GetReleases(ctx, project.Name, project.ReleaseDefinitions, maxItems)
 		if err != nil {
 			return ReleasesLoadedMsg{
-				Project: project.Name,
+				Project: project.Key(),
 				Err:     err,
 			}
 		}
 
 		return ReleasesLoadedMsg{
-			Project:  project.Name,
+			Project:  project.Key(),
 			Releases: releases,
 		}
 	}
 }
 
-// fetchPullRequests creates a command to fetch pull requests for a project
-func fetchPullRequests(client *api.Client, project config.ProjectConfig, maxItems int) tea.Cmd {
+// fetchPullRequests creates a command to fetch pull requests for a project.
+// The signed-in user is looked up through users when a filter refers to it.
+func fetchPullRequests(client api.Service, project config.ProjectConfig, filter pullRequestFilter, users *identities, maxItems int) tea.Cmd {
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 
-		pullRequests, err := client.GetPullRequests(ctx, project.Name, project.Repositories, maxItems)
+		var userID string
+		if filter.needsUser() {
+			var err error
+			if userID, err = users.userID(ctx, client, project.Organization); err != nil {
+				return PullRequestsLoadedMsg{
+					Project: project.Key(),
+					Filter:  filter.forProject(project.Key()),
+					Err:     err,
+				}
+			}
+		}
+
+		pullRequests, err := client.GetPullRequests(ctx, project.Name, project.Repositories,
+			filter.criteria(project, userID), maxItems)
 		if err != nil {
 			return PullRequestsLoadedMsg{
-				Project: project.Name,
+				Project: project.Key(),
+				Filter:  filter.forProject(project.Key()),
 				Err:     err,
 			}
 		}
 
 		return PullRequestsLoadedMsg{
-			Project:      project.Name,
+			Project:      project.Key(),
+			Filter:       filter.forProject(project.Key()),
 			PullRequests: pullRequests,
 		}
 	}
 }
 
 // fetchAllData creates commands to fetch all builds, releases, and pull requests
-func fetchAllData(client *api.Client, projects []config.ProjectConfig, maxItems int) tea.Cmd {
+func fetchAllData(clients Clients, projects []config.ProjectConfig, prFilter pullRequestFilter, users *identities, maxItems int) tea.Cmd {
 	var cmds []tea.Cmd
 
 	for _, project := range projects {
 		p := project // capture loop variable
-		cmds = append(cmds, fetchBuilds(client, p, maxItems))
+		client := clients[p.Organization]
+		cmds = append(cmds, fetchBuilds(client, p, prFilter.Branch, maxItems))
 		cmds = append(cmds, fetchReleases(client, p, maxItems))
-		cmds = append(cmds, fetchPullRequests(client, p, maxItems))
+		cmds = append(cmds, fetchPullRequests(client, p, prFilter, users, maxItems))
 	}
 
 	return tea.Batch(cmds...)
 }
 
+// runPreflight creates commands that check the connection, credentials and configured
+// projects of every organization
+func runPreflight(clients Clients, projects []config.ProjectConfig) tea.Cmd {
+	byOrganization := make(map[string][]config.ProjectConfig)
+	for _, p := range projects {
+		byOrganization[p.Organization] = append(byOrganization[p.Organization], p)
+	}
+
+	var cmds []tea.Cmd
+	for organization, client := range clients {
+		organization, client := organization, client
+		cmds = append(cmds, func() tea.Msg {
+			ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+			defer cancel()
+
+			return PreflightMsg{
+				Organization: organization,
+				Report:       doctor.Run(ctx, client, byOrganization[organization]),
+			}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// resolveDefinitions creates a command that looks up the definitions selected by name,
+// glob or folder again, so newly created pipelines are picked up
+func resolveDefinitions(clients Clients, projects []config.ProjectConfig) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		defer cancel()
+
+		resolved := make(map[string]discovery.Resolution)
+		var errs []error
+		for _, p := range projects {
+			if !p.HasDefinitionPatterns() {
+				continue
+			}
+			// Keep the previous IDs of projects whose definitions cannot be listed right now
+			res, err := discovery.ResolveDefinitions(ctx, clients[p.Organization], p)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			resolved[p.Key()] = res
+		}
+		return DefinitionsResolvedMsg{Resolved: resolved, Err: errors.Join(errs...)}
+	}
+}
+
+// definitionsTicker creates a command that triggers the next definition lookup
+func definitionsTicker(interval time.Duration) tea.Cmd {
+	return tea.Tick(interval, func(t time.Time) tea.Msg {
+		return DefinitionsTickMsg{}
+	})
+}
+
+// writeSnapshot creates a command that stores the encoded snapshot on disk
+func writeSnapshot(path string, data []byte) tea.Cmd {
+	return func() tea.Msg {
+		// The snapshot is a best-effort cache, so write errors are ignored
+		_ = cache.Write(path, data)
+		return nil
+	}
+}
+
 // refreshTicker creates a command that ticks at the specified interval
 func refreshTicker(interval time.Duration) tea.Cmd {
 	return tea.Tick(interval, func(t time.Time) tea.Msg {
@@ -0,0 +1,253 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/api"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/config"
+	"golang.org/x/sync/singleflight"
+)
+
+// pullRequestFilter holds the pull request filters, toggled with hotkeys in the Pull Requests section
+type pullRequestFilter struct {
+	Status           api.PullRequestStatus // active, completed, abandoned or all
+	CreatedByMe      bool                  // Only pull requests created by the signed-in user
+	AwaitingMyReview bool                  // Only pull requests with the signed-in user as a reviewer
+	HideDrafts       bool                  // Leave out draft pull requests
+	AllTargets       bool                  // Ignore the target_branches of the project
+
+	// "Current branch" pseudo-filter, zero when off. It also narrows the builds of the project.
+	Branch branchFilter
+}
+
+// branchFilter limits a project to the branch checked out in the local git checkout
+type branchFilter struct {
+	Project string // Project key (see config.ProjectConfig.Key)
+	Ref     string // e.g. "refs/heads/feature/refunds"
+}
+
+// appliesTo returns true if the branch filter is on for the project
+func (b branchFilter) appliesTo(project config.ProjectConfig) bool {
+	return b.Ref != "" && b.Project == project.Key()
+}
+
+// forProject returns the filters that apply to a project, without the branch filter of
+// another project
+func (f pullRequestFilter) forProject(key string) pullRequestFilter {
+	if f.Branch.Project != key {
+		f.Branch = branchFilter{}
+	}
+	return f
+}
+
+// pullRequestStatuses is the order in which the status filter cycles
+var pullRequestStatuses = []api.PullRequestStatus{
+	api.PullRequestStatusActive,
+	api.PullRequestStatusCompleted,
+	api.PullRequestStatusAbandoned,
+	api.PullRequestStatusAll,
+}
+
+// nextStatus returns the status following the current one in the cycle
+func (f pullRequestFilter) nextStatus() api.PullRequestStatus {
+	for i, s := range pullRequestStatuses {
+		if s == f.Status {
+			return pullRequestStatuses[(i+1)%len(pullRequestStatuses)]
+		}
+	}
+	return api.PullRequestStatusActive
+}
+
+// showsClosed returns true if the list can contain completed or abandoned pull requests
+func (f pullRequestFilter) showsClosed() bool {
+	return f.Status != api.PullRequestStatusActive
+}
+
+// newPullRequestFilter returns the filters configured for startup
+func newPullRequestFilter(cfg config.PullRequestsConfig) pullRequestFilter {
+	status := api.PullRequestStatus(cfg.Status)
+	if status == "" {
+		status = api.PullRequestStatusActive
+	}
+	return pullRequestFilter{
+		Status:           status,
+		CreatedByMe:      cfg.CreatedByMe,
+		AwaitingMyReview: cfg.AwaitingMyReview,
+		HideDrafts:       cfg.HideDrafts,
+	}
+}
+
+// needsUser returns true if the filters refer to the signed-in user
+func (f pullRequestFilter) needsUser() bool {
+	return f.CreatedByMe || f.AwaitingMyReview
+}
+
+// criteria returns the API search criteria for a project; userID is the signed-in user
+func (f pullRequestFilter) criteria(project config.ProjectConfig, userID string) api.PullRequestCriteria {
+	c := api.PullRequestCriteria{Status: f.Status, ExcludeDrafts: f.HideDrafts}
+	if !f.AllTargets {
+		c.TargetBranches = project.TargetBranches
+	}
+	if f.CreatedByMe {
+		c.CreatorID = userID
+	}
+	if f.AwaitingMyReview {
+		c.ReviewerID = userID
+	}
+	if f.Branch.appliesTo(project) {
+		c.SourceBranch = f.Branch.Ref
+	}
+	return c
+}
+
+// describe returns the active filters for the section header, e.g. "mine, no drafts"
+func (f pullRequestFilter) describe(project config.ProjectConfig) string {
+	var parts []string
+	if f.showsClosed() {
+		parts = append(parts, string(f.Status))
+	}
+	if f.CreatedByMe {
+		parts = append(parts, "mine")
+	}
+	if f.AwaitingMyReview {
+		parts = append(parts, "awaiting my review")
+	}
+	if len(project.TargetBranches) > 0 && !f.AllTargets {
+		parts = append(parts, "target: "+strings.Join(project.TargetBranches, ", "))
+	}
+	if f.HideDrafts {
+		parts = append(parts, "no drafts")
+	}
+	if f.Branch.appliesTo(project) {
+		parts = append(parts, "current branch: "+strings.TrimPrefix(f.Branch.Ref, "refs/heads/"))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// identities caches the ID of the signed-in user per organization. It is shared by the
+// fetch commands, so connectionData is requested once per organization.
+type identities struct {
+	mu      sync.Mutex
+	ids     map[string]string  // organization -> user ID
+	lookups singleflight.Group // connectionData requests in flight, keyed by organization
+}
+
+// newIdentities creates an empty identity cache
+func newIdentities() *identities {
+	return &identities{ids: make(map[string]string)}
+}
+
+// userID returns the ID of the signed-in user, requesting connectionData on first use.
+// The lock is not held during the request, so cached never waits for the network.
+func (c *identities) userID(ctx context.Context, client api.Service, organization string) (string, error) {
+	if id := c.cached(organization); id != "" {
+		return id, nil
+	}
+
+	// Concurrent fetches share one request; a failed lookup is tried again by the next fetch
+	id, err, _ := c.lookups.Do(organization, func() (any, error) {
+		id, err := fetchUserID(ctx, client)
+		if err != nil {
+			return "", err
+		}
+		c.mu.Lock()
+		c.ids[organization] = id
+		c.mu.Unlock()
+		return id, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return id.(string), nil
+}
+
+// fetchUserID requests the ID of the signed-in user from connectionData
+func fetchUserID(ctx context.Context, client api.Service) (string, error) {
+	data, err := client.GetConnectionData(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to identify the signed-in user: %w", err)
+	}
+	if data.AuthenticatedUser.IsAnonymous() || data.AuthenticatedUser.ID == "" {
+		return "", fmt.Errorf("failed to identify the signed-in user: not authenticated")
+	}
+	return data.AuthenticatedUser.ID, nil
+}
+
+// cached returns the ID of the signed-in user if it has been resolved
+func (c *identities) cached(organization string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ids[organization]
+}
+
+// isStartupFilter returns true if the filters are the configured ones. Only lists fetched
+// with them are cached in the snapshot, which is shown before the first fetch.
+func (m Model) isStartupFilter() bool {
+	return m.prFilter == newPullRequestFilter(m.config.PullRequests)
+}
+
+// toggleFilter applies a change to the pull request filters and fetches the pull requests again
+func (m Model) toggleFilter(change func(*pullRequestFilter)) (tea.Model, tea.Cmd) {
+	change(&m.prFilter)
+	m.selectedRow = 0
+
+	var cmds []tea.Cmd
+	for _, p := range m.config.Projects {
+		cmds = append(cmds, m.refetchPullRequests(p))
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// refetchPullRequests drops the pull requests of a project, which no longer match the filters,
+// and fetches them again
+func (m Model) refetchPullRequests(project config.ProjectConfig) tea.Cmd {
+	key := project.Key()
+	delete(m.pullRequests, key)
+	delete(m.errors, key+"-pullrequests")
+	delete(m.stale, key+"-pullrequests")
+	m.loadingPullRequests[key] = true
+	return fetchPullRequests(m.clientFor(project), project, m.prFilter, m.users, m.config.Display.MaxItemsPerProject)
+}
+
+// toggleCurrentBranch turns the "current branch" filter on or off. Turning it on switches to the
+// project of the local checkout and shows only the builds and pull requests of its branch.
+func (m Model) toggleCurrentBranch() (tea.Model, tea.Cmd) {
+	project, ok := m.localProject()
+	if !ok || m.local.Branch == "" {
+		return m, nil
+	}
+
+	branch := branchFilter{}
+	if !m.prFilter.Branch.appliesTo(project) {
+		branch = branchFilter{Project: project.Key(), Ref: m.local.BranchRef()}
+		m.activeProject, _ = m.projectIndex(project)
+	}
+
+	m.prFilter.Branch = branch
+	m.selectedRow = 0
+
+	// Builds of the other branches no longer apply; other projects are not affected
+	key := project.Key()
+	delete(m.builds, key)
+	delete(m.errors, key+"-builds")
+	delete(m.stale, key+"-builds")
+	m.loadingBuilds[key] = true
+
+	return m, tea.Batch(m.refetchPullRequests(project),
+		fetchBuilds(m.clientFor(project), project, m.prFilter.Branch, m.config.Display.MaxItemsPerProject))
+}
+
+// pullRequestListed returns true if the pull request belongs in the list with the current filters
+func (m Model) pullRequestListed(project config.ProjectConfig, pr api.PullRequest) bool {
+	userID := m.users.cached(project.Organization)
+	// An empty creator or reviewer matches every pull request, so nothing is listed by the
+	// identity filters until the signed-in user is known
+	if m.prFilter.needsUser() && userID == "" {
+		return false
+	}
+	return m.prFilter.criteria(project, userID).Matches(pr)
+}
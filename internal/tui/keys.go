@@ -6,15 +6,42 @@ import (
 
 // KeyMap defines the keybindings for the application
 type KeyMap struct {
-	Up      key.Binding
-	Down    key.Binding
-	Left    key.Binding
-	Right   key.Binding
-	Tab     key.Binding
-	Enter   key.Binding
-	Refresh key.Binding
-	Help    key.Binding
-	Quit    key.Binding
+	Up       key.Binding
+	Down     key.Binding
+	Left     key.Binding
+	Right    key.Binding
+	Tab      key.Binding
+	Enter    key.Binding
+	Refresh  key.Binding
+	Projects key.Binding
+	Help     key.Binding
+	Quit     key.Binding
+
+	// "Current branch" filter of the local checkout, for builds and pull requests
+	CurrentBranch key.Binding
+
+	// Pull request filters, active in the Pull Requests section
+	FilterStatus key.Binding
+	FilterMine   key.Binding
+	FilterReview key.Binding
+	FilterTarget key.Binding
+	FilterDrafts key.Binding
+
+	// Pull request and release detail panes
+	Details key.Binding
+	Reply   key.Binding
+	Resolve key.Binding
+	Files   key.Binding
+
+	// Pull request completion form
+	Complete key.Binding
+
+	// Create a pull request from the checked out branch
+	NewPullRequest key.Binding
+
+	// Create a release of the definition of the selected release, or of a chosen definition
+	NewRelease   key.Binding
+	NewReleaseOf key.Binding
 }
 
 // DefaultKeyMap returns the default keybindings
@@ -48,6 +75,14 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("r"),
 			key.WithHelp("r", "refresh"),
 		),
+		Projects: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "add project"),
+		),
+		CurrentBranch: key.NewBinding(
+			key.WithKeys("b"),
+			key.WithHelp("b", "current branch only"),
+		),
 		Help: key.NewBinding(
 			key.WithKeys("?"),
 			key.WithHelp("?", "help"),
@@ -56,6 +91,58 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("q", "ctrl+c"),
 			key.WithHelp("q", "quit"),
 		),
+		FilterStatus: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "PRs: cycle status"),
+		),
+		FilterMine: key.NewBinding(
+			key.WithKeys("m"),
+			key.WithHelp("m", "PRs: mine"),
+		),
+		FilterReview: key.NewBinding(
+			key.WithKeys("v"),
+			key.WithHelp("v", "PRs: awaiting my review"),
+		),
+		FilterTarget: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "PRs: target branch filter"),
+		),
+		FilterDrafts: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "PRs: hide drafts"),
+		),
+		Details: key.NewBinding(
+			key.WithKeys("i"),
+			key.WithHelp("i", "PRs/releases: details"),
+		),
+		Reply: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "reply to thread"),
+		),
+		Resolve: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "resolve thread"),
+		),
+		Files: key.NewBinding(
+			key.WithKeys("f"),
+			key.WithHelp("f", "PRs: changed files and diffs"),
+		),
+		Complete: key.NewBinding(
+			key.WithKeys("C"),
+			key.WithHelp("C", "PRs: complete or auto-complete"),
+		),
+		NewPullRequest: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "PRs: create from local branch"),
+		),
+		NewRelease: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "Releases: create release"),
+		),
+		NewReleaseOf: key.NewBinding(
+			key.WithKeys("N"),
+			key.WithHelp("N", "Releases: create release of a chosen definition"),
+		),
 	}
 }
 
@@ -68,7 +155,8 @@ func (k KeyMap) ShortHelp() []key.Binding {
 func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Up, k.Down, k.Left, k.Right},
-		{k.Tab, k.Enter, k.Refresh},
+		{k.Tab, k.Enter, k.Refresh, k.Projects, k.CurrentBranch},
+		{k.FilterStatus, k.FilterMine, k.FilterReview, k.FilterTarget, k.FilterDrafts, k.Details, k.Files, k.Complete, k.NewPullRequest, k.NewRelease, k.NewReleaseOf},
 		{k.Help, k.Quit},
 	}
 }
@@ -2,13 +2,17 @@ package tui
 
 import (
 	"github.com/polakv93/azure_devops_tui_dashboard/internal/api"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/discovery"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/doctor"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/webhook"
 )
 
 // Message types for bubbletea
 
 // BuildsLoadedMsg is sent when builds have been fetched
 type BuildsLoadedMsg struct {
-	Project string
+	Project string       // Project key (see config.ProjectConfig.Key)
+	Branch  branchFilter // Current branch filter the builds were fetched with, zero when off
 	Builds  []api.Build
 	Err     error
 }
@@ -23,10 +27,31 @@ type ReleasesLoadedMsg struct {
 // PullRequestsLoadedMsg is sent when pull requests have been fetched
 type PullRequestsLoadedMsg struct {
 	Project      string
+	Filter       pullRequestFilter // Filters the pull requests were fetched with (see pullRequestFilter.forProject)
 	PullRequests []api.PullRequest
 	Err          error
 }
 
+// PreflightMsg is sent when the startup connection check has completed
+type PreflightMsg struct {
+	Organization string
+	Report       doctor.Report
+}
+
+// WebhookEventMsg is sent when a service hook event has been received
+type WebhookEventMsg struct {
+	Event webhook.Event
+}
+
+// DefinitionsResolvedMsg is sent when definition selectors have been resolved again
+type DefinitionsResolvedMsg struct {
+	Resolved map[string]discovery.Resolution // project key -> resolved definitions
+	Err      error                           // Lookups that failed, nil when every project was resolved
+}
+
+// DefinitionsTickMsg is sent by the definition lookup ticker
+type DefinitionsTickMsg struct{}
+
 // RefreshTickMsg is sent by the refresh ticker
 type RefreshTickMsg struct{}
 
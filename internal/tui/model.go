@@ -1,6 +1,9 @@
 package tui
 
 import (
+	"fmt"
+	"net/http"
+	"sort"
 	"strings"
 	"time"
 
@@ -9,7 +12,10 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/polakv93/azure_devops_tui_dashboard/internal/api"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/cache"
 	"github.com/polakv93/azure_devops_tui_dashboard/internal/config"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/doctor"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/localgit"
 )
 
 // Tab represents the active view tab
@@ -24,8 +30,8 @@ const (
 // Model is the main application model
 type Model struct {
 	// Configuration
-	config *config.Config
-	client *api.Client
+	config  *config.Config
+	clients Clients
 
 	// UI state
 	activeTab     Tab
@@ -36,9 +42,9 @@ type Model struct {
 	showHelp      bool
 
 	// Data
-	builds       map[string][]api.Build       // project name -> builds
-	releases     map[string][]api.Release     // project name -> releases
-	pullRequests map[string][]api.PullRequest // project name -> pull requests
+	builds       map[string][]api.Build       // project key -> builds
+	releases     map[string][]api.Release     // project key -> releases
+	pullRequests map[string][]api.PullRequest // project key -> pull requests
 
 	// Loading states
 	loadingBuilds       map[string]bool
@@ -51,14 +57,66 @@ type Model struct {
 	// Last refresh time
 	lastRefresh time.Time
 
+	// Project picker, nil when closed
+	picker    *projectPicker
+	pickerSeq int // Incremented each time the picker opens
+
+	// Pull request detail pane, nil when closed
+	detail *pullRequestDetail
+
+	// Changed files and diff pane, nil when closed; opened from the list or the detail pane
+	files *pullRequestFiles
+
+	// Pull request completion form, nil when closed
+	complete *completeForm
+
+	// Release detail pane with deployments and task logs, nil when closed
+	release *releaseDetail
+
+	// Form creating a release of the definition of the selected release or of a chosen
+	// definition, nil when closed
+	createRelease *releaseForm
+
+	// Git checkout the dashboard was started in, nil outside of an Azure DevOps checkout,
+	// and the form creating a pull request from its branch, nil when closed
+	local  *localgit.Repository
+	create *createForm
+
+	// Pull request filters and the signed-in user of each organization they refer to
+	prFilter pullRequestFilter
+	users    *identities
+
+	// Startup connection check of each organization, missing until it completes
+	preflight map[string]*doctor.Report
+
+	// Problems found before the dashboard started, e.g. definition selectors that match
+	// nothing, and the error of the last definition lookup, nil once it succeeds
+	warnings       []string
+	definitionsErr error
+
+	// Error of the project discovery before the dashboard started and whether the projects of
+	// the failed organizations were restored from the snapshot
+	projectsErr      error
+	restoredProjects bool
+
+	// Persistent snapshot of the last fetched data, nil when caching is disabled
+	snapshot      *cache.Snapshot
+	snapshotPath  string
+	snapshotDirty bool
+	updatedAt     map[string]time.Time // data key (e.g. "org/project-builds") -> time the shown data was fetched
+	stale         map[string]bool      // data key -> shown data is from the snapshot or the last fetch failed
+
 	// Components
 	spinner spinner.Model
 	help    help.Model
 	keys    KeyMap
 }
 
-// NewModel creates a new Model with the given configuration
-func NewModel(cfg *config.Config) Model {
+// Clients holds one API client per organization, keyed by organization name
+type Clients map[string]api.Service
+
+// NewModel creates a new Model with the given configuration and API clients
+func NewModel(cfg *config.Config, clients Clients) Model {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFF00"))
@@ -66,9 +124,9 @@ func NewModel(cfg *config.Config) Model {
 	h := help.New()
 	h.ShowAll = false
 
-	return Model{
+	m := Model{
 		config:              cfg,
-		client:              newClientFromConfig(cfg),
+		clients:             clients,
 		activeTab:           TabBuilds,
 		activeProject:       0,
 		selectedRow:         0,
@@ -79,37 +137,286 @@ func NewModel(cfg *config.Config) Model {
 		loadingReleases:     make(map[string]bool),
 		loadingPullRequests: make(map[string]bool),
 		errors:              make(map[string]error),
+		preflight:           make(map[string]*doctor.Report),
+		prFilter:            newPullRequestFilter(cfg.PullRequests),
+		users:               newIdentities(),
+		updatedAt:           make(map[string]time.Time),
+		stale:               make(map[string]bool),
 		spinner:             s,
 		help:                h,
 		keys:                DefaultKeyMap(),
 	}
+
+	if cfg.Cache.IsEnabled() {
+		m.loadSnapshot()
+	}
+
+	return m
+}
+
+// AddWarnings adds problems found before the dashboard started; they are shown above the sections
+func (m *Model) AddWarnings(warnings ...string) {
+	m.warnings = append(m.warnings, warnings...)
+}
+
+// SetProjectsError sets the error of the project discovery done before the dashboard started.
+// Organizations whose projects could not be listed show the projects cached by the last run.
+func (m *Model) SetProjectsError(err error) {
+	m.projectsErr = err
+}
+
+// SetDefinitionsError sets the error of the definition lookup done before the dashboard started.
+// Projects whose definitions could not be listed show nothing until a later lookup succeeds;
+// the lookup is retried with every refresh while it fails.
+func (m *Model) SetDefinitionsError(err error) {
+	m.definitionsErr = err
+}
+
+// SetLocalRepository sets the git checkout the dashboard was started in and opens the
+// dashboard on its project. A project of a configured organization that is not listed in
+// the configuration is added to the session.
+func (m *Model) SetLocalRepository(repo *localgit.Repository) {
+	m.local = repo
+
+	if _, ok := m.localProject(); !ok {
+		for i, org := range m.config.Organizations {
+			client, ok := m.clients[org.Name]
+			if ok && repo.Remote.MatchesOrganization(client.GetOrganizationURL()) {
+				m.config.Organizations[i].Projects = append(m.config.Organizations[i].Projects,
+					config.ProjectConfig{Organization: org.Name, Name: repo.Remote.Project})
+				m.config.CollectProjects()
+				break
+			}
+		}
+	}
+	if project, ok := m.localProject(); ok {
+		m.activeProject, _ = m.projectIndex(project)
+	}
+}
+
+// localProject returns the configured project the remote of the local checkout belongs to
+func (m Model) localProject() (config.ProjectConfig, bool) {
+	if m.local == nil {
+		return config.ProjectConfig{}, false
+	}
+	remote := m.local.Remote
+	for _, project := range m.config.Projects {
+		client := m.clientFor(project)
+		if client != nil && strings.EqualFold(project.Name, remote.Project) && remote.MatchesOrganization(client.GetOrganizationURL()) {
+			return project, true
+		}
+	}
+	return config.ProjectConfig{}, false
+}
+
+// loadSnapshot shows the cached data of every project as stale until fresh data arrives.
+// Cache problems are not fatal: the dashboard then simply starts empty.
+func (m *Model) loadSnapshot() {
+	path := m.config.Cache.Path
+	if path == "" {
+		var err error
+		path, err = cache.DefaultPath(m.organizationURLs())
+		if err != nil {
+			return
+		}
+	}
+
+	snapshot, err := cache.Load(path)
+	if err != nil {
+		snapshot = cache.New()
+	}
+	m.snapshot = snapshot
+	m.snapshotPath = path
+	m.restoreProjects(snapshot)
+
+	// Snapshots of a single organization used to be keyed by the project name alone
+	if len(m.organizations()) == 1 {
+		for _, project := range m.config.Projects {
+			snapshot.Rename(project.Name, project.Key())
+		}
+	}
+
+	for _, project := range m.config.Projects {
+		key := project.Key()
+		p, ok := snapshot.Projects[key]
+		if !ok {
+			continue
+		}
+		if len(p.Builds) > 0 {
+			m.builds[key] = p.GetBuilds()
+			m.updatedAt[key+"-builds"] = p.BuildsAt
+			m.stale[key+"-builds"] = true
+		}
+		if len(p.Releases) > 0 {
+			m.releases[key] = p.Releases
+			m.updatedAt[key+"-releases"] = p.ReleasesAt
+			m.stale[key+"-releases"] = true
+		}
+		if len(p.PullRequests) > 0 {
+			m.pullRequests[key] = p.GetPullRequests()
+			m.updatedAt[key+"-pullrequests"] = p.PullRequestsAt
+			m.stale[key+"-pullrequests"] = true
+		}
+	}
+}
+
+// restoreProjects adds the cached projects of organizations configured with "projects: auto"
+// whose projects could not be listed, so their last data is shown while offline
+func (m *Model) restoreProjects(snapshot *cache.Snapshot) {
+	for i := range m.config.Organizations {
+		org := &m.config.Organizations[i]
+		if !org.DiscoversProjects() || len(org.Projects) > 0 {
+			continue
+		}
+
+		var names []string
+		for key := range snapshot.Projects {
+			name, ok := strings.CutPrefix(key, org.Name+"/")
+			if ok && org.ProjectSource.Discovery.Matches(name) {
+				names = append(names, name)
+			}
+		}
+		sort.Slice(names, func(i, j int) bool {
+			return strings.ToLower(names[i]) < strings.ToLower(names[j])
+		})
+		for _, name := range names {
+			org.Projects = append(org.Projects, config.ProjectConfig{Organization: org.Name, Name: name})
+		}
+		m.restoredProjects = m.restoredProjects || len(names) > 0
+	}
+	if m.restoredProjects {
+		m.config.CollectProjects()
+	}
 }
 
-// newClientFromConfig creates an API client from the configuration
-func newClientFromConfig(cfg *config.Config) *api.Client {
+// organizationURLs returns the URLs of all organizations in configuration order, one per line.
+// Organizations whose projects could not be discovered are included, so they keep their snapshot.
+func (m Model) organizationURLs() string {
+	organizations := m.organizations()
+	if len(m.config.Organizations) > 0 {
+		organizations = nil
+		for _, org := range m.config.Organizations {
+			organizations = append(organizations, org.Name)
+		}
+	}
+
+	var urls []string
+	for _, organization := range organizations {
+		if client, ok := m.clients[organization]; ok {
+			urls = append(urls, client.GetOrganizationURL())
+		}
+	}
+	return strings.Join(urls, "\n")
+}
+
+// clientFor returns the API client of the organization a project belongs to
+func (m Model) clientFor(project config.ProjectConfig) api.Service {
+	return m.clients[project.Organization]
+}
+
+// ClientOptions holds command-line options that affect API traffic
+type ClientOptions struct {
+	RecordDir string // Save every API response to this directory
+	ReplayDir string // Serve API responses from this directory instead of the network
+}
+
+// NewClients creates an API client for every configured organization
+func NewClients(cfg *config.Config, opts ClientOptions) (Clients, error) {
+	clients := make(Clients, len(cfg.Organizations))
+	for _, org := range cfg.Organizations {
+		client, err := NewClientFromConfig(cfg, org, opts)
+		if err != nil {
+			if len(cfg.Organizations) > 1 {
+				return nil, fmt.Errorf("organization %q: %w", org.Name, err)
+			}
+			return nil, err
+		}
+		clients[org.Name] = client
+	}
+	return clients, nil
+}
+
+// NewClientFromConfig creates an API client for one organization
+func NewClientFromConfig(cfg *config.Config, org config.OrganizationConfig, opts ClientOptions) (*api.Client, error) {
+	if opts.RecordDir != "" && opts.ReplayDir != "" {
+		return nil, fmt.Errorf("record and replay cannot be used together")
+	}
+
+	var transport http.RoundTripper
+	auth := newAuthProvider(org.AzureDevOpsConfig)
+
+	if opts.ReplayDir != "" {
+		// Recordings carry no credentials, so skip real authentication
+		transport = &api.ReplayTransport{Dir: opts.ReplayDir}
+		auth = api.BearerTokenAuth{Token: "replay"}
+	} else {
+		httpTransport, err := api.NewTransport(api.TransportConfig{
+			ProxyURL:      cfg.HTTP.Proxy,
+			CABundle:      cfg.HTTP.CABundle,
+			ClientCert:    cfg.HTTP.ClientCert,
+			ClientKey:     cfg.HTTP.ClientKey,
+			TLSMinVersion: cfg.HTTP.TLSMinVersion,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure HTTP transport: %w", err)
+		}
+		transport = httpTransport
+
+		if opts.RecordDir != "" {
+			transport = &api.RecordingTransport{Dir: opts.RecordDir, Next: httpTransport}
+		}
+	}
+
 	return api.NewClient(api.ClientConfig{
-		Organization:      cfg.AzureDevOps.Organization,
-		BaseURL:           cfg.AzureDevOps.BaseURL,
-		PAT:               cfg.AzureDevOps.PAT,
+		Organization:      org.Organization,
+		BaseURL:           org.BaseURL,
+		CollectionURL:     org.CollectionURL,
+		ReleaseURL:        org.ReleaseBaseURL,
+		Server:            org.IsServer(),
+		APIVersion:        org.APIVersion,
+		Auth:              auth,
 		RequestsPerSecond: cfg.RateLimiting.RequestsPerSecond,
 		BurstSize:         cfg.RateLimiting.BurstSize,
-	})
+		Timeout:           cfg.HTTP.Timeout,
+		Transport:         transport,
+	}), nil
+}
+
+// newAuthProvider creates the API authentication provider selected in the configuration
+func newAuthProvider(cfg config.AzureDevOpsConfig) api.AuthProvider {
+	switch cfg.Auth.Type {
+	case config.AuthTypeBearer:
+		return api.BearerTokenAuth{Token: cfg.Auth.Token}
+	case config.AuthTypeCommand:
+		return api.NewCommandTokenAuth(cfg.Auth.Command)
+	default:
+		return api.PATAuth{PAT: cfg.PAT}
+	}
 }
 
 // Init initializes the model
 func (m Model) Init() tea.Cmd {
 	// Mark all projects as loading
 	for _, p := range m.config.Projects {
-		m.loadingBuilds[p.Name] = true
-		m.loadingReleases[p.Name] = true
-		m.loadingPullRequests[p.Name] = true
+		m.loadingBuilds[p.Key()] = true
+		m.loadingReleases[p.Key()] = true
+		m.loadingPullRequests[p.Key()] = true
 	}
 
-	return tea.Batch(
+	cmds := []tea.Cmd{
 		m.spinner.Tick,
-		fetchAllData(m.client, m.config.Projects, m.config.Display.MaxItemsPerProject),
-		refreshTicker(m.config.Display.RefreshInterval),
-	)
+		fetchAllData(m.clients, m.config.Projects, m.prFilter, m.users, m.config.Display.MaxItemsPerProject),
+		refreshTicker(m.config.RefreshInterval()),
+		runPreflight(m.clients, m.config.Projects),
+	}
+	for _, p := range m.config.Projects {
+		if p.HasDefinitionPatterns() {
+			cmds = append(cmds, definitionsTicker(m.config.Display.DefinitionRefreshInterval))
+			break
+		}
+	}
+
+	return tea.Batch(cmds...)
 }
 
 // CurrentProject returns the current active project config
@@ -122,25 +429,25 @@ func (m Model) CurrentProject() config.ProjectConfig {
 
 // CurrentBuilds returns the builds for the current project
 func (m Model) CurrentBuilds() []api.Build {
-	project := m.CurrentProject().Name
+	project := m.CurrentProject().Key()
 	return m.builds[project]
 }
 
 // CurrentReleases returns the releases for the current project
 func (m Model) CurrentReleases() []api.Release {
-	project := m.CurrentProject().Name
+	project := m.CurrentProject().Key()
 	return m.releases[project]
 }
 
 // CurrentPullRequests returns the pull requests for the current project
 func (m Model) CurrentPullRequests() []api.PullRequest {
-	project := m.CurrentProject().Name
+	project := m.CurrentProject().Key()
 	return m.pullRequests[project]
 }
 
 // IsLoading returns true if data is being loaded for the current project
 func (m Model) IsLoading() bool {
-	project := m.CurrentProject().Name
+	project := m.CurrentProject().Key()
 	switch m.activeTab {
 	case TabBuilds:
 		return m.loadingBuilds[project]
@@ -154,7 +461,7 @@ func (m Model) IsLoading() bool {
 
 // HasData returns true if data is available for the current project and tab
 func (m Model) HasData() bool {
-	project := m.CurrentProject().Name
+	project := m.CurrentProject().Key()
 	switch m.activeTab {
 	case TabBuilds:
 		builds, ok := m.builds[project]
@@ -171,55 +478,117 @@ func (m Model) HasData() bool {
 
 // hasBuildData returns true if build data is available for the current project
 func (m Model) hasBuildData() bool {
-	project := m.CurrentProject().Name
+	project := m.CurrentProject().Key()
 	builds, ok := m.builds[project]
 	return ok && len(builds) > 0
 }
 
 // hasReleaseData returns true if release data is available for the current project
 func (m Model) hasReleaseData() bool {
-	project := m.CurrentProject().Name
+	project := m.CurrentProject().Key()
 	releases, ok := m.releases[project]
 	return ok && len(releases) > 0
 }
 
 // getBuildError returns the build error for the current project if any
 func (m Model) getBuildError() error {
-	project := m.CurrentProject().Name
+	project := m.CurrentProject().Key()
 	return m.errors[project+"-builds"]
 }
 
 // getReleaseError returns the release error for the current project if any
 func (m Model) getReleaseError() error {
-	project := m.CurrentProject().Name
+	project := m.CurrentProject().Key()
 	return m.errors[project+"-releases"]
 }
 
 // hasPullRequestData returns true if pull request data is available for the current project
 func (m Model) hasPullRequestData() bool {
-	project := m.CurrentProject().Name
+	project := m.CurrentProject().Key()
 	pullRequests, ok := m.pullRequests[project]
 	return ok && len(pullRequests) > 0
 }
 
 // getPullRequestError returns the pull request error for the current project if any
 func (m Model) getPullRequestError() error {
-	project := m.CurrentProject().Name
+	project := m.CurrentProject().Key()
 	return m.errors[project+"-pullrequests"]
 }
 
 // getBranchFilterInfo returns branch filter info for the current project
 func (m Model) getBranchFilterInfo() string {
-	branches := m.CurrentProject().Branches
+	project := m.CurrentProject()
+	if m.prFilter.Branch.appliesTo(project) {
+		return "current branch " + strings.TrimPrefix(m.prFilter.Branch.Ref, "refs/heads/")
+	}
+	branches := project.Branches
 	if len(branches) == 0 {
 		return "all"
 	}
 	return strings.Join(branches, ", ")
 }
 
+// getStaleSince returns the time the shown data was fetched if it is stale, zero otherwise
+func (m Model) getStaleSince(kind string) time.Time {
+	key := m.CurrentProject().Key() + "-" + kind
+	if !m.stale[key] {
+		return time.Time{}
+	}
+	return m.updatedAt[key]
+}
+
+// organizations returns the organization names in configuration order
+func (m Model) organizations() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, p := range m.config.Projects {
+		if !seen[p.Organization] {
+			seen[p.Organization] = true
+			names = append(names, p.Organization)
+		}
+	}
+	return names
+}
+
+// projectLabel returns the tab label of a project, prefixed with its organization when
+// another organization has a project with the same name
+func (m Model) projectLabel(project config.ProjectConfig) string {
+	for _, p := range m.config.Projects {
+		if p.Organization != project.Organization && strings.EqualFold(p.Name, project.Name) {
+			return project.Organization + "/" + project.Name
+		}
+	}
+	return project.Name
+}
+
+// hasPreflightFailures returns true if the connection check failed for any organization
+func (m Model) hasPreflightFailures() bool {
+	for _, report := range m.preflight {
+		if report.HasFailures() {
+			return true
+		}
+	}
+	return false
+}
+
+// signedInUsers returns the distinct users reported by the connection checks
+func (m Model) signedInUsers() []string {
+	var users []string
+	seen := make(map[string]bool)
+	for _, organization := range m.organizations() {
+		report, ok := m.preflight[organization]
+		if !ok || report.User == "" || seen[report.User] {
+			continue
+		}
+		seen[report.User] = true
+		users = append(users, report.User)
+	}
+	return users
+}
+
 // CurrentError returns the error for the current project/tab if any
 func (m Model) CurrentError() error {
-	project := m.CurrentProject().Name
+	project := m.CurrentProject().Key()
 	key := project
 	switch m.activeTab {
 	case TabBuilds:
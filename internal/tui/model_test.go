@@ -0,0 +1,1305 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/api"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/api/fake"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/cache"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/config"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/localgit"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/webhook"
+)
+
+const (
+	testOrganization = "fake"
+	testProject      = "Payments"
+)
+
+// newTestModel creates a model backed by the fake service with a sized window
+func newTestModel(svc *fake.Service) Model {
+	cfg := &config.Config{
+		Projects: []config.ProjectConfig{{Organization: testOrganization, Name: testProject}},
+		Display: config.DisplayConfig{
+			RefreshInterval:    30 * time.Second,
+			MaxItemsPerProject: 10,
+			DateFormat:         "2006-01-02 15:04:05",
+		},
+	}
+
+	m := NewModel(cfg, Clients{testOrganization: svc})
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 200, Height: 60})
+	return updated.(Model)
+}
+
+// pullRequestIDs returns the IDs of pull requests, in order
+func pullRequestIDs(prs []api.PullRequest) []int {
+	var ids []int
+	for _, pr := range prs {
+		ids = append(ids, pr.PullRequestID)
+	}
+	return ids
+}
+
+// update applies a message and returns the resulting model
+func update(t *testing.T, m Model, msg tea.Msg) Model {
+	t.Helper()
+	updated, _ := m.Update(msg)
+	return updated.(Model)
+}
+
+// newTabTest creates a fake service holding testData and a model showing the given tab.
+// run applies a message to the model and returns the resulting command.
+func newTabTest(t *testing.T, tab Tab) (svc *fake.Service, m *Model, run func(tea.Msg) tea.Cmd) {
+	svc = fake.New()
+	testData(svc)
+	model := newTestModel(svc)
+	model.activeTab = tab
+	m = &model
+
+	run = func(msg tea.Msg) tea.Cmd {
+		t.Helper()
+		updated, cmd := m.Update(msg)
+		*m = updated.(Model)
+		return cmd
+	}
+	return svc, m, run
+}
+
+func testData(svc *fake.Service) {
+	svc.Builds[testProject] = []api.Build{{
+		ID:           1,
+		BuildNumber:  "20240101.1",
+		Status:       api.BuildStatusCompleted,
+		Result:       api.BuildResultSucceeded,
+		Definition:   api.BuildDefinition{ID: 7, Name: "payments-ci"},
+		SourceBranch: "refs/heads/main",
+	}}
+	svc.Releases[testProject] = []api.Release{{
+		ID:                3,
+		Name:              "Release-42",
+		Status:            api.ReleaseStatusActive,
+		ReleaseDefinition: api.ReleaseDefinition{ID: 2, Name: "payments-cd"},
+		Environments: []api.ReleaseEnvironment{
+			{Name: "prod", Status: api.EnvironmentStatusSucceeded},
+		},
+	}}
+	svc.PullRequests[testProject] = []api.PullRequest{{
+		PullRequestID: 5,
+		Title:         "Add refunds endpoint",
+		SourceRefName: "refs/heads/feature/refunds",
+		TargetRefName: "refs/heads/main",
+		Status:        api.PullRequestStatusActive,
+		Repository:    api.PullRequestRepository{Name: "payments-api"},
+	}}
+}
+
+func TestSectionStates(t *testing.T) {
+	sections := []struct {
+		name    string
+		op      string
+		fetch   func(api.Service, config.ProjectConfig, int) tea.Cmd
+		loading string
+		data    string
+		empty   string
+	}{
+		{"builds", fake.OpGetBuilds, func(client api.Service, project config.ProjectConfig, maxItems int) tea.Cmd {
+			return fetchBuilds(client, project, branchFilter{}, maxItems)
+		}, "Loading builds...", "payments-ci", "No builds found"},
+		{"releases", fake.OpGetReleases, fetchReleases, "Loading releases...", "Release-42", "No releases found"},
+		{"pull requests", fake.OpGetPullRequests, func(client api.Service, project config.ProjectConfig, maxItems int) tea.Cmd {
+			return fetchPullRequests(client, project, newPullRequestFilter(config.PullRequestsConfig{}), newIdentities(), maxItems)
+		}, "Loading pull requests...", "Add refunds endpoint", "No pull requests found"},
+	}
+
+	states := []struct {
+		name  string
+		setup func(svc *fake.Service, op string)
+		fetch bool
+		want  func(loading, data, empty string) []string
+		not   func(loading, data, empty string) []string
+	}{
+		{
+			name:  "loading",
+			setup: func(svc *fake.Service, op string) { testData(svc) },
+			want:  func(loading, data, empty string) []string { return []string{loading} },
+			not:   func(loading, data, empty string) []string { return []string{data, empty} },
+		},
+		{
+			name:  "error",
+			setup: func(svc *fake.Service, op string) { svc.SetError(op, errors.New("service unavailable")) },
+			fetch: true,
+			want:  func(loading, data, empty string) []string { return []string{"Error: service unavailable"} },
+			not:   func(loading, data, empty string) []string { return []string{loading, empty} },
+		},
+		{
+			name:  "data",
+			setup: func(svc *fake.Service, op string) { testData(svc) },
+			fetch: true,
+			want:  func(loading, data, empty string) []string { return []string{data} },
+			not:   func(loading, data, empty string) []string { return []string{loading, empty} },
+		},
+		{
+			name:  "empty",
+			setup: func(svc *fake.Service, op string) {},
+			fetch: true,
+			want:  func(loading, data, empty string) []string { return []string{empty} },
+			not:   func(loading, data, empty string) []string { return []string{loading} },
+		},
+	}
+
+	for _, section := range sections {
+		for _, state := range states {
+			t.Run(section.name+"/"+state.name, func(t *testing.T) {
+				svc := fake.New()
+				state.setup(svc, section.op)
+
+				m := newTestModel(svc)
+				m.Init()
+
+				if state.fetch {
+					msg := section.fetch(svc, m.CurrentProject(), m.config.Display.MaxItemsPerProject)()
+					m = update(t, m, msg)
+				}
+
+				view := m.View()
+				for _, want := range state.want(section.loading, section.data, section.empty) {
+					if !strings.Contains(view, want) {
+						t.Errorf("view does not contain %q:\n%s", want, view)
+					}
+				}
+				for _, unwanted := range state.not(section.loading, section.data, section.empty) {
+					if strings.Contains(view, unwanted) {
+						t.Errorf("view unexpectedly contains %q:\n%s", unwanted, view)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestErrorKeepsPreviousData(t *testing.T) {
+	svc := fake.New()
+	testData(svc)
+
+	m := newTestModel(svc)
+	m = update(t, m, fetchBuilds(svc, m.CurrentProject(), branchFilter{}, 10)())
+
+	svc.SetError(fake.OpGetBuilds, errors.New("timeout"))
+	m = update(t, m, fetchBuilds(svc, m.CurrentProject(), branchFilter{}, 10)())
+
+	if got := len(m.CurrentBuilds()); got != 1 {
+		t.Fatalf("builds after failed refresh = %d, want 1", got)
+	}
+	if err := m.getBuildError(); err == nil || err.Error() != "timeout" {
+		t.Errorf("build error = %v, want timeout", err)
+	}
+}
+
+func TestSnapshotMigratesProjectKeys(t *testing.T) {
+	// Snapshots of a single organization used to be keyed by the project name alone
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	old := `{"projects":{"Payments":{"builds":[{"id":1,"buildNumber":"20240101.1"}],"buildsAt":"2024-01-01T12:00:00Z"}}}`
+	if err := os.WriteFile(path, []byte(old), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	enabled := true
+	cfg := &config.Config{
+		Projects: []config.ProjectConfig{{Organization: testOrganization, Name: testProject}},
+		Display:  config.DisplayConfig{MaxItemsPerProject: 10},
+		Cache:    config.CacheConfig{Enabled: &enabled, Path: path},
+	}
+	m := NewModel(cfg, Clients{testOrganization: fake.New()})
+
+	if got := m.CurrentBuilds(); len(got) != 1 || got[0].ID != 1 {
+		t.Fatalf("builds from the old snapshot = %+v, want build 1", got)
+	}
+	if _, ok := m.snapshot.Projects[testProject]; ok {
+		t.Error("old snapshot key was kept")
+	}
+	if _, ok := m.snapshot.Projects[testOrganization+"/"+testProject]; !ok {
+		t.Error("snapshot was not moved to the new key")
+	}
+}
+
+func TestSnapshotRestoresDiscoveredProjects(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	cached := `{"projects":{` +
+		`"fake/Payments":{"builds":[{"id":1,"buildNumber":"20240101.1"}],"buildsAt":"2024-01-01T12:00:00Z"},` +
+		`"fake/Orders":{},"fake/Sandbox":{},"other/Billing":{}}}`
+	if err := os.WriteFile(path, []byte(cached), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Listing the projects failed, so the organization has none
+	enabled := true
+	cfg := &config.Config{
+		Organizations: []config.OrganizationConfig{{
+			Name:          testOrganization,
+			ProjectSource: config.ProjectsSetting{Discovery: &config.DiscoveryConfig{Exclude: []string{"Sandbox"}}},
+		}},
+		Display: config.DisplayConfig{MaxItemsPerProject: 10},
+		Cache:   config.CacheConfig{Enabled: &enabled, Path: path},
+	}
+	m := NewModel(cfg, Clients{testOrganization: fake.New()})
+	m.SetProjectsError(errors.New("failed to list projects of https://dev.azure.com/fake: connection refused"))
+	m = update(t, m, tea.WindowSizeMsg{Width: 200, Height: 60})
+
+	var keys []string
+	for _, p := range m.config.Projects {
+		keys = append(keys, p.Key())
+	}
+	if want := []string{"fake/Orders", "fake/Payments"}; !reflect.DeepEqual(keys, want) {
+		t.Fatalf("projects = %v, want %v", keys, want)
+	}
+
+	m.activeProject = 1
+	if got := m.CurrentBuilds(); len(got) != 1 || got[0].ID != 1 {
+		t.Errorf("cached builds = %+v, want build 1", got)
+	}
+	view := m.View()
+	for _, want := range []string{"showing the projects cached by the last run", "connection refused"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view does not contain %q:\n%s", want, view)
+		}
+	}
+
+	// Without a snapshot the dashboard starts without projects and shows the error
+	cfg.Organizations[0].Projects = nil
+	cfg.CollectProjects()
+	cfg.Cache.Path = filepath.Join(t.TempDir(), "missing.json")
+	empty := NewModel(cfg, Clients{testOrganization: fake.New()})
+	empty.SetProjectsError(errors.New("connection refused"))
+	empty = update(t, empty, tea.WindowSizeMsg{Width: 200, Height: 60})
+	_ = empty.Init()
+	empty = update(t, empty, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	if view := empty.View(); !strings.Contains(view, "Projects could not be listed:") {
+		t.Errorf("error not shown without projects:\n%s", view)
+	}
+}
+
+func TestKeyNavigation(t *testing.T) {
+	svc := fake.New()
+	testData(svc)
+	svc.Builds[testProject] = append(svc.Builds[testProject], api.Build{ID: 2, Definition: api.BuildDefinition{Name: "payments-nightly"}})
+
+	m := newTestModel(svc)
+	m = update(t, m, fetchBuilds(svc, m.CurrentProject(), branchFilter{}, 10)())
+
+	tests := []struct {
+		key     tea.KeyMsg
+		wantTab Tab
+		wantRow int
+	}{
+		{tea.KeyMsg{Type: tea.KeyDown}, TabBuilds, 1},
+		{tea.KeyMsg{Type: tea.KeyDown}, TabBuilds, 1}, // clamped at the last row
+		{tea.KeyMsg{Type: tea.KeyUp}, TabBuilds, 0},
+		{tea.KeyMsg{Type: tea.KeyTab}, TabReleases, 0},
+		{tea.KeyMsg{Type: tea.KeyTab}, TabPullRequests, 0},
+		{tea.KeyMsg{Type: tea.KeyTab}, TabBuilds, 0},
+	}
+
+	for i, tt := range tests {
+		m = update(t, m, tt.key)
+		if m.activeTab != tt.wantTab || m.selectedRow != tt.wantRow {
+			t.Errorf("step %d (%s): tab=%d row=%d, want tab=%d row=%d",
+				i, tt.key, m.activeTab, m.selectedRow, tt.wantTab, tt.wantRow)
+		}
+	}
+}
+
+func TestWebhookEventUpdatesRow(t *testing.T) {
+	svc := fake.New()
+	testData(svc)
+
+	m := newTestModel(svc)
+	m = update(t, m, fetchPullRequests(svc, m.CurrentProject(), m.prFilter, m.users, 10)())
+
+	pr := svc.PullRequests[testProject][0]
+	pr.Title = "Add refunds endpoint v2"
+	pr.Repository.Project = api.TeamProject{Name: testProject}
+	m = update(t, m, WebhookEventMsg{Event: webhook.Event{Type: webhook.EventPullRequestUpdated, Project: testProject, PullRequest: &pr}})
+
+	if got := m.CurrentPullRequests()[0].Title; got != pr.Title {
+		t.Errorf("title after update event = %q, want %q", got, pr.Title)
+	}
+
+	pr.Status = api.PullRequestStatusCompleted
+	m = update(t, m, WebhookEventMsg{Event: webhook.Event{Type: webhook.EventPullRequestUpdated, Project: testProject, PullRequest: &pr}})
+
+	if got := len(m.CurrentPullRequests()); got != 0 {
+		t.Errorf("pull requests after completion event = %d, want 0", got)
+	}
+}
+
+func TestWebhookBuildEventSavesSnapshot(t *testing.T) {
+	svc := fake.New()
+	testData(svc)
+	svc.Builds[testProject][0].Status = api.BuildStatusInProgress
+	svc.Builds[testProject][0].Result = ""
+
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	enabled := true
+	cfg := &config.Config{
+		Projects: []config.ProjectConfig{{Organization: testOrganization, Name: testProject}},
+		Display:  config.DisplayConfig{MaxItemsPerProject: 10},
+		Cache:    config.CacheConfig{Enabled: &enabled, Path: path},
+	}
+	m := NewModel(cfg, Clients{testOrganization: svc})
+	m = update(t, m, fetchBuilds(svc, m.CurrentProject(), branchFilter{}, 10)())
+
+	build := svc.Builds[testProject][0]
+	build.Status, build.Result = api.BuildStatusCompleted, api.BuildResultFailed
+	updated, cmd := m.Update(WebhookEventMsg{Event: webhook.Event{Type: webhook.EventBuildComplete, Project: testProject, Build: &build}})
+	m = updated.(Model)
+	if cmd == nil {
+		t.Fatal("build event returned no command")
+	}
+	// Run the snapshot write; the refetch of the builds is not needed
+	for _, c := range cmd().(tea.BatchMsg) {
+		if c != nil {
+			c()
+		}
+	}
+
+	snapshot, err := cache.Load(path)
+	if err != nil {
+		t.Fatalf("loading the snapshot: %v", err)
+	}
+	p, ok := snapshot.Projects[testOrganization+"/"+testProject]
+	if !ok || len(p.Builds) != 1 || p.Builds[0].Result != api.BuildResultFailed {
+		t.Errorf("snapshot after the build event = %+v, want the failed build", p)
+	}
+}
+
+func TestProjectLabels(t *testing.T) {
+	cfg := &config.Config{
+		Projects: []config.ProjectConfig{
+			{Organization: "contoso", Name: "Payments"},
+			{Organization: "contoso", Name: "Web"},
+			{Organization: "fabrikam", Name: "Payments"},
+		},
+		Display: config.DisplayConfig{RefreshInterval: time.Minute, MaxItemsPerProject: 10},
+	}
+	m := NewModel(cfg, Clients{"contoso": fake.New(), "fabrikam": fake.New()})
+
+	want := []string{"contoso/Payments", "Web", "fabrikam/Payments"}
+	for i, p := range cfg.Projects {
+		if got := m.projectLabel(p); got != want[i] {
+			t.Errorf("label of %s = %q, want %q", p.Key(), got, want[i])
+		}
+	}
+}
+
+func TestProjectPicker(t *testing.T) {
+	svc := fake.New()
+	svc.Projects = []api.TeamProject{{Name: testProject}, {Name: "Platform"}, {Name: "Payroll Archive"}, {Name: "Web"}}
+
+	m := newTestModel(svc)
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	m = updated.(Model)
+	if m.picker == nil {
+		t.Fatal("picker not opened")
+	}
+	if cmd == nil {
+		t.Fatal("no command to list projects")
+	}
+	m = update(t, m, listProjects(svc, testOrganization, m.picker.seq)())
+
+	for _, r := range "pla" {
+		m = update(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	if len(m.picker.matches) == 0 || m.picker.matches[0].Name != "Platform" {
+		t.Fatalf("matches for %q = %v, want Platform first", "pla", m.picker.matches)
+	}
+	for _, item := range m.picker.matches {
+		if item.Name == testProject {
+			t.Errorf("configured project %s offered again", testProject)
+		}
+	}
+
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.picker != nil {
+		t.Error("picker still open after adding a project")
+	}
+	if got := m.CurrentProject(); got.Name != "Platform" || got.Organization != testOrganization {
+		t.Errorf("current project = %+v, want %s/Platform", got, testOrganization)
+	}
+	if !m.loadingBuilds[m.CurrentProject().Key()] {
+		t.Error("added project is not loading")
+	}
+}
+
+func TestProjectPickerDropsStaleLists(t *testing.T) {
+	svc := fake.New()
+	svc.Projects = []api.TeamProject{{Name: "Platform"}}
+
+	m := newTestModel(svc)
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	stale := listProjects(svc, testOrganization, m.picker.seq)()
+
+	// Close and reopen the picker before the first list arrives
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+
+	svc.Projects = []api.TeamProject{{Name: "Web"}}
+	m = update(t, m, listProjects(svc, testOrganization, m.picker.seq)())
+	m = update(t, m, stale)
+
+	if len(m.picker.items) != 1 || m.picker.items[0].Name != "Web" {
+		t.Errorf("picker items = %+v, want only Web from the current list", m.picker.items)
+	}
+	if m.picker.pending != 0 {
+		t.Errorf("pending = %d, want 0", m.picker.pending)
+	}
+}
+
+func TestPullRequestFilters(t *testing.T) {
+	svc := fake.New()
+	me := svc.Connection.AuthenticatedUser.ID
+	other := api.Identity{ID: "other-user", DisplayName: "Other User"}
+	svc.PullRequests[testProject] = []api.PullRequest{
+		{PullRequestID: 1, Title: "My change", CreatedBy: api.Identity{ID: me}, Status: api.PullRequestStatusActive,
+			Reviewers: []api.Reviewer{{ID: other.ID}}},
+		{PullRequestID: 2, Title: "Review requested", CreatedBy: other, Status: api.PullRequestStatusActive,
+			Reviewers: []api.Reviewer{{ID: me}}},
+		{PullRequestID: 3, Title: "Draft change", CreatedBy: other, Status: api.PullRequestStatusActive, IsDraft: true},
+	}
+
+	m := newTestModel(svc)
+	m.activeTab = TabPullRequests
+	press := func(k string) {
+		m = update(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+		m = update(t, m, fetchPullRequests(svc, m.CurrentProject(), m.prFilter, m.users, 10)())
+	}
+
+	tests := []struct {
+		key    string
+		header string
+		want   []string
+		not    []string
+	}{
+		{"m", "(mine)", []string{"My change"}, []string{"Review requested", "Draft change"}},
+		{"m", "", []string{"My change", "Review requested", "Draft change"}, nil},
+		{"v", "(awaiting my review)", []string{"Review requested"}, []string{"My change", "Draft change"}},
+		{"v", "", []string{"My change", "Review requested", "Draft change"}, nil},
+		{"d", "(no drafts)", []string{"My change", "Review requested"}, []string{"Draft change"}},
+	}
+	for _, tt := range tests {
+		before := m.prFilter
+		press(tt.key)
+		view := m.View()
+		if tt.header != "" && !strings.Contains(view, tt.header) {
+			t.Errorf("after %q: header %q not shown", tt.key, tt.header)
+		}
+		for _, s := range tt.want {
+			if !strings.Contains(view, s) {
+				t.Errorf("after %q: %q not shown", tt.key, s)
+			}
+		}
+		for _, s := range tt.not {
+			if strings.Contains(view, s) {
+				t.Errorf("after %q: %q shown", tt.key, s)
+			}
+		}
+
+		// Results fetched with the previous filters are dropped
+		stale := fetchPullRequests(svc, m.CurrentProject(), before, m.users, 10)().(PullRequestsLoadedMsg)
+		want := pullRequestIDs(m.CurrentPullRequests())
+		if got := pullRequestIDs(update(t, m, stale).CurrentPullRequests()); !reflect.DeepEqual(got, want) {
+			t.Errorf("after %q: pull requests after stale results = %v, want %v", tt.key, got, want)
+		}
+	}
+
+	var lookups int
+	for _, call := range svc.Calls {
+		if call == fake.OpGetConnectionData {
+			lookups++
+		}
+	}
+	if lookups != 1 {
+		t.Errorf("connection data requested %d times, want 1", lookups)
+	}
+}
+
+func TestIdentityFiltersWaitForUser(t *testing.T) {
+	svc := fake.New()
+	me := svc.Connection.AuthenticatedUser.ID
+	mine := api.PullRequest{PullRequestID: 1, CreatedBy: api.Identity{ID: me}, Status: api.PullRequestStatusActive}
+	theirs := api.PullRequest{PullRequestID: 2, CreatedBy: api.Identity{ID: "other-user"}, Status: api.PullRequestStatusActive}
+
+	m := newTestModel(svc)
+	m.prFilter.CreatedByMe = true
+	project := m.CurrentProject()
+
+	// Before the signed-in user is known, no pull request passes the filter
+	if m.pullRequestListed(project, mine) || m.pullRequestListed(project, theirs) {
+		t.Error("pull requests listed by the identity filter before the user is known")
+	}
+
+	// Looking up the user does not block reads of the cache
+	svc.Latency = time.Second
+	lookup := make(chan error, 1)
+	go func() {
+		_, err := m.users.userID(context.Background(), svc, testOrganization)
+		lookup <- err
+	}()
+	read := make(chan string, 1)
+	go func() { read <- m.users.cached(testOrganization) }()
+	select {
+	case id := <-read:
+		if id != "" {
+			t.Errorf("cached() during lookup = %q, want empty", id)
+		}
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("cached() blocked during the connectionData request")
+	}
+	if err := <-lookup; err != nil {
+		t.Fatalf("userID: %v", err)
+	}
+
+	if !m.pullRequestListed(project, mine) || m.pullRequestListed(project, theirs) {
+		t.Error("identity filter does not match only the signed-in user's pull requests")
+	}
+}
+
+func TestPullRequestStatusFilter(t *testing.T) {
+	svc := fake.New()
+	closed := time.Date(2024, 6, 11, 7, 12, 0, 0, time.UTC)
+	svc.PullRequests[testProject] = []api.PullRequest{
+		{PullRequestID: 1, Title: "Open change", Status: api.PullRequestStatusActive},
+		{PullRequestID: 2, Title: "Merged change", Status: api.PullRequestStatusCompleted, ClosedDate: closed,
+			MergeCommit: &api.GitCommitRef{CommitID: "3f6c2a9e81d04b7a"}},
+		{PullRequestID: 3, Title: "Dropped change", Status: api.PullRequestStatusAbandoned, ClosedDate: closed},
+	}
+
+	m := newTestModel(svc)
+	m.activeTab = TabPullRequests
+
+	tests := []struct {
+		status api.PullRequestStatus
+		want   []string
+		not    []string
+	}{
+		{api.PullRequestStatusCompleted, []string{"Merged change", "Closed", "3f6c2a9e", formatCreatedTime(closed)}, []string{"Open change", "Dropped change"}},
+		{api.PullRequestStatusAbandoned, []string{"Dropped change"}, []string{"Open change", "Merged change"}},
+		{api.PullRequestStatusAll, []string{"Open change", "Merged change", "Dropped change"}, nil},
+		{api.PullRequestStatusActive, []string{"Open change"}, []string{"Merged change", "Dropped change", "Closed"}},
+	}
+	for _, tt := range tests {
+		m = update(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+		if m.prFilter.Status != tt.status {
+			t.Fatalf("status after s = %q, want %q", m.prFilter.Status, tt.status)
+		}
+		m = update(t, m, fetchPullRequests(svc, m.CurrentProject(), m.prFilter, m.users, 10)())
+
+		view := m.View()
+		for _, s := range tt.want {
+			if !strings.Contains(view, s) {
+				t.Errorf("status %s: %q not shown", tt.status, s)
+			}
+		}
+		for _, s := range tt.not {
+			if strings.Contains(view, s) {
+				t.Errorf("status %s: %q shown", tt.status, s)
+			}
+		}
+	}
+}
+
+func TestPullRequestPolicies(t *testing.T) {
+	svc := fake.New()
+	policy := func(name, build string, status api.PolicyEvaluationStatus, blocking bool) api.PolicyEvaluation {
+		return api.PolicyEvaluation{Status: status, Configuration: api.PolicyConfiguration{
+			IsBlocking: blocking,
+			Type:       api.PolicyType{DisplayName: name},
+			Settings:   api.PolicySettings{DisplayName: build},
+		}}
+	}
+	svc.PullRequests[testProject] = []api.PullRequest{{
+		PullRequestID: 1,
+		Title:         "Approved but blocked",
+		Status:        api.PullRequestStatusActive,
+		Reviewers:     []api.Reviewer{{Vote: 10}},
+		Policies: []api.PolicyEvaluation{
+			policy("Minimum number of reviewers", "", api.PolicyEvaluationStatusApproved, true),
+			policy("Build", "payments-ci", api.PolicyEvaluationStatusRejected, true),
+			policy("Work item linking", "", api.PolicyEvaluationStatusRunning, true),
+			policy("Comment requirements", "", api.PolicyEvaluationStatusRejected, false),
+		},
+	}}
+
+	m := newTestModel(svc)
+	m.activeTab = TabPullRequests
+	m = update(t, m, fetchPullRequests(svc, m.CurrentProject(), m.prFilter, m.users, 10)())
+
+	view := m.View()
+	for _, want := range []string{"Policies", "✓1 ✗2 ○1", "Blocking policies:", "Build (payments-ci)", "Work item linking"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view does not contain %q:\n%s", want, view)
+		}
+	}
+	for _, unwanted := range []string{"Minimum number of reviewers", "Comment requirements"} {
+		if strings.Contains(view, unwanted) {
+			t.Errorf("non-blocking policy %q listed as blocking", unwanted)
+		}
+	}
+}
+
+func TestPullRequestDetail(t *testing.T) {
+	svc, m, run := newTabTest(t, TabPullRequests)
+	pr := &svc.PullRequests[testProject][0]
+	pr.Description = "Adds POST /refunds."
+	pr.Reviewers = []api.Reviewer{
+		{DisplayName: "Sam Reviewer", Vote: 10, IsRequired: true},
+		{DisplayName: "Alex Ops", Vote: -5},
+	}
+	comment := func(content string) api.Comment {
+		return api.Comment{ID: 1, Author: api.Identity{DisplayName: "Sam Reviewer"}, Content: content, CommentType: api.CommentTypeText}
+	}
+	svc.Threads[5] = []api.CommentThread{
+		{ID: 1, Status: api.CommentThreadStatusActive, Comments: []api.Comment{
+			{ID: 1, Content: "Withdrawn", IsDeleted: true},
+			{ID: 2, Author: api.Identity{DisplayName: "Sam Reviewer"}, Content: "Scope the key to the merchant", CommentType: api.CommentTypeText},
+		},
+			ThreadContext: &api.ThreadContext{FilePath: "/src/refunds.go", RightFileStart: &api.CommentPosition{Line: 42}}},
+		{ID: 2, Status: api.CommentThreadStatusFixed, Comments: []api.Comment{comment("Link the incident")}},
+		{ID: 3, Comments: []api.Comment{{ID: 1, Content: "Sam Reviewer voted 10", CommentType: api.CommentTypeSystem}}},
+	}
+	run(fetchPullRequests(svc, m.CurrentProject(), m.prFilter, m.users, 10)())
+
+	cmd := run(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("i")})
+	if m.detail == nil || cmd == nil {
+		t.Fatal("detail pane not opened")
+	}
+	run(cmd())
+
+	view := m.View()
+	for _, want := range []string{"Adds POST /refunds.", "Sam Reviewer", "required", "waiting for author",
+		"Active threads (1)", "/src/refunds.go:42", "Scope the key to the merchant"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("detail does not contain %q:\n%s", want, view)
+		}
+	}
+	for _, unwanted := range []string{"Link the incident", "voted 10"} {
+		if strings.Contains(view, unwanted) {
+			t.Errorf("resolved or system thread %q shown", unwanted)
+		}
+	}
+
+	// Reply to the selected thread
+	run(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	run(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Done")})
+	cmd = run(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("no command to send the reply")
+	}
+	run(cmd())
+	if got := len(m.detail.threads[0].Comments); got != 3 {
+		t.Fatalf("comments after reply = %d, want 3", got)
+	}
+	// Replies go to the first comment that was not deleted
+	if reply := m.detail.threads[0].Comments[2]; reply.Content != "Done" || reply.ParentCommentID != 2 {
+		t.Errorf("reply = %q to comment %d, want %q to comment 2", reply.Content, reply.ParentCommentID, "Done")
+	}
+
+	// Resolve it
+	cmd = run(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	run(cmd())
+	if got := svc.Threads[5][0].Status; got != api.CommentThreadStatusFixed {
+		t.Errorf("thread status = %q, want %q", got, api.CommentThreadStatusFixed)
+	}
+	if len(m.detail.threads) != 0 || !strings.Contains(m.View(), "No active threads") {
+		t.Errorf("resolved thread still listed: %v", m.detail.threads)
+	}
+
+	run(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.detail != nil {
+		t.Error("detail pane still open after esc")
+	}
+}
+
+func TestPullRequestFiles(t *testing.T) {
+	svc, m, run := newTabTest(t, TabPullRequests)
+	svc.Iterations[5] = []api.PullRequestIteration{{ID: 1}, {ID: 2, SourceRefCommit: api.GitCommitRef{CommitID: "4b1e9c7d2a3f"}}}
+	svc.Changes[5] = []api.PullRequestChange{
+		{Item: api.GitItem{Path: "/src", GitObjectType: "tree", IsFolder: true}, ChangeType: api.ChangeTypeEdit},
+		{Item: api.GitItem{Path: "/src/refunds.go", ObjectID: "new", OriginalObjectID: "old", GitObjectType: "blob"}, ChangeType: api.ChangeTypeEdit},
+		{Item: api.GitItem{Path: "/src/legacy.go", OriginalObjectID: "legacy", GitObjectType: "blob"}, ChangeType: api.ChangeTypeDelete},
+	}
+	svc.Blobs["old"] = "package refunds\n\nfunc Refund() {}\n"
+	svc.Blobs["new"] = "package refunds\n\nfunc Refund(key string) {}\n"
+	svc.Blobs["legacy"] = "package refunds\n"
+	run(fetchPullRequests(svc, m.CurrentProject(), m.prFilter, m.users, 10)())
+
+	cmd := run(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	if m.files == nil || cmd == nil {
+		t.Fatal("files pane not opened")
+	}
+	run(cmd())
+
+	view := m.View()
+	for _, want := range []string{"iteration 2 @ 4b1e9c7d", "Changed files (2)", "M /src/refunds.go", "D /src/legacy.go"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("files view does not contain %q:\n%s", want, view)
+		}
+	}
+
+	cmd = run(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.files.diff == nil || cmd == nil {
+		t.Fatal("diff not opened")
+	}
+	run(cmd())
+	view = m.View()
+	for _, want := range []string{"@@ -1,3 +1,3 @@", "-func Refund() {}", "+func Refund(key string) {}"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("diff does not contain %q:\n%s", want, view)
+		}
+	}
+
+	// Next file is the deletion, compared to an empty file
+	cmd = run(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("l")})
+	run(cmd())
+	if !strings.Contains(m.View(), "@@ -1,1 +0,0 @@") {
+		t.Errorf("deleted file diff not shown:\n%s", m.View())
+	}
+
+	run(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.files == nil || m.files.diff != nil {
+		t.Fatal("esc should return to the file list")
+	}
+	run(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.files != nil {
+		t.Error("files pane still open after esc")
+	}
+}
+
+func TestCompletePullRequest(t *testing.T) {
+	svc, m, run := newTabTest(t, TabPullRequests)
+	pr := &svc.PullRequests[testProject][0]
+	pr.Reviewers = []api.Reviewer{{DisplayName: "Sam Reviewer", Vote: 10}}
+	pr.LastMergeSourceCommit = &api.GitCommitRef{CommitID: "4b1e9c7d"}
+	pr.Policies = nil
+	run(fetchPullRequests(svc, m.CurrentProject(), m.prFilter, m.users, 10)())
+
+	run(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("C")})
+	if m.complete == nil {
+		t.Fatal("completion form not opened")
+	}
+	if m.complete.action != completeActionNow {
+		t.Errorf("action = %q, approved pull request should default to completing now", m.complete.action)
+	}
+
+	// Squash and delete the source branch
+	run(tea.KeyMsg{Type: tea.KeyRight})
+	run(tea.KeyMsg{Type: tea.KeyDown})
+	run(tea.KeyMsg{Type: tea.KeySpace})
+	if !strings.Contains(m.View(), "‹ squash ›") {
+		t.Errorf("merge strategy not shown:\n%s", m.View())
+	}
+
+	cmd := run(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("no command to complete the pull request")
+	}
+	run(cmd())
+	if m.complete != nil {
+		t.Error("form still open after completing")
+	}
+
+	completed := svc.PullRequests[testProject][0]
+	if completed.Status != api.PullRequestStatusCompleted {
+		t.Errorf("status = %q, want completed", completed.Status)
+	}
+	want := api.CompletionOptions{MergeStrategy: api.MergeStrategySquash, DeleteSourceBranch: true, TransitionWorkItems: true}
+	if completed.CompletionOptions == nil || *completed.CompletionOptions != want {
+		t.Errorf("completion options = %+v, want %+v", completed.CompletionOptions, want)
+	}
+}
+
+func TestCompleteUnknownPolicies(t *testing.T) {
+	_, m, run := newTabTest(t, TabPullRequests)
+	pr := api.PullRequest{
+		PullRequestID:         5,
+		Status:                api.PullRequestStatusActive,
+		Reviewers:             []api.Reviewer{{DisplayName: "Sam Reviewer", Vote: 10}},
+		LastMergeSourceCommit: &api.GitCommitRef{CommitID: "4b1e9c7d"},
+		PolicyError:           "access denied",
+	}
+	run(PullRequestsLoadedMsg{Project: m.CurrentProject().Key(), Filter: m.prFilter, PullRequests: []api.PullRequest{pr}})
+
+	run(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("C")})
+	if m.complete == nil || m.complete.action != completeActionAutoComplete {
+		t.Fatal("pull request with unknown policies should only offer auto-complete")
+	}
+	view := m.View()
+	for _, want := range []string{"policies ?", "policies unknown: access denied"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("form does not contain %q:\n%s", want, view)
+		}
+	}
+}
+
+func TestSetAutoComplete(t *testing.T) {
+	svc, m, run := newTabTest(t, TabPullRequests)
+	svc.PullRequests[testProject][0].Reviewers = []api.Reviewer{{DisplayName: "Sam Reviewer", Vote: 0}}
+	run(fetchPullRequests(svc, m.CurrentProject(), m.prFilter, m.users, 10)())
+
+	run(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("C")})
+	if m.complete == nil || m.complete.action != completeActionAutoComplete {
+		t.Fatal("unapproved pull request should only offer auto-complete")
+	}
+	if view := m.View(); !strings.Contains(view, "not approved by all reviewers") {
+		t.Errorf("blocking reason not shown:\n%s", view)
+	}
+
+	// Completing now cannot be chosen
+	m.complete.field = completeFieldAction
+	run(tea.KeyMsg{Type: tea.KeySpace})
+	if m.complete.action != completeActionAutoComplete {
+		t.Errorf("action = %q, want auto-complete for an unapproved pull request", m.complete.action)
+	}
+
+	run(run(tea.KeyMsg{Type: tea.KeyEnter})())
+
+	pr := svc.PullRequests[testProject][0]
+	if !pr.IsAutoComplete() || pr.AutoCompleteSetBy.ID != "fake-user" {
+		t.Errorf("auto-complete set by %+v, want fake-user", pr.AutoCompleteSetBy)
+	}
+	if pr.Status != api.PullRequestStatusActive {
+		t.Errorf("status = %q, want active", pr.Status)
+	}
+
+	// Cancel it again
+	run(fetchPullRequests(svc, m.CurrentProject(), m.prFilter, m.users, 10)())
+	run(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("C")})
+	m.complete.field = completeFieldAction
+	run(tea.KeyMsg{Type: tea.KeySpace})
+	if !strings.Contains(m.View(), "‹ cancel auto-complete ›") {
+		t.Fatalf("cancel action not offered:\n%s", m.View())
+	}
+	run(run(tea.KeyMsg{Type: tea.KeyEnter})())
+
+	if pr := svc.PullRequests[testProject][0]; pr.IsAutoComplete() {
+		t.Errorf("auto-complete still set by %+v", pr.AutoCompleteSetBy)
+	}
+}
+
+func TestCreatePullRequest(t *testing.T) {
+	svc := fake.New()
+	testData(svc)
+	svc.Repositories[testProject] = []api.GitRepository{{ID: "repo-1", Name: "payments-api", DefaultBranch: "refs/heads/main"}}
+	sam := api.ConnectionIdentity{ID: "sam", ProviderDisplayName: "Sam Reviewer"}
+	sam.Properties.Mail.Value = "sam@contoso.com"
+	svc.Identities = []api.ConnectionIdentity{sam, {ID: "samantha", ProviderDisplayName: "Samantha Ops"}}
+
+	m := newTestModel(svc)
+	m.activeTab = TabPullRequests
+
+	// Without a local checkout there is nothing to create a pull request from
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if m.create != nil {
+		t.Fatal("create form opened without a local checkout")
+	}
+
+	m.SetLocalRepository(&localgit.Repository{
+		Branch:            "feature/chargebacks",
+		Remote:            localgit.Remote{Host: "dev.azure.com", Organization: "fake", Project: testProject, Repository: "payments-api"},
+		LastCommitSubject: "Add chargebacks",
+		LastCommitBody:    "Handles disputes raised by the card networks.",
+	})
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if m.create == nil {
+		t.Fatal("create form not opened")
+	}
+	m = update(t, m, fetchRepository(svc, m.CurrentProject(), "payments-api")())
+	if got := m.create.target.Value(); got != "main" {
+		t.Errorf("target = %q, want the default branch main", got)
+	}
+	if got := m.create.title.Value(); got != "Add chargebacks" {
+		t.Errorf("title = %q, want the last commit subject", got)
+	}
+
+	// Add a reviewer by a name matching two identities, then mark as draft
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyTab})
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyTab})
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("sam")})
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyTab})
+	m = update(t, m, tea.KeyMsg{Type: tea.KeySpace})
+
+	// The branch has not been pushed yet
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	m = update(t, m, cmd())
+	if m.create == nil || m.create.err == nil || !strings.Contains(m.create.err.Error(), "push the branch first") {
+		t.Fatalf("unpushed branch not reported: %+v", m.create)
+	}
+
+	svc.Branches["payments-api"] = []string{"refs/heads/main", "refs/heads/feature/chargebacks"}
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	m = update(t, m, cmd())
+	if m.create == nil || m.create.err == nil || !strings.Contains(m.create.err.Error(), "ambiguous") {
+		t.Fatalf("ambiguous reviewer not reported: %+v", m.create)
+	}
+
+	m.create.reviewers.SetValue("sam@contoso.com")
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	m = update(t, m, cmd())
+	if m.create != nil {
+		t.Fatalf("form still open after creating: %v", m.create.err)
+	}
+
+	prs := svc.PullRequests[testProject]
+	created := prs[len(prs)-1]
+	if created.SourceRefName != "refs/heads/feature/chargebacks" || created.TargetRefName != "refs/heads/main" {
+		t.Errorf("branches = %s → %s", created.SourceRefName, created.TargetRefName)
+	}
+	if created.Title != "Add chargebacks" || created.Description != "Handles disputes raised by the card networks." || !created.IsDraft {
+		t.Errorf("created %+v", created)
+	}
+	if len(created.Reviewers) != 1 || created.Reviewers[0].ID != "sam" {
+		t.Errorf("reviewers = %+v, want sam", created.Reviewers)
+	}
+}
+
+func TestCurrentBranchFilter(t *testing.T) {
+	svc := fake.New()
+	testData(svc)
+	svc.Builds[testProject] = append(svc.Builds[testProject], api.Build{
+		ID:           2,
+		BuildNumber:  "20240102.1",
+		Status:       api.BuildStatusCompleted,
+		Result:       api.BuildResultFailed,
+		Definition:   api.BuildDefinition{ID: 7, Name: "payments-ci"},
+		SourceBranch: "refs/heads/feature/refunds",
+	})
+	svc.PullRequests[testProject] = append(svc.PullRequests[testProject], api.PullRequest{
+		PullRequestID: 6,
+		Title:         "Bump dependencies",
+		SourceRefName: "refs/heads/chore/deps",
+		TargetRefName: "refs/heads/main",
+		Status:        api.PullRequestStatusActive,
+	})
+
+	m := newTestModel(svc)
+	m.config.Projects = append([]config.ProjectConfig{{Organization: testOrganization, Name: "Other"}}, m.config.Projects...)
+	m.SetLocalRepository(&localgit.Repository{
+		Branch: "feature/refunds",
+		Remote: localgit.Remote{Host: "dev.azure.com", Organization: "fake", Project: testProject, Repository: "payments-api"},
+	})
+	if m.CurrentProject().Name != testProject {
+		t.Fatalf("opened on %q, want the project of the checkout", m.CurrentProject().Name)
+	}
+	project, other := m.CurrentProject(), m.config.Projects[0]
+	staleBuilds := fetchBuilds(svc, project, m.prFilter.Branch, 10)()
+	otherPullRequests := fetchPullRequests(svc, other, m.prFilter, m.users, 10)
+
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	if m.prFilter.Branch.Ref != "refs/heads/feature/refunds" {
+		t.Fatalf("branch filter = %+v", m.prFilter.Branch)
+	}
+
+	// Other projects are neither refetched nor filtered; builds of all branches arriving late are dropped
+	if m.loadingPullRequests[other.Key()] {
+		t.Error("pull requests of another project refetched")
+	}
+	m = update(t, m, otherPullRequests())
+	if _, ok := m.pullRequests[other.Key()]; !ok {
+		t.Error("pull requests of another project fetched before the toggle were dropped")
+	}
+	m = update(t, m, staleBuilds)
+	if _, ok := m.builds[project.Key()]; ok {
+		t.Errorf("builds of all branches shown: %+v", m.builds[project.Key()])
+	}
+
+	m = update(t, m, fetchBuilds(svc, project, m.prFilter.Branch, 10)())
+	m = update(t, m, fetchPullRequests(svc, project, m.prFilter, m.users, 10)())
+
+	if builds := m.CurrentBuilds(); len(builds) != 1 || builds[0].ID != 2 {
+		t.Errorf("builds = %+v, want only build 2 of the branch", builds)
+	}
+	if prs := m.CurrentPullRequests(); len(prs) != 1 || prs[0].PullRequestID != 5 {
+		t.Errorf("pull requests = %+v, want only !5 from the branch", prs)
+	}
+	if view := m.View(); !strings.Contains(view, "current branch feature/refunds") {
+		t.Errorf("branch filter not shown:\n%s", view)
+	}
+
+	m = update(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	if m.prFilter.Branch != (branchFilter{}) {
+		t.Errorf("branch filter still on: %+v", m.prFilter.Branch)
+	}
+}
+
+func TestReleaseDetail(t *testing.T) {
+	svc, m, run := newTabTest(t, TabReleases)
+	start := time.Date(2024, 6, 10, 10, 0, 0, 0, time.UTC)
+	release := &svc.Releases[testProject][0]
+	release.Environments[0] = api.ReleaseEnvironment{
+		ID:     11,
+		Name:   "prod",
+		Status: api.EnvironmentStatusRejected,
+		DeploySteps: []api.DeployStep{{
+			ID:           21,
+			Attempt:      1,
+			Status:       api.EnvironmentStatusRejected,
+			Reason:       "manual",
+			RequestedFor: api.Identity{DisplayName: "Dana Dev"},
+			ReleaseDeployPhases: []api.ReleaseDeployPhase{{
+				ID:     31,
+				Name:   "Agent job",
+				Status: "failed",
+				DeploymentJobs: []api.DeploymentJob{{Tasks: []api.ReleaseTask{
+					{ID: 1, Name: "Download artifacts", Status: api.TaskStatusSucceeded, StartTime: start, FinishTime: start.Add(20 * time.Second)},
+					{ID: 2, Name: "Deploy Azure App Service", Status: api.TaskStatusFailed, StartTime: start.Add(20 * time.Second),
+						FinishTime: start.Add(2 * time.Minute), Issues: []api.TaskIssue{{IssueType: "error", Message: "Conflict (CODE: 409)"}}},
+				}}},
+			}},
+			PreDeploymentGates: &api.ReleaseGates{Status: "succeeded", DeploymentJobs: []api.DeploymentJob{{Tasks: []api.ReleaseTask{
+				{ID: 1, Name: "Query Azure Monitor alerts", Status: api.TaskStatusSucceeded},
+			}}}},
+		}},
+		PreDeployApprovals: []api.ReleaseApproval{{
+			Attempt:    1,
+			Status:     api.ApprovalStatusApproved,
+			Approver:   api.Identity{DisplayName: "Release Managers"},
+			ApprovedBy: &api.Identity{DisplayName: "Alex Ops"},
+			Comments:   "ship it",
+		}},
+	}
+	svc.TaskLogs[2] = "2024-06-10T10:00:21.0000000Z ##[section]Starting: Deploy\n2024-06-10T10:02:00.0000000Z ##[error]Conflict (CODE: 409)\n"
+	run(fetchReleases(svc, m.CurrentProject(), 10)())
+
+	cmd := run(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("i")})
+	if m.release == nil || cmd == nil {
+		t.Fatal("release detail not opened")
+	}
+	run(cmd())
+
+	view := m.View()
+	for _, want := range []string{
+		"Attempt 1 • manual • requested for Dana Dev",
+		"Pre-deployment approval: ✓ approved by Alex Ops", "ship it",
+		"Pre-deployment gates", "Query Azure Monitor alerts",
+		"Agent job", "Download artifacts", "20s", "Deploy Azure App Service", "1m 40s", "Conflict (CODE: 409)",
+	} {
+		if !strings.Contains(view, want) {
+			t.Errorf("release detail does not contain %q:\n%s", want, view)
+		}
+	}
+
+	// The failed task is selected first and opens its log
+	cmd = run(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.release.log == nil || cmd == nil {
+		t.Fatal("task log not opened")
+	}
+	if m.release.log.task.ID != 2 {
+		t.Errorf("log of task %d opened, want the failed task 2", m.release.log.task.ID)
+	}
+	run(cmd())
+	view = m.View()
+	if !strings.Contains(view, "Starting: Deploy") || strings.Contains(view, "2024-06-10T10:00:21") {
+		t.Errorf("log not shown without timestamps:\n%s", view)
+	}
+
+	// The first task has no log configured
+	run(tea.KeyMsg{Type: tea.KeyEsc})
+	run(tea.KeyMsg{Type: tea.KeyUp})
+	cmd = run(tea.KeyMsg{Type: tea.KeyEnter})
+	run(cmd())
+	if m.release.log == nil || m.release.log.task.ID != 1 || m.release.log.err == nil {
+		t.Errorf("missing log should be shown as an error")
+	}
+
+	run(tea.KeyMsg{Type: tea.KeyEsc})
+	run(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.release != nil {
+		t.Error("release detail still open after esc")
+	}
+}
+
+func TestCreateRelease(t *testing.T) {
+	svc, m, run := newTabTest(t, TabReleases)
+	svc.Builds[testProject] = append([]api.Build{
+		{ID: 4, BuildNumber: "20240103.1", Status: api.BuildStatusCompleted, Result: api.BuildResultSucceeded,
+			Definition: api.BuildDefinition{ID: 7, Name: "payments-ci"}, SourceBranch: "refs/heads/main"},
+		{ID: 3, BuildNumber: "20240102.1", Status: api.BuildStatusCompleted, Result: api.BuildResultFailed,
+			Definition: api.BuildDefinition{ID: 7, Name: "payments-ci"}, SourceBranch: "refs/heads/main"},
+	}, svc.Builds[testProject]...)
+	svc.ReleaseDefinitions[testProject] = []api.ReleaseDefinition{{
+		ID:   2,
+		Name: "payments-cd",
+		Artifacts: []api.DefinitionArtifact{{
+			Alias:               "_payments-ci",
+			Type:                "Build",
+			DefinitionReference: map[string]api.ArtifactSourceReference{"definition": {ID: "7", Name: "payments-ci"}},
+		}},
+		Environments: []api.DefinitionEnvironment{
+			{ID: 1, Name: "dev", Conditions: []api.EnvironmentCondition{{Name: "ReleaseStarted", ConditionType: "event"}}},
+			{ID: 2, Name: "prod", Conditions: []api.EnvironmentCondition{{Name: "dev", ConditionType: "environmentState", Value: "4"}}},
+			{ID: 3, Name: "hotfix"},
+		},
+	}}
+	run(fetchReleases(svc, m.CurrentProject(), 10)())
+	press := func(k tea.KeyType) { run(tea.KeyMsg{Type: k}) }
+
+	cmd := run(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if m.createRelease == nil || cmd == nil {
+		t.Fatal("release form not opened")
+	}
+	run(cmd())
+
+	view := m.View()
+	for _, want := range []string{"New release of payments-cd", "_payments-ci", "20240103.1", "(1/2)",
+		"automatic on release creation", "automatic after dev", "manual only"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("release form does not contain %q:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "20240102.1") {
+		t.Errorf("failed build offered as a version:\n%s", view)
+	}
+
+	// Pick the older build, deploy prod manually; hotfix is manual only and stays so
+	press(tea.KeyRight)
+	press(tea.KeyTab)
+	press(tea.KeyTab)
+	press(tea.KeySpace)
+	press(tea.KeyTab)
+	press(tea.KeySpace)
+	press(tea.KeyTab)
+	run(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Refund fix")})
+	if !strings.Contains(m.View(), "manual in this release") {
+		t.Errorf("prod not shown as manual:\n%s", m.View())
+	}
+
+	svc.Errors[fake.OpCreateRelease] = errors.New("VS402962: No succeeded build found")
+	cmd = run(tea.KeyMsg{Type: tea.KeyEnter})
+	run(cmd())
+	if m.createRelease == nil || !strings.Contains(m.View(), "VS402962") {
+		t.Fatalf("error not shown in the form:\n%s", m.View())
+	}
+
+	delete(svc.Errors, fake.OpCreateRelease)
+	cmd = run(tea.KeyMsg{Type: tea.KeyEnter})
+	run(cmd())
+	if m.createRelease != nil {
+		t.Fatal("form still open after creating the release")
+	}
+	if m.release == nil || m.release.release.ID != 4 {
+		t.Errorf("details of the new release not opened")
+	}
+
+	if len(svc.NewReleases) != 1 {
+		t.Fatalf("%d releases created, want 1", len(svc.NewReleases))
+	}
+	got := svc.NewReleases[0]
+	if got.DefinitionID != 2 || got.Description != "Refund fix" {
+		t.Errorf("release request = %+v", got)
+	}
+	if len(got.Artifacts) != 1 || got.Artifacts[0].Alias != "_payments-ci" || got.Artifacts[0].InstanceReference.ID != "1" {
+		t.Errorf("artifacts = %+v, want build 1 of _payments-ci", got.Artifacts)
+	}
+	if len(got.ManualEnvironments) != 1 || got.ManualEnvironments[0] != "prod" {
+		t.Errorf("manual environments = %v, want [prod]", got.ManualEnvironments)
+	}
+}
+
+func TestChooseReleaseDefinition(t *testing.T) {
+	svc, m, run := newTabTest(t, TabReleases)
+	svc.ReleaseDefinitions[testProject] = []api.ReleaseDefinition{
+		{ID: 8, Name: "billing-cd", Path: `\Billing`},
+		{ID: 2, Name: "payments-cd", Environments: []api.DefinitionEnvironment{{ID: 1, Name: "dev"}}},
+	}
+
+	// Without releases n falls back to choosing the definition
+	cmd := run(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if m.createRelease == nil || !m.createRelease.choosing || cmd == nil {
+		t.Fatal("definition choice not opened without releases")
+	}
+	run(cmd())
+	if view := m.View(); !strings.Contains(view, "billing-cd") || !strings.Contains(view, `\Billing`) {
+		t.Errorf("definitions not listed:\n%s", view)
+	}
+	run(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.createRelease != nil {
+		t.Fatal("definition choice still open after esc")
+	}
+
+	// N starts at the definition of the selected release
+	run(fetchReleases(svc, m.CurrentProject(), 10)())
+	run(run(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("N")})())
+	if m.createRelease.chosen != 1 {
+		t.Errorf("definition %d highlighted, want payments-cd", m.createRelease.chosen)
+	}
+
+	press := func(k tea.KeyType) { run(tea.KeyMsg{Type: k}) }
+	press(tea.KeyUp)
+	run(run(tea.KeyMsg{Type: tea.KeyEnter})())
+	f := m.createRelease
+	if f == nil || f.choosing || f.loading || f.definition.ID != 8 {
+		t.Fatalf("form not filled with the chosen definition: %+v", f)
+	}
+	if view := m.View(); !strings.Contains(view, "New release of billing-cd") {
+		t.Errorf("form not shown for billing-cd:\n%s", view)
+	}
+
+	svc.SetError(fake.OpGetReleaseDefinitions, errors.New("access denied"))
+	press(tea.KeyEsc)
+	run(run(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("N")})())
+	if view := m.View(); !strings.Contains(view, "Error: access denied") {
+		t.Errorf("listing error not shown:\n%s", view)
+	}
+}
+
+func TestDefinitionLookupRetried(t *testing.T) {
+	svc, m, run := newTabTest(t, TabBuilds)
+	m.config.Projects[0].BuildSelectors = config.DefinitionSelectors{{Pattern: "payments-*"}}
+	svc.BuildDefinitions[testProject] = []api.BuildDefinition{{ID: 7, Name: "payments-ci"}}
+
+	// The lookup failed before the dashboard started, so the project has no definitions yet
+	m.AddWarnings("contoso/Payments: release definition nightly matches no definition")
+	m.SetDefinitionsError(errors.New("failed to list build definitions of Payments: unauthorized"))
+	run(fetchBuilds(svc, m.CurrentProject(), branchFilter{}, 10)())
+	view := m.View()
+	for _, want := range []string{"release definition nightly matches no definition", "Definition lookup failed", "unauthorized", "No builds found"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view does not contain %q:\n%s", want, view)
+		}
+	}
+
+	svc.SetError(fake.OpGetBuildDefinitions, errors.New("service unavailable"))
+	run(resolveDefinitions(m.clients, m.config.Projects)())
+	if view := m.View(); !strings.Contains(view, "service unavailable") || strings.Contains(view, "unauthorized") {
+		t.Errorf("failed retry not shown:\n%s", view)
+	}
+
+	// A successful lookup clears the error and fetches the builds right away
+	svc.SetError(fake.OpGetBuildDefinitions, nil)
+	cmd := run(resolveDefinitions(m.clients, m.config.Projects)())
+	if m.definitionsErr != nil || cmd == nil {
+		t.Fatalf("definitions error = %v, builds fetched = %v", m.definitionsErr, cmd != nil)
+	}
+	batch, ok := cmd().(tea.BatchMsg)
+	if !ok || len(batch) != 1 {
+		t.Fatalf("lookup returned %T, want the builds fetch only", batch)
+	}
+	run(batch[0]())
+	if view := m.View(); strings.Contains(view, "Definition lookup failed") || !strings.Contains(view, "payments-ci") {
+		t.Errorf("builds not shown after the lookup succeeded:\n%s", view)
+	}
+	if !strings.Contains(m.View(), "matches no definition") {
+		t.Error("startup warning no longer shown")
+	}
+}
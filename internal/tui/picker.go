@@ -0,0 +1,309 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/api"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/config"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/styles"
+)
+
+// pickerMaxResults is the number of matching projects shown in the picker
+const pickerMaxResults = 15
+
+// pickerItem is a project offered by the project picker
+type pickerItem struct {
+	Organization string
+	Name         string
+}
+
+// projectPicker is the state of the project picker, which fuzzy-searches every project
+// of the configured organizations and adds the chosen one to the session
+type projectPicker struct {
+	input    textinput.Model
+	items    []pickerItem
+	matches  []pickerItem
+	selected int
+	pending  int              // Organizations whose project list has not arrived yet
+	errors   map[string]error // organization -> error listing its projects
+	seq      int              // Request sequence number of the project lists this picker waits for
+}
+
+// ProjectsListedMsg is sent when the projects of an organization have been listed for the picker.
+// Seq identifies the picker that requested the list, so lists for a closed picker are dropped.
+type ProjectsListedMsg struct {
+	Organization string
+	Projects     []api.TeamProject
+	Err          error
+	Seq          int
+}
+
+// listProjects creates a command to list the projects of an organization
+func listProjects(client api.Service, organization string, seq int) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		projects, err := client.GetProjects(ctx)
+		return ProjectsListedMsg{Organization: organization, Projects: projects, Err: err, Seq: seq}
+	}
+}
+
+// openPicker opens the project picker and starts listing the projects of every organization
+func (m Model) openPicker() (tea.Model, tea.Cmd) {
+	input := textinput.New()
+	input.Placeholder = "Search projects"
+	input.Prompt = "> "
+	input.Focus()
+
+	m.pickerSeq++
+	m.picker = &projectPicker{
+		input:  input,
+		errors: make(map[string]error),
+		seq:    m.pickerSeq,
+	}
+
+	cmds := []tea.Cmd{textinput.Blink}
+	for _, organization := range m.organizations() {
+		if client, ok := m.clients[organization]; ok {
+			cmds = append(cmds, listProjects(client, organization, m.pickerSeq))
+			m.picker.pending++
+		}
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// handleProjectsListed adds the listed projects that are not shown yet to the picker
+func (m Model) handleProjectsListed(msg ProjectsListedMsg) (tea.Model, tea.Cmd) {
+	// Drop lists requested by a picker that has been closed since
+	if m.picker == nil || msg.Seq != m.picker.seq {
+		return m, nil
+	}
+	m.picker.pending--
+
+	if msg.Err != nil {
+		m.picker.errors[msg.Organization] = msg.Err
+		return m, nil
+	}
+
+	for _, p := range msg.Projects {
+		if _, ok := m.projectIndex(config.ProjectConfig{Organization: msg.Organization, Name: p.Name}); ok {
+			continue
+		}
+		m.picker.items = append(m.picker.items, pickerItem{Organization: msg.Organization, Name: p.Name})
+	}
+	m.picker.filter()
+	return m, nil
+}
+
+// handlePickerKey handles keyboard input while the project picker is open
+func (m Model) handlePickerKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+
+	case tea.KeyEsc:
+		m.picker = nil
+		return m, nil
+
+	case tea.KeyUp:
+		if m.picker.selected > 0 {
+			m.picker.selected--
+		}
+		return m, nil
+
+	case tea.KeyDown:
+		if m.picker.selected < min(len(m.picker.matches), pickerMaxResults)-1 {
+			m.picker.selected++
+		}
+		return m, nil
+
+	case tea.KeyEnter:
+		if m.picker.selected >= len(m.picker.matches) {
+			return m, nil
+		}
+		item := m.picker.matches[m.picker.selected]
+		m.picker = nil
+		return m.addProject(config.ProjectConfig{Organization: item.Organization, Name: item.Name})
+	}
+
+	var cmd tea.Cmd
+	m.picker.input, cmd = m.picker.input.Update(msg)
+	m.picker.filter()
+	return m, cmd
+}
+
+// addProject adds a project to the session, switches to it and fetches its data
+func (m Model) addProject(project config.ProjectConfig) (tea.Model, tea.Cmd) {
+	if i, ok := m.projectIndex(project); ok {
+		m.activeProject = i
+		m.selectedRow = 0
+		return m, nil
+	}
+
+	added := false
+	for i := range m.config.Organizations {
+		if m.config.Organizations[i].Name == project.Organization {
+			m.config.Organizations[i].Projects = append(m.config.Organizations[i].Projects, project)
+			m.config.CollectProjects()
+			added = true
+			break
+		}
+	}
+	if !added {
+		m.config.Projects = append(m.config.Projects, project)
+	}
+
+	m.activeProject, _ = m.projectIndex(project)
+	m.selectedRow = 0
+
+	key := project.Key()
+	m.loadingBuilds[key] = true
+	m.loadingReleases[key] = true
+	m.loadingPullRequests[key] = true
+
+	client := m.clientFor(project)
+	maxItems := m.config.Display.MaxItemsPerProject
+	return m, tea.Batch(
+		fetchBuilds(client, project, m.prFilter.Branch, maxItems),
+		fetchReleases(client, project, maxItems),
+		fetchPullRequests(client, project, m.prFilter, m.users, maxItems),
+	)
+}
+
+// projectIndex returns the position of a project in the session
+func (m Model) projectIndex(project config.ProjectConfig) (int, bool) {
+	for i, p := range m.config.Projects {
+		if p.Organization == project.Organization && strings.EqualFold(p.Name, project.Name) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// filter updates the matches for the current query, best matches first
+func (p *projectPicker) filter() {
+	query := p.input.Value()
+
+	type scored struct {
+		item  pickerItem
+		score int
+	}
+	var results []scored
+	for _, item := range p.items {
+		if score, ok := fuzzyScore(query, item.Name); ok {
+			results = append(results, scored{item, score})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].score != results[j].score {
+			return results[i].score > results[j].score
+		}
+		return strings.ToLower(results[i].item.Name) < strings.ToLower(results[j].item.Name)
+	})
+
+	p.matches = p.matches[:0]
+	for _, r := range results {
+		p.matches = append(p.matches, r.item)
+	}
+	if p.selected >= len(p.matches) {
+		p.selected = max(len(p.matches)-1, 0)
+	}
+}
+
+// fuzzyScore reports whether query matches s as a case-insensitive subsequence and how well.
+// Consecutive characters and characters at the start of words score higher.
+func fuzzyScore(query, s string) (int, bool) {
+	if query == "" {
+		return 0, true
+	}
+
+	q := []rune(strings.ToLower(query))
+	runes := []rune(s)
+	score, qi := 0, 0
+	prevMatch := -2
+
+	for i, r := range runes {
+		if qi == len(q) {
+			break
+		}
+		if unicode.ToLower(r) != q[qi] {
+			continue
+		}
+
+		score++
+		if prevMatch == i-1 {
+			score += 3
+		}
+		if i == 0 || (!unicode.IsLetter(runes[i-1]) && !unicode.IsDigit(runes[i-1])) ||
+			(unicode.IsUpper(r) && unicode.IsLower(runes[i-1])) {
+			score += 2
+		}
+		prevMatch = i
+		qi++
+	}
+
+	if qi < len(q) {
+		return 0, false
+	}
+	// Prefer shorter names for equally good matches
+	return score*100 - len(runes), true
+}
+
+// renderPicker renders the project picker
+func (m Model) renderPicker() string {
+	var b strings.Builder
+
+	b.WriteString(styles.ActiveTabStyle.Render("► Add project"))
+	b.WriteString("\n")
+	b.WriteString(m.picker.input.View())
+	b.WriteString("\n\n")
+
+	multipleOrganizations := len(m.clients) > 1
+	for i, item := range m.picker.matches {
+		if i == pickerMaxResults {
+			b.WriteString(styles.HelpStyle.Render(fmt.Sprintf("  … %d more", len(m.picker.matches)-pickerMaxResults)))
+			b.WriteString("\n")
+			break
+		}
+
+		label := item.Name
+		if multipleOrganizations {
+			label = item.Organization + "/" + item.Name
+		}
+		row := "  " + label
+		if i == m.picker.selected {
+			row = styles.SelectedRowStyle.Render("> " + label)
+		}
+		b.WriteString(row)
+		b.WriteString("\n")
+	}
+
+	switch {
+	case m.picker.pending > 0:
+		b.WriteString(m.spinner.View())
+		b.WriteString(" Loading projects...\n")
+	case len(m.picker.matches) == 0:
+		b.WriteString(styles.HelpStyle.Render("No matching projects"))
+		b.WriteString("\n")
+	}
+
+	for _, organization := range m.organizations() {
+		if err, ok := m.picker.errors[organization]; ok {
+			b.WriteString(styles.ErrorStyle.Render(fmt.Sprintf("Error listing projects of %s: %v", organization, err)))
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(styles.HelpStyle.Render("enter add project • esc cancel"))
+	return b.String()
+}
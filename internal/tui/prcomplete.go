@@ -0,0 +1,291 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/api"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/config"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/styles"
+)
+
+// mergeStrategies is the order in which the merge strategy field cycles
+var mergeStrategies = []api.MergeStrategy{
+	api.MergeStrategyNoFastForward,
+	api.MergeStrategySquash,
+	api.MergeStrategyRebase,
+}
+
+// Fields of the completion form, in display order
+const (
+	completeFieldStrategy = iota
+	completeFieldDeleteBranch
+	completeFieldWorkItems
+	completeFieldAction
+	completeFieldCount
+)
+
+// completeAction is what the completion form does when confirmed
+type completeAction int
+
+const (
+	completeActionNow                completeAction = iota // Merge the pull request now
+	completeActionAutoComplete                             // Merge it once its policies pass
+	completeActionCancelAutoComplete                       // Stop merging it automatically
+)
+
+// String returns the action as shown in the completion form
+func (a completeAction) String() string {
+	switch a {
+	case completeActionAutoComplete:
+		return "set auto-complete (complete when policies pass)"
+	case completeActionCancelAutoComplete:
+		return "cancel auto-complete"
+	default:
+		return "complete now"
+	}
+}
+
+// completeForm is the state of the completion form, which merges the selected pull request
+// or sets it to auto-complete once its policies pass
+type completeForm struct {
+	project             config.ProjectConfig
+	pr                  api.PullRequest
+	strategy            int // Index into mergeStrategies
+	deleteSourceBranch  bool
+	transitionWorkItems bool
+	action              completeAction
+	field               int // Focused field
+	sending             bool
+	err                 error
+}
+
+// PullRequestCompletedMsg is sent when a pull request has been completed, or auto-complete
+// has been set or cancelled
+type PullRequestCompletedMsg struct {
+	Project     string
+	PullRequest *api.PullRequest
+	Err         error
+}
+
+// actions returns the actions available for the pull request, in the order the form cycles them
+func (f *completeForm) actions() []completeAction {
+	var actions []completeAction
+	// Completing now is only possible once the pull request is approved and its policies pass
+	if f.pr.CanComplete() {
+		actions = append(actions, completeActionNow)
+	}
+	actions = append(actions, completeActionAutoComplete)
+	if f.pr.IsAutoComplete() {
+		actions = append(actions, completeActionCancelAutoComplete)
+	}
+	return actions
+}
+
+// options returns the completion options chosen in the form
+func (f *completeForm) options() api.CompletionOptions {
+	return api.CompletionOptions{
+		MergeStrategy:       mergeStrategies[f.strategy],
+		DeleteSourceBranch:  f.deleteSourceBranch,
+		TransitionWorkItems: f.transitionWorkItems,
+	}
+}
+
+// completePullRequest creates a command to complete a pull request, or to set or cancel
+// auto-complete on behalf of the signed-in user
+func completePullRequest(client api.Service, project config.ProjectConfig, pr api.PullRequest, users *identities, action completeAction, options api.CompletionOptions) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		msg := PullRequestCompletedMsg{Project: project.Key()}
+		switch action {
+		case completeActionAutoComplete:
+			userID, err := users.userID(ctx, client, project.Organization)
+			if err != nil {
+				msg.Err = err
+				return msg
+			}
+			msg.PullRequest, msg.Err = client.SetAutoComplete(ctx, project.Name, repositoryOf(pr), pr.PullRequestID, userID, options)
+		case completeActionCancelAutoComplete:
+			msg.PullRequest, msg.Err = client.SetAutoComplete(ctx, project.Name, repositoryOf(pr), pr.PullRequestID, "", options)
+		default:
+			msg.PullRequest, msg.Err = client.CompletePullRequest(ctx, project.Name, repositoryOf(pr), pr.PullRequestID,
+				pr.LastMergeSourceCommit.CommitID, options)
+		}
+		return msg
+	}
+}
+
+// openCompleteForm opens the completion form for the selected pull request
+func (m Model) openCompleteForm() (tea.Model, tea.Cmd) {
+	pullRequests := m.CurrentPullRequests()
+	if m.selectedRow < 0 || m.selectedRow >= len(pullRequests) {
+		return m, nil
+	}
+	pr := pullRequests[m.selectedRow]
+	if !pr.CanAutoComplete() {
+		return m, nil
+	}
+
+	f := &completeForm{project: m.CurrentProject(), pr: pr, transitionWorkItems: true}
+	if pr.CompletionOptions != nil {
+		// Start from the options auto-complete was set with
+		for i, s := range mergeStrategies {
+			if s == pr.CompletionOptions.MergeStrategy {
+				f.strategy = i
+			}
+		}
+		f.deleteSourceBranch = pr.CompletionOptions.DeleteSourceBranch
+		f.transitionWorkItems = pr.CompletionOptions.TransitionWorkItems
+	}
+	// Pull requests that cannot be merged yet can only be set to auto-complete
+	f.action = f.actions()[0]
+	m.complete = f
+	return m, nil
+}
+
+// handlePullRequestCompleted closes the form and fetches the pull requests of the project again
+func (m Model) handlePullRequestCompleted(msg PullRequestCompletedMsg) (tea.Model, tea.Cmd) {
+	if m.complete == nil || m.complete.project.Key() != msg.Project {
+		return m, nil
+	}
+	if msg.Err != nil {
+		m.complete.sending = false
+		m.complete.err = msg.Err
+		return m, nil
+	}
+
+	project := m.complete.project
+	m.complete = nil
+	if m.loadingPullRequests[msg.Project] {
+		return m, nil
+	}
+	m.loadingPullRequests[msg.Project] = true
+	return m, fetchPullRequests(m.clientFor(project), project, m.prFilter, m.users, m.config.Display.MaxItemsPerProject)
+}
+
+// handleCompleteKey handles keyboard input while the completion form is open
+func (m Model) handleCompleteKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	f := m.complete
+	if f.sending {
+		if msg.Type == tea.KeyCtrlC {
+			return m, tea.Quit
+		}
+		return m, nil
+	}
+
+	switch {
+	case msg.Type == tea.KeyEsc:
+		m.complete = nil
+		return m, nil
+
+	case key.Matches(msg, m.keys.Quit):
+		return m, tea.Quit
+
+	case key.Matches(msg, m.keys.Up) || msg.Type == tea.KeyShiftTab:
+		f.field = (f.field + completeFieldCount - 1) % completeFieldCount
+
+	case key.Matches(msg, m.keys.Down) || key.Matches(msg, m.keys.Tab):
+		f.field = (f.field + 1) % completeFieldCount
+
+	case key.Matches(msg, m.keys.Left):
+		f.change(-1)
+
+	case key.Matches(msg, m.keys.Right) || msg.Type == tea.KeySpace:
+		f.change(1)
+
+	case key.Matches(msg, m.keys.Enter):
+		if f.action == completeActionNow && !f.pr.CanComplete() {
+			return m, nil
+		}
+		f.sending = true
+		f.err = nil
+		return m, completePullRequest(m.clientFor(f.project), f.project, f.pr, m.users, f.action, f.options())
+	}
+
+	return m, nil
+}
+
+// change cycles the merge strategy or toggles the focused option
+func (f *completeForm) change(delta int) {
+	switch f.field {
+	case completeFieldStrategy:
+		f.strategy = (f.strategy + len(mergeStrategies) + delta) % len(mergeStrategies)
+	case completeFieldDeleteBranch:
+		f.deleteSourceBranch = !f.deleteSourceBranch
+	case completeFieldWorkItems:
+		f.transitionWorkItems = !f.transitionWorkItems
+	case completeFieldAction:
+		actions := f.actions()
+		for i, a := range actions {
+			if a == f.action {
+				f.action = actions[(i+len(actions)+delta)%len(actions)]
+				return
+			}
+		}
+	}
+}
+
+// renderCompleteForm renders the completion form
+func (m Model) renderCompleteForm() string {
+	f := m.complete
+	pr := f.pr
+	var b strings.Builder
+
+	b.WriteString(styles.ActiveTabStyle.Render(fmt.Sprintf("► Complete pull request !%d: %s", pr.PullRequestID, pr.Title)))
+	b.WriteString("\n")
+	b.WriteString(styles.HelpStyle.Render(fmt.Sprintf("%s • %s • reviewers %s • policies %s",
+		pr.GetBranchSummary(), pr.GetStatusDisplay(), pr.GetReviewerSummary(), pr.GetPolicySummary())))
+	b.WriteString("\n\n")
+
+	checkbox := func(checked bool) string {
+		if checked {
+			return "[x]"
+		}
+		return "[ ]"
+	}
+	fields := []string{
+		fmt.Sprintf("Merge strategy:        ‹ %s ›", mergeStrategies[f.strategy].GetDisplay()),
+		fmt.Sprintf("Delete source branch:  %s %s", checkbox(f.deleteSourceBranch), pr.GetSourceBranch()),
+		fmt.Sprintf("Complete work items:   %s", checkbox(f.transitionWorkItems)),
+		fmt.Sprintf("Action:                ‹ %s ›", f.action),
+	}
+	for i, field := range fields {
+		if i == f.field {
+			b.WriteString(styles.SelectedRowStyle.Render("> " + field))
+		} else {
+			b.WriteString("  " + field)
+		}
+		b.WriteString("\n")
+	}
+
+	if blockers := pr.CompletionBlockers(); len(blockers) > 0 {
+		b.WriteString("\n")
+		b.WriteString(styles.HelpStyle.Render("Cannot complete now:"))
+		b.WriteString("\n")
+		for _, reason := range blockers {
+			b.WriteString("  " + styles.CanceledStyle.Render("○") + " " + reason)
+			b.WriteString("\n")
+		}
+	}
+
+	if f.sending {
+		b.WriteString("\n")
+		b.WriteString(m.spinner.View())
+		b.WriteString(" Sending...\n")
+	}
+	if f.err != nil {
+		b.WriteString("\n")
+		b.WriteString(styles.ErrorStyle.Render(fmt.Sprintf("Error: %v", f.err)))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(styles.HelpStyle.Render("↑/↓ select option • ←/→/space change • enter confirm • esc cancel"))
+	return b.String()
+}
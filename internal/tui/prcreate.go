@@ -0,0 +1,356 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/api"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/config"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/localgit"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/styles"
+)
+
+// Fields of the create form, in display order
+const (
+	createFieldTarget = iota
+	createFieldTitle
+	createFieldDescription
+	createFieldReviewers
+	createFieldDraft
+	createFieldCount
+)
+
+// createForm is the state of the form that creates a pull request from the checked out branch
+type createForm struct {
+	project     config.ProjectConfig
+	repo        *localgit.Repository
+	target      textinput.Model
+	title       textinput.Model
+	description textarea.Model
+	reviewers   textinput.Model // Comma-separated names or e-mail addresses
+	draft       bool
+	field       int  // Focused field
+	loading     bool // Fetching the default branch of the repository
+	sending     bool
+	err         error
+}
+
+// RepositoryLoadedMsg is sent when the repository of the create form has been fetched
+type RepositoryLoadedMsg struct {
+	Project    string
+	Repository *api.GitRepository
+	Err        error
+}
+
+// PullRequestCreatedMsg is sent when a pull request has been created
+type PullRequestCreatedMsg struct {
+	Project     string
+	PullRequest *api.PullRequest
+	Err         error
+}
+
+// fetchRepository creates a command to fetch a repository, for its default branch
+func fetchRepository(client api.Service, project config.ProjectConfig, repositoryID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		repo, err := client.GetRepository(ctx, project.Name, repositoryID)
+		return RepositoryLoadedMsg{Project: project.Key(), Repository: repo, Err: err}
+	}
+}
+
+// createPullRequest creates a command that checks the source branch was pushed, resolves the
+// reviewers and creates the pull request
+func createPullRequest(client api.Service, project config.ProjectConfig, repositoryID string, pr api.NewPullRequest, reviewers []string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		msg := PullRequestCreatedMsg{Project: project.Key()}
+		pushed, err := client.BranchExists(ctx, project.Name, repositoryID, pr.SourceRefName)
+		if err != nil {
+			msg.Err = fmt.Errorf("failed to check the source branch: %w", err)
+			return msg
+		}
+		if !pushed {
+			msg.Err = fmt.Errorf("branch %s is not in %s, push the branch first",
+				strings.TrimPrefix(pr.SourceRefName, "refs/heads/"), repositoryID)
+			return msg
+		}
+
+		for _, name := range reviewers {
+			identity, err := findReviewer(ctx, client, name)
+			if err != nil {
+				msg.Err = err
+				return msg
+			}
+			pr.Reviewers = append(pr.Reviewers, api.Reviewer{ID: identity.ID, DisplayName: identity.ProviderDisplayName})
+		}
+
+		msg.PullRequest, msg.Err = client.CreatePullRequest(ctx, project.Name, repositoryID, pr)
+		return msg
+	}
+}
+
+// findReviewer resolves a reviewer by name or e-mail address. A search matching several
+// identities is only accepted when one of them matches exactly.
+func findReviewer(ctx context.Context, client api.Service, name string) (*api.ConnectionIdentity, error) {
+	found, err := client.FindIdentities(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up reviewer %q: %w", name, err)
+	}
+	if len(found) == 1 {
+		return &found[0], nil
+	}
+	for _, identity := range found {
+		if strings.EqualFold(identity.ProviderDisplayName, name) ||
+			strings.EqualFold(identity.Properties.Account.Value, name) ||
+			strings.EqualFold(identity.Properties.Mail.Value, name) {
+			return &identity, nil
+		}
+	}
+	if len(found) == 0 {
+		return nil, fmt.Errorf("reviewer %q not found", name)
+	}
+	return nil, fmt.Errorf("reviewer %q is ambiguous (%d matches), use the e-mail address", name, len(found))
+}
+
+// openCreateForm opens the create form for the checked out branch, prefilled from the last commit
+func (m Model) openCreateForm() (tea.Model, tea.Cmd) {
+	project, ok := m.localProject()
+	if !ok || m.local.Branch == "" {
+		return m, nil
+	}
+
+	newInput := func(placeholder string, limit int) textinput.Model {
+		input := textinput.New()
+		input.Placeholder = placeholder
+		input.Prompt = ""
+		input.CharLimit = limit
+		return input
+	}
+	f := &createForm{
+		project:   project,
+		repo:      m.local,
+		target:    newInput("loading default branch...", 250),
+		title:     newInput("Title", 400),
+		reviewers: newInput("names or e-mail addresses, comma-separated", 1000),
+		loading:   true,
+	}
+	f.title.SetValue(m.local.LastCommitSubject)
+	f.description = textarea.New()
+	f.description.Placeholder = "Description"
+	f.description.ShowLineNumbers = false
+	f.description.CharLimit = 4000
+	f.description.SetWidth(max(m.width-8, 40))
+	f.description.SetHeight(5)
+	f.description.SetValue(m.local.LastCommitBody)
+	f.field = createFieldTitle
+	f.focus()
+
+	m.create = f
+	return m, tea.Batch(textinput.Blink, fetchRepository(m.clientFor(project), project, m.local.Remote.Repository))
+}
+
+// handleRepositoryLoaded prefills the target branch with the default branch of the repository
+func (m Model) handleRepositoryLoaded(msg RepositoryLoadedMsg) (tea.Model, tea.Cmd) {
+	f := m.create
+	if f == nil || f.project.Key() != msg.Project {
+		return m, nil
+	}
+	f.loading = false
+	f.target.Placeholder = "target branch"
+	if msg.Err != nil {
+		f.err = msg.Err
+		return m, nil
+	}
+	if f.target.Value() == "" {
+		f.target.SetValue(strings.TrimPrefix(msg.Repository.DefaultBranch, "refs/heads/"))
+	}
+	return m, nil
+}
+
+// handlePullRequestCreated closes the form and shows the new pull request in the list
+func (m Model) handlePullRequestCreated(msg PullRequestCreatedMsg) (tea.Model, tea.Cmd) {
+	if m.create == nil || m.create.project.Key() != msg.Project {
+		return m, nil
+	}
+	if msg.Err != nil {
+		m.create.sending = false
+		m.create.err = msg.Err
+		return m, nil
+	}
+
+	project := m.create.project
+	m.create = nil
+	m.activeTab = TabPullRequests
+	m.selectedRow = 0
+	if m.loadingPullRequests[msg.Project] {
+		return m, nil
+	}
+	m.loadingPullRequests[msg.Project] = true
+	return m, fetchPullRequests(m.clientFor(project), project, m.prFilter, m.users, m.config.Display.MaxItemsPerProject)
+}
+
+// handleCreateKey handles keyboard input while the create form is open. Tab moves between
+// fields, enter submits except in the description, where it starts a new line.
+func (m Model) handleCreateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	f := m.create
+	if msg.Type == tea.KeyCtrlC {
+		return m, tea.Quit
+	}
+	if f.sending {
+		return m, nil
+	}
+
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.create = nil
+		return m, nil
+
+	case tea.KeyTab:
+		f.field = (f.field + 1) % createFieldCount
+		return m, f.focus()
+
+	case tea.KeyShiftTab:
+		f.field = (f.field + createFieldCount - 1) % createFieldCount
+		return m, f.focus()
+
+	case tea.KeyCtrlS:
+		return m.submitCreateForm()
+
+	case tea.KeyEnter:
+		if f.field != createFieldDescription {
+			return m.submitCreateForm()
+		}
+	}
+
+	var cmd tea.Cmd
+	switch f.field {
+	case createFieldTarget:
+		f.target, cmd = f.target.Update(msg)
+	case createFieldTitle:
+		f.title, cmd = f.title.Update(msg)
+	case createFieldDescription:
+		f.description, cmd = f.description.Update(msg)
+	case createFieldReviewers:
+		f.reviewers, cmd = f.reviewers.Update(msg)
+	case createFieldDraft:
+		if msg.Type == tea.KeySpace {
+			f.draft = !f.draft
+		}
+	}
+	return m, cmd
+}
+
+// submitCreateForm validates the form and creates the pull request
+func (m Model) submitCreateForm() (tea.Model, tea.Cmd) {
+	f := m.create
+	target := strings.TrimPrefix(strings.TrimSpace(f.target.Value()), "refs/heads/")
+	title := strings.TrimSpace(f.title.Value())
+	switch {
+	case target == "":
+		f.err = fmt.Errorf("the target branch is required")
+		return m, nil
+	case target == f.repo.Branch:
+		f.err = fmt.Errorf("the source and target branch are both %s", target)
+		return m, nil
+	case title == "":
+		f.err = fmt.Errorf("the title is required")
+		return m, nil
+	}
+
+	var reviewers []string
+	for _, name := range strings.Split(f.reviewers.Value(), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			reviewers = append(reviewers, name)
+		}
+	}
+
+	pr := api.NewPullRequest{
+		SourceRefName: f.repo.BranchRef(),
+		TargetRefName: "refs/heads/" + target,
+		Title:         title,
+		Description:   strings.TrimSpace(f.description.Value()),
+		IsDraft:       f.draft,
+	}
+	f.sending = true
+	f.err = nil
+	return m, createPullRequest(m.clientFor(f.project), f.project, f.repo.Remote.Repository, pr, reviewers)
+}
+
+// focus focuses the input of the current field and blurs the others
+func (f *createForm) focus() tea.Cmd {
+	f.target.Blur()
+	f.title.Blur()
+	f.description.Blur()
+	f.reviewers.Blur()
+
+	switch f.field {
+	case createFieldTarget:
+		return f.target.Focus()
+	case createFieldTitle:
+		return f.title.Focus()
+	case createFieldDescription:
+		return f.description.Focus()
+	case createFieldReviewers:
+		return f.reviewers.Focus()
+	}
+	return nil
+}
+
+// renderCreateForm renders the create form
+func (m Model) renderCreateForm() string {
+	f := m.create
+	var b strings.Builder
+
+	b.WriteString(styles.ActiveTabStyle.Render(fmt.Sprintf("► New pull request in %s/%s", f.project.Name, f.repo.Remote.Repository)))
+	b.WriteString("\n")
+	b.WriteString(styles.HelpStyle.Render(fmt.Sprintf("Source branch %s (local checkout %s)", f.repo.Branch, f.repo.Root)))
+	b.WriteString("\n\n")
+
+	label := func(field int, name string) string {
+		if field == f.field {
+			return styles.SelectedRowStyle.Render("> " + name)
+		}
+		return "  " + name
+	}
+	checkbox := "[ ]"
+	if f.draft {
+		checkbox = "[x]"
+	}
+
+	target := f.target.View()
+	if f.loading && f.target.Value() == "" {
+		target = m.spinner.View() + " Loading default branch..."
+	}
+	b.WriteString(label(createFieldTarget, "Target branch: ") + target + "\n")
+	b.WriteString(label(createFieldTitle, "Title:         ") + f.title.View() + "\n")
+	b.WriteString(label(createFieldDescription, "Description:") + "\n")
+	for _, line := range strings.Split(f.description.View(), "\n") {
+		b.WriteString("    " + line + "\n")
+	}
+	b.WriteString(label(createFieldReviewers, "Reviewers:     ") + f.reviewers.View() + "\n")
+	b.WriteString(label(createFieldDraft, "Draft:         ") + checkbox + "\n")
+
+	if f.sending {
+		b.WriteString("\n")
+		b.WriteString(m.spinner.View())
+		b.WriteString(" Creating pull request...\n")
+	}
+	if f.err != nil {
+		b.WriteString("\n")
+		b.WriteString(styles.ErrorStyle.Render(fmt.Sprintf("Error: %v", f.err)))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(styles.HelpStyle.Render("tab/shift+tab select field • space toggle draft • enter (ctrl+s in description) create • esc cancel"))
+	return b.String()
+}
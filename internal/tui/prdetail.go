@@ -0,0 +1,427 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/api"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/config"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/styles"
+)
+
+const (
+	// detailMaxDescriptionLines is the number of description lines shown in the detail pane
+	detailMaxDescriptionLines = 8
+	// detailMaxThreads is the number of threads listed around the selected one
+	detailMaxThreads = 10
+)
+
+// pullRequestDetail is the state of the pull request detail pane, which shows the
+// description, reviewers and active comment threads of the selected pull request
+type pullRequestDetail struct {
+	project  config.ProjectConfig
+	pr       api.PullRequest     // From the list until the full pull request has been fetched
+	threads  []api.CommentThread // Active threads, oldest first
+	loading  bool
+	sending  bool // A reply or status change is in flight
+	selected int  // Selected thread
+	replying bool
+	input    textinput.Model
+	err      error
+}
+
+// PullRequestDetailMsg is sent when the details of a pull request have been fetched
+type PullRequestDetailMsg struct {
+	PullRequestID int
+	PullRequest   *api.PullRequest
+	Threads       []api.CommentThread
+	Err           error
+}
+
+// ThreadUpdatedMsg is sent when a reply has been posted or a thread status has been changed.
+// Threads holds all threads of the pull request, fetched again after the change.
+type ThreadUpdatedMsg struct {
+	PullRequestID int
+	Threads       []api.CommentThread
+	Err           error
+}
+
+// repositoryOf returns the repository identifier used in pull request API URLs
+func repositoryOf(pr api.PullRequest) string {
+	if pr.Repository.ID != "" {
+		return pr.Repository.ID
+	}
+	return pr.Repository.Name
+}
+
+// fetchPullRequestDetail creates a command to fetch a pull request and its comment threads
+func fetchPullRequestDetail(client api.Service, project config.ProjectConfig, pr api.PullRequest) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		full, err := client.GetPullRequest(ctx, project.Name, repositoryOf(pr), pr.PullRequestID)
+		if err != nil {
+			return PullRequestDetailMsg{PullRequestID: pr.PullRequestID, Err: err}
+		}
+		// Policy evaluations are fetched with the list only
+		full.Policies = pr.Policies
+		full.PolicyError = pr.PolicyError
+
+		threads, err := client.GetPullRequestThreads(ctx, project.Name, repositoryOf(pr), pr.PullRequestID)
+		if err != nil {
+			return PullRequestDetailMsg{PullRequestID: pr.PullRequestID, PullRequest: full, Err: err}
+		}
+
+		return PullRequestDetailMsg{
+			PullRequestID: pr.PullRequestID,
+			PullRequest:   full,
+			Threads:       threads,
+		}
+	}
+}
+
+// updateThread creates a command that applies a change to a thread and fetches the threads again
+func updateThread(client api.Service, project config.ProjectConfig, pr api.PullRequest, change func(ctx context.Context) error) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if err := change(ctx); err != nil {
+			return ThreadUpdatedMsg{PullRequestID: pr.PullRequestID, Err: err}
+		}
+
+		threads, err := client.GetPullRequestThreads(ctx, project.Name, repositoryOf(pr), pr.PullRequestID)
+		return ThreadUpdatedMsg{PullRequestID: pr.PullRequestID, Threads: threads, Err: err}
+	}
+}
+
+// openDetail opens the detail pane for the selected pull request
+func (m Model) openDetail() (tea.Model, tea.Cmd) {
+	pullRequests := m.CurrentPullRequests()
+	if m.selectedRow < 0 || m.selectedRow >= len(pullRequests) {
+		return m, nil
+	}
+
+	project := m.CurrentProject()
+	pr := pullRequests[m.selectedRow]
+	m.detail = &pullRequestDetail{project: project, pr: pr, loading: true}
+	return m, fetchPullRequestDetail(m.clientFor(project), project, pr)
+}
+
+// handleDetailLoaded shows the fetched pull request and its active threads
+func (m Model) handleDetailLoaded(msg PullRequestDetailMsg) (tea.Model, tea.Cmd) {
+	if m.detail == nil || m.detail.pr.PullRequestID != msg.PullRequestID {
+		return m, nil
+	}
+	m.detail.loading = false
+	m.detail.err = msg.Err
+	if msg.PullRequest != nil {
+		m.detail.pr = *msg.PullRequest
+	}
+	if msg.Err == nil {
+		m.detail.setThreads(msg.Threads)
+	}
+	return m, nil
+}
+
+// handleThreadUpdated shows the threads fetched after a reply or status change
+func (m Model) handleThreadUpdated(msg ThreadUpdatedMsg) (tea.Model, tea.Cmd) {
+	if m.detail == nil || m.detail.pr.PullRequestID != msg.PullRequestID {
+		return m, nil
+	}
+	m.detail.sending = false
+	m.detail.err = msg.Err
+	if msg.Err == nil {
+		m.detail.setThreads(msg.Threads)
+	}
+	return m, nil
+}
+
+// setThreads keeps the active threads and keeps the selection in range
+func (d *pullRequestDetail) setThreads(threads []api.CommentThread) {
+	d.threads = api.ActiveThreads(threads)
+	if d.selected >= len(d.threads) {
+		d.selected = max(len(d.threads)-1, 0)
+	}
+}
+
+// selectedThread returns the selected thread, nil when there are no active threads
+func (d *pullRequestDetail) selectedThread() *api.CommentThread {
+	if d.selected < 0 || d.selected >= len(d.threads) {
+		return nil
+	}
+	return &d.threads[d.selected]
+}
+
+// handleDetailKey handles keyboard input while the detail pane is open
+func (m Model) handleDetailKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	d := m.detail
+	if d.replying {
+		return m.handleReplyKey(msg)
+	}
+
+	switch {
+	case msg.Type == tea.KeyEsc:
+		m.detail = nil
+		return m, nil
+
+	case key.Matches(msg, m.keys.Quit):
+		return m, tea.Quit
+
+	case key.Matches(msg, m.keys.Up):
+		if d.selected > 0 {
+			d.selected--
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Down):
+		if d.selected < len(d.threads)-1 {
+			d.selected++
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Enter):
+		client := m.clientFor(d.project)
+		return m, openBrowser(client.GetPullRequestWebURL(d.project.Name, d.pr.Repository.Name, d.pr.PullRequestID))
+
+	case key.Matches(msg, m.keys.Refresh):
+		if d.loading {
+			return m, nil
+		}
+		d.loading = true
+		return m, fetchPullRequestDetail(m.clientFor(d.project), d.project, d.pr)
+
+	case key.Matches(msg, m.keys.Files):
+		return m.openFiles(d.project, d.pr)
+
+	case key.Matches(msg, m.keys.Reply):
+		if d.selectedThread() == nil || d.sending {
+			return m, nil
+		}
+		input := textinput.New()
+		input.Placeholder = "Reply"
+		input.Prompt = "> "
+		input.CharLimit = 4000
+		input.Focus()
+		d.input = input
+		d.replying = true
+		return m, textinput.Blink
+
+	case key.Matches(msg, m.keys.Resolve):
+		thread := d.selectedThread()
+		if thread == nil || d.sending {
+			return m, nil
+		}
+		d.sending = true
+		client := m.clientFor(d.project)
+		project, pr, threadID := d.project, d.pr, thread.ID
+		return m, updateThread(client, project, pr, func(ctx context.Context) error {
+			_, err := client.SetThreadStatus(ctx, project.Name, repositoryOf(pr), pr.PullRequestID, threadID, api.CommentThreadStatusFixed)
+			return err
+		})
+	}
+
+	return m, nil
+}
+
+// handleReplyKey handles keyboard input while a reply is being written
+func (m Model) handleReplyKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	d := m.detail
+
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+
+	case tea.KeyEsc:
+		d.replying = false
+		return m, nil
+
+	case tea.KeyEnter:
+		content := strings.TrimSpace(d.input.Value())
+		thread := d.selectedThread()
+		if content == "" || thread == nil {
+			return m, nil
+		}
+		d.replying = false
+		d.sending = true
+		client := m.clientFor(d.project)
+		project, pr, threadID, parentID := d.project, d.pr, thread.ID, thread.RootCommentID()
+		return m, updateThread(client, project, pr, func(ctx context.Context) error {
+			_, err := client.ReplyToThread(ctx, project.Name, repositoryOf(pr), pr.PullRequestID, threadID, parentID, content)
+			return err
+		})
+	}
+
+	var cmd tea.Cmd
+	d.input, cmd = d.input.Update(msg)
+	return m, cmd
+}
+
+// renderDetail renders the pull request detail pane
+func (m Model) renderDetail() string {
+	d := m.detail
+	pr := d.pr
+	var b strings.Builder
+
+	b.WriteString(styles.ActiveTabStyle.Render(fmt.Sprintf("► Pull request !%d: %s", pr.PullRequestID, pr.Title)))
+	b.WriteString("\n")
+	b.WriteString(styles.HelpStyle.Render(fmt.Sprintf("%s • %s • %s • created %s",
+		pr.GetBranchSummary(), pr.CreatedBy.DisplayName, pr.GetStatusDisplay(), formatCreatedTime(pr.CreationDate))))
+	b.WriteString("\n\n")
+
+	// Description
+	b.WriteString(styles.SubtitleStyle.Render("Description"))
+	b.WriteString("\n")
+	description := strings.TrimSpace(pr.Description)
+	if description == "" {
+		b.WriteString(styles.HelpStyle.Render("  No description"))
+		b.WriteString("\n")
+	} else {
+		lines := strings.Split(description, "\n")
+		for i, line := range lines {
+			if i == detailMaxDescriptionLines {
+				b.WriteString(styles.HelpStyle.Render(fmt.Sprintf("  … %d more lines", len(lines)-i)))
+				b.WriteString("\n")
+				break
+			}
+			b.WriteString("  " + strings.TrimRight(line, "\r"))
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("\n")
+
+	// Reviewers
+	b.WriteString(styles.SubtitleStyle.Render("Reviewers"))
+	b.WriteString("\n")
+	if len(pr.Reviewers) == 0 {
+		b.WriteString(styles.HelpStyle.Render("  No reviewers"))
+		b.WriteString("\n")
+	}
+	for _, r := range pr.Reviewers {
+		required := ""
+		if r.IsRequired {
+			required = styles.CanceledStyle.Render("required")
+		}
+		b.WriteString(fmt.Sprintf("  %s %-30s %-26s %s\n",
+			renderVoteIcon(r), truncate(r.DisplayName, 30), r.GetVoteDisplay(), required))
+	}
+	b.WriteString("\n")
+
+	// Threads
+	b.WriteString(styles.SubtitleStyle.Render(fmt.Sprintf("Active threads (%d)", len(d.threads))))
+	b.WriteString("\n")
+	switch {
+	case d.loading && len(d.threads) == 0:
+		b.WriteString(m.spinner.View())
+		b.WriteString(" Loading threads...\n")
+	case len(d.threads) == 0:
+		b.WriteString(styles.HelpStyle.Render("  No active threads"))
+		b.WriteString("\n")
+	default:
+		b.WriteString(m.renderThreadList())
+	}
+
+	if thread := d.selectedThread(); thread != nil {
+		b.WriteString("\n")
+		b.WriteString(m.renderThread(*thread))
+	}
+
+	if d.replying {
+		b.WriteString("\n")
+		b.WriteString(d.input.View())
+		b.WriteString("\n")
+	}
+	if d.sending {
+		b.WriteString("\n")
+		b.WriteString(m.spinner.View())
+		b.WriteString(" Sending...\n")
+	}
+	if d.err != nil {
+		b.WriteString("\n")
+		b.WriteString(styles.ErrorStyle.Render(fmt.Sprintf("Error: %v", d.err)))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	if d.replying {
+		b.WriteString(styles.HelpStyle.Render("enter send reply • esc cancel"))
+	} else {
+		b.WriteString(styles.HelpStyle.Render("↑/↓ select thread • c reply • x resolve • f changed files • r reload • enter open in browser • esc back"))
+	}
+	return b.String()
+}
+
+// renderThreadList renders one line per active thread, scrolled to keep the selection visible
+func (m Model) renderThreadList() string {
+	d := m.detail
+	start := max(0, min(d.selected-detailMaxThreads/2, len(d.threads)-detailMaxThreads))
+	end := min(start+detailMaxThreads, len(d.threads))
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString(styles.HelpStyle.Render(fmt.Sprintf("  … %d more", start)))
+		b.WriteString("\n")
+	}
+	for i := start; i < end; i++ {
+		t := d.threads[i]
+		location := t.GetLocation()
+		if location == "" {
+			location = "(general)"
+		}
+		author := ""
+		if comments := t.VisibleComments(); len(comments) > 0 {
+			author = comments[0].Author.DisplayName
+		}
+		row := fmt.Sprintf("%-40s %-20s %-60s %d",
+			truncate(location, 40), truncate(author, 20), truncate(t.GetSummary(), 60), len(t.VisibleComments()))
+		if i == d.selected {
+			b.WriteString(styles.SelectedRowStyle.Render("> " + row))
+		} else {
+			b.WriteString("  " + row)
+		}
+		b.WriteString("\n")
+	}
+	if end < len(d.threads) {
+		b.WriteString(styles.HelpStyle.Render(fmt.Sprintf("  … %d more", len(d.threads)-end)))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// renderThread renders all comments of a thread
+func (m Model) renderThread(thread api.CommentThread) string {
+	var b strings.Builder
+	for _, c := range thread.VisibleComments() {
+		b.WriteString("  ")
+		b.WriteString(styles.SubtitleStyle.Render(c.Author.DisplayName))
+		if !c.PublishedDate.IsZero() {
+			b.WriteString(styles.HelpStyle.Render(" " + formatCreatedTime(c.PublishedDate)))
+		}
+		b.WriteString("\n")
+		for _, line := range strings.Split(strings.TrimSpace(c.Content), "\n") {
+			b.WriteString("    " + strings.TrimRight(line, "\r"))
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// renderVoteIcon returns a colored icon for the vote of a reviewer
+func renderVoteIcon(r api.Reviewer) string {
+	switch {
+	case r.Vote >= 5:
+		return styles.SucceededStyle.Render("✓")
+	case r.Vote <= -10:
+		return styles.FailedStyle.Render("✗")
+	case r.Vote == -5:
+		return styles.CanceledStyle.Render("○")
+	default:
+		return styles.NotStartedStyle.Render("-")
+	}
+}
@@ -0,0 +1,426 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/api"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/config"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/diff"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/styles"
+)
+
+const (
+	// diffContextLines is the number of unchanged lines shown around each change
+	diffContextLines = 3
+	// filesChromeLines is the number of screen lines used around the file list or diff
+	filesChromeLines = 12
+)
+
+// pullRequestFiles is the state of the changed files pane, which lists the files of the
+// latest iteration of a pull request and shows the diff of the selected one
+type pullRequestFiles struct {
+	project   config.ProjectConfig
+	pr        api.PullRequest
+	iteration *api.PullRequestIteration
+	changes   []api.PullRequestChange
+	loading   bool
+	selected  int
+	diff      *fileDiff // Diff of the selected file, nil while the list is shown
+	err       error
+}
+
+// fileDiff is the diff of a changed file, scrolled by offset lines
+type fileDiff struct {
+	change  api.PullRequestChange
+	lines   []diffLine
+	binary  bool
+	loading bool
+	offset  int
+	err     error
+}
+
+// diffLine is a rendered line of a diff: a hunk header or a diff line
+type diffLine struct {
+	header string
+	line   diff.Line
+}
+
+// PullRequestChangesMsg is sent when the changed files of a pull request have been fetched
+type PullRequestChangesMsg struct {
+	PullRequestID int
+	Iteration     *api.PullRequestIteration
+	Changes       []api.PullRequestChange
+	Err           error
+}
+
+// FileDiffMsg is sent when both versions of a changed file have been fetched and compared
+type FileDiffMsg struct {
+	PullRequestID int
+	Path          string
+	Hunks         []diff.Hunk
+	Binary        bool
+	Err           error
+}
+
+// fetchPullRequestChanges creates a command to fetch the changed files of the latest iteration
+func fetchPullRequestChanges(client api.Service, project config.ProjectConfig, pr api.PullRequest) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		iterations, err := client.GetPullRequestIterations(ctx, project.Name, repositoryOf(pr), pr.PullRequestID)
+		if err != nil {
+			return PullRequestChangesMsg{PullRequestID: pr.PullRequestID, Err: err}
+		}
+		latest := api.LatestIteration(iterations)
+		if latest == nil {
+			return PullRequestChangesMsg{PullRequestID: pr.PullRequestID}
+		}
+
+		changes, err := client.GetPullRequestIterationChanges(ctx, project.Name, repositoryOf(pr), pr.PullRequestID, latest.ID)
+		return PullRequestChangesMsg{
+			PullRequestID: pr.PullRequestID,
+			Iteration:     latest,
+			Changes:       api.FileChanges(changes),
+			Err:           err,
+		}
+	}
+}
+
+// fetchFileDiff creates a command to fetch both versions of a changed file and compare them
+func fetchFileDiff(client api.Service, project config.ProjectConfig, pr api.PullRequest, change api.PullRequestChange) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		msg := FileDiffMsg{PullRequestID: pr.PullRequestID, Path: change.Item.Path}
+		var versions [2][]byte
+		for i, objectID := range []string{change.OldObjectID(), change.NewObjectID()} {
+			if objectID == "" {
+				continue
+			}
+			content, err := client.GetBlobContent(ctx, project.Name, repositoryOf(pr), objectID)
+			if err != nil {
+				msg.Err = err
+				return msg
+			}
+			versions[i] = content
+		}
+
+		if diff.IsBinary(versions[0]) || diff.IsBinary(versions[1]) {
+			msg.Binary = true
+			return msg
+		}
+		msg.Hunks, msg.Err = diff.Unified(string(versions[0]), string(versions[1]), diffContextLines)
+		return msg
+	}
+}
+
+// openFiles opens the changed files pane for a pull request
+func (m Model) openFiles(project config.ProjectConfig, pr api.PullRequest) (tea.Model, tea.Cmd) {
+	m.files = &pullRequestFiles{project: project, pr: pr, loading: true}
+	return m, fetchPullRequestChanges(m.clientFor(project), project, pr)
+}
+
+// openSelectedFiles opens the changed files pane for the selected pull request
+func (m Model) openSelectedFiles() (tea.Model, tea.Cmd) {
+	pullRequests := m.CurrentPullRequests()
+	if m.selectedRow < 0 || m.selectedRow >= len(pullRequests) {
+		return m, nil
+	}
+	return m.openFiles(m.CurrentProject(), pullRequests[m.selectedRow])
+}
+
+// handleChangesLoaded shows the fetched changed files
+func (m Model) handleChangesLoaded(msg PullRequestChangesMsg) (tea.Model, tea.Cmd) {
+	if m.files == nil || m.files.pr.PullRequestID != msg.PullRequestID {
+		return m, nil
+	}
+	f := m.files
+	f.loading = false
+	f.err = msg.Err
+	if msg.Err == nil {
+		f.iteration = msg.Iteration
+		f.changes = msg.Changes
+		if f.selected >= len(f.changes) {
+			f.selected = max(len(f.changes)-1, 0)
+		}
+	}
+	return m, nil
+}
+
+// handleFileDiffLoaded shows the diff of the selected file
+func (m Model) handleFileDiffLoaded(msg FileDiffMsg) (tea.Model, tea.Cmd) {
+	if m.files == nil || m.files.pr.PullRequestID != msg.PullRequestID ||
+		m.files.diff == nil || m.files.diff.change.Item.Path != msg.Path {
+		return m, nil
+	}
+	d := m.files.diff
+	d.loading = false
+	d.err = msg.Err
+	d.binary = msg.Binary
+	d.lines = nil
+	for _, h := range msg.Hunks {
+		d.lines = append(d.lines, diffLine{header: h.Header()})
+		for _, l := range h.Lines {
+			d.lines = append(d.lines, diffLine{line: l})
+		}
+	}
+	return m, nil
+}
+
+// showDiff switches to the diff of the changed file at index i
+func (m Model) showDiff(i int) (tea.Model, tea.Cmd) {
+	f := m.files
+	if i < 0 || i >= len(f.changes) {
+		return m, nil
+	}
+	f.selected = i
+	f.diff = &fileDiff{change: f.changes[i], loading: true}
+	return m, fetchFileDiff(m.clientFor(f.project), f.project, f.pr, f.changes[i])
+}
+
+// diffHeight returns the number of diff lines that fit on the screen
+func (m Model) diffHeight() int {
+	return max(m.height-filesChromeLines, 5)
+}
+
+// handleFilesKey handles keyboard input while the changed files pane is open
+func (m Model) handleFilesKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	f := m.files
+	if f.diff != nil {
+		return m.handleDiffKey(msg)
+	}
+
+	switch {
+	case msg.Type == tea.KeyEsc:
+		m.files = nil
+		return m, nil
+
+	case key.Matches(msg, m.keys.Quit):
+		return m, tea.Quit
+
+	case key.Matches(msg, m.keys.Up):
+		if f.selected > 0 {
+			f.selected--
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Down):
+		if f.selected < len(f.changes)-1 {
+			f.selected++
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Enter):
+		return m.showDiff(f.selected)
+
+	case key.Matches(msg, m.keys.Refresh):
+		if f.loading {
+			return m, nil
+		}
+		f.loading = true
+		return m, fetchPullRequestChanges(m.clientFor(f.project), f.project, f.pr)
+	}
+
+	return m, nil
+}
+
+// handleDiffKey handles keyboard input while a diff is shown
+func (m Model) handleDiffKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	f := m.files
+	d := f.diff
+	lastOffset := max(len(d.lines)-m.diffHeight(), 0)
+
+	switch {
+	case msg.Type == tea.KeyEsc:
+		f.diff = nil
+		return m, nil
+
+	case key.Matches(msg, m.keys.Quit):
+		return m, tea.Quit
+
+	case key.Matches(msg, m.keys.Up):
+		d.offset = max(d.offset-1, 0)
+
+	case key.Matches(msg, m.keys.Down):
+		d.offset = min(d.offset+1, lastOffset)
+
+	case msg.Type == tea.KeyPgUp:
+		d.offset = max(d.offset-m.diffHeight(), 0)
+
+	case msg.Type == tea.KeyPgDown || msg.Type == tea.KeySpace:
+		d.offset = min(d.offset+m.diffHeight(), lastOffset)
+
+	case key.Matches(msg, m.keys.Left):
+		return m.showDiff(f.selected - 1)
+
+	case key.Matches(msg, m.keys.Right):
+		return m.showDiff(f.selected + 1)
+	}
+
+	return m, nil
+}
+
+// renderFiles renders the changed files pane
+func (m Model) renderFiles() string {
+	f := m.files
+	pr := f.pr
+	var b strings.Builder
+
+	b.WriteString(styles.ActiveTabStyle.Render(fmt.Sprintf("► Pull request !%d: %s", pr.PullRequestID, pr.Title)))
+	b.WriteString("\n")
+	iteration := "no iterations"
+	if f.iteration != nil {
+		iteration = fmt.Sprintf("iteration %d", f.iteration.ID)
+		if commit := f.iteration.SourceRefCommit.CommitID; len(commit) >= 8 {
+			iteration += " @ " + commit[:8]
+		}
+	}
+	b.WriteString(styles.HelpStyle.Render(fmt.Sprintf("%s • %s", pr.GetBranchSummary(), iteration)))
+	b.WriteString("\n\n")
+
+	if f.diff != nil {
+		b.WriteString(m.renderDiff())
+	} else {
+		b.WriteString(m.renderFileList())
+	}
+
+	if f.err != nil {
+		b.WriteString("\n")
+		b.WriteString(styles.ErrorStyle.Render(fmt.Sprintf("Error: %v", f.err)))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	if f.diff != nil {
+		b.WriteString(styles.HelpStyle.Render("↑/↓ scroll • pgup/pgdn page • ←/→ previous/next file • esc back to files"))
+	} else {
+		b.WriteString(styles.HelpStyle.Render("↑/↓ select file • enter show diff • r reload • esc back"))
+	}
+	return b.String()
+}
+
+// renderFileList renders one line per changed file, scrolled to keep the selection visible
+func (m Model) renderFileList() string {
+	f := m.files
+	var b strings.Builder
+
+	b.WriteString(styles.SubtitleStyle.Render(fmt.Sprintf("Changed files (%d)", len(f.changes))))
+	b.WriteString("\n")
+	switch {
+	case f.loading && len(f.changes) == 0:
+		b.WriteString(m.spinner.View())
+		b.WriteString(" Loading changed files...\n")
+		return b.String()
+	case len(f.changes) == 0:
+		b.WriteString(styles.HelpStyle.Render("  No changed files"))
+		b.WriteString("\n")
+		return b.String()
+	}
+
+	height := m.diffHeight()
+	start := max(0, min(f.selected-height/2, len(f.changes)-height))
+	end := min(start+height, len(f.changes))
+	for i := start; i < end; i++ {
+		c := f.changes[i]
+		row := fmt.Sprintf("%s %s", renderChangeMarker(c), c.GetPathDisplay())
+		if i == f.selected {
+			b.WriteString(styles.SelectedRowStyle.Render("> " + row))
+		} else {
+			b.WriteString("  " + row)
+		}
+		b.WriteString("\n")
+	}
+	if end < len(f.changes) {
+		b.WriteString(styles.HelpStyle.Render(fmt.Sprintf("  … %d more", len(f.changes)-end)))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// renderDiff renders the visible part of the diff of the selected file
+func (m Model) renderDiff() string {
+	f := m.files
+	d := f.diff
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("%s %s", renderChangeMarker(d.change), styles.SubtitleStyle.Render(d.change.GetPathDisplay())))
+	b.WriteString(styles.HelpStyle.Render(fmt.Sprintf("  (%d/%d)", f.selected+1, len(f.changes))))
+	b.WriteString("\n")
+
+	switch {
+	case d.loading:
+		b.WriteString(m.spinner.View())
+		b.WriteString(" Loading diff...\n")
+		return b.String()
+	case d.err != nil:
+		b.WriteString(styles.ErrorStyle.Render(fmt.Sprintf("Error: %v", d.err)))
+		b.WriteString("\n")
+		return b.String()
+	case d.binary:
+		b.WriteString(styles.HelpStyle.Render("  Binary file, not shown"))
+		b.WriteString("\n")
+		return b.String()
+	case len(d.lines) == 0:
+		b.WriteString(styles.HelpStyle.Render("  No content changes"))
+		b.WriteString("\n")
+		return b.String()
+	}
+
+	end := min(d.offset+m.diffHeight(), len(d.lines))
+	for _, l := range d.lines[d.offset:end] {
+		b.WriteString(renderDiffLine(l, m.width))
+		b.WriteString("\n")
+	}
+	if end < len(d.lines) {
+		b.WriteString(styles.HelpStyle.Render(fmt.Sprintf("  … %d more lines", len(d.lines)-end)))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// renderDiffLine renders a diff line with line numbers, colored by kind
+func renderDiffLine(l diffLine, width int) string {
+	if l.header != "" {
+		return styles.InProgressStyle.Render(l.header)
+	}
+
+	number := func(n int) string {
+		if n == 0 {
+			return "    "
+		}
+		return fmt.Sprintf("%4d", n)
+	}
+	text := truncate(strings.ReplaceAll(l.line.String(), "\t", "    "), max(width-11, 20))
+	gutter := styles.HelpStyle.Render(fmt.Sprintf("%s %s ", number(l.line.OldLine), number(l.line.NewLine)))
+
+	switch l.line.Kind {
+	case diff.Added:
+		return gutter + styles.SucceededStyle.Render(text)
+	case diff.Removed:
+		return gutter + styles.FailedStyle.Render(text)
+	default:
+		return gutter + text
+	}
+}
+
+// renderChangeMarker returns a colored marker for the change type of a file
+func renderChangeMarker(c api.PullRequestChange) string {
+	marker := c.GetMarker()
+	switch marker {
+	case "A":
+		return styles.SucceededStyle.Render(marker)
+	case "D":
+		return styles.FailedStyle.Render(marker)
+	case "R":
+		return styles.CanceledStyle.Render(marker)
+	default:
+		return styles.InProgressStyle.Render(marker)
+	}
+}
@@ -0,0 +1,521 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/api"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/config"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/styles"
+)
+
+// releaseVersionCount is the number of recent successful builds offered per artifact
+const releaseVersionCount = 10
+
+// releaseDefinitionRows is the number of definitions shown at once while choosing one
+const releaseDefinitionRows = 15
+
+// releaseForm is the state of the form that creates a release of a release definition. The
+// definition is either the one of the selected release or chosen from the definitions of the
+// project first. Its fields are the build artifacts, then the environments, then the description.
+type releaseForm struct {
+	project      config.ProjectConfig
+	definition   api.ReleaseDefinition   // From the selected release until the full definition has been fetched
+	choosing     bool                    // Choosing the definition from the definitions of the project
+	definitions  []api.ReleaseDefinition // Offered while choosing
+	chosen       int                     // Highlighted definition while choosing
+	artifacts    []artifactVersion
+	environments []environmentDeploy
+	description  textinput.Model
+	field        int  // Focused field
+	loading      bool // Fetching the definition and the builds of its artifacts
+	sending      bool
+	err          error
+}
+
+// artifactVersion is a build artifact of the definition and the build selected as its version
+type artifactVersion struct {
+	artifact api.DefinitionArtifact
+	builds   []api.Build // Recent successful builds, newest first
+	selected int
+}
+
+// environmentDeploy is an environment of the definition and whether the new release deploys it
+// automatically
+type environmentDeploy struct {
+	environment api.DefinitionEnvironment
+	automated   bool
+}
+
+// ReleaseDefinitionsLoadedMsg is sent when the release definitions of a project have been listed
+// for the release form
+type ReleaseDefinitionsLoadedMsg struct {
+	Project     string
+	Definitions []api.ReleaseDefinition
+	Err         error
+}
+
+// ReleaseFormLoadedMsg is sent when the definition of the release form and the recent successful
+// builds of its artifacts have been fetched
+type ReleaseFormLoadedMsg struct {
+	DefinitionID int
+	Definition   *api.ReleaseDefinition
+	Builds       map[string][]api.Build // Keyed by artifact alias
+	Err          error
+}
+
+// ReleaseCreatedMsg is sent when a release has been created
+type ReleaseCreatedMsg struct {
+	DefinitionID int
+	Release      *api.Release
+	Err          error
+}
+
+// fetchReleaseDefinitions creates a command to list the release definitions of a project
+func fetchReleaseDefinitions(client api.Service, project config.ProjectConfig) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		definitions, err := client.GetReleaseDefinitions(ctx, project.Name)
+		return ReleaseDefinitionsLoadedMsg{Project: project.Key(), Definitions: definitions, Err: err}
+	}
+}
+
+// fetchReleaseForm creates a command to fetch a release definition and the recent successful
+// builds of its build artifacts
+func fetchReleaseForm(client api.Service, project config.ProjectConfig, definitionID int) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		msg := ReleaseFormLoadedMsg{DefinitionID: definitionID, Builds: make(map[string][]api.Build)}
+		msg.Definition, msg.Err = client.GetReleaseDefinition(ctx, project.Name, definitionID)
+		if msg.Err != nil {
+			return msg
+		}
+
+		for _, a := range msg.Definition.BuildArtifacts() {
+			// Artifacts can be built in another project of the organization
+			source := a.SourceProject()
+			if source == "" {
+				source = project.Name
+			}
+			builds, err := client.GetSuccessfulBuilds(ctx, source, a.BuildDefinitionID(), releaseVersionCount)
+			if err != nil {
+				msg.Err = fmt.Errorf("failed to fetch builds of artifact %s: %w", a.Alias, err)
+				return msg
+			}
+			msg.Builds[a.Alias] = builds
+		}
+		return msg
+	}
+}
+
+// createRelease creates a command that creates a release
+func createRelease(client api.Service, project config.ProjectConfig, release api.NewRelease) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		created, err := client.CreateRelease(ctx, project.Name, release)
+		return ReleaseCreatedMsg{DefinitionID: release.DefinitionID, Release: created, Err: err}
+	}
+}
+
+// newReleaseForm creates an empty release form for a project
+func newReleaseForm(project config.ProjectConfig) *releaseForm {
+	f := &releaseForm{project: project, description: textinput.New(), loading: true}
+	f.description.Placeholder = "Description"
+	f.description.Prompt = ""
+	f.description.CharLimit = 4000
+	return f
+}
+
+// openReleaseForm opens the release form for the definition of the selected release, or lets
+// the definition be chosen when no release is selected
+func (m Model) openReleaseForm() (tea.Model, tea.Cmd) {
+	releases := m.CurrentReleases()
+	if m.selectedRow < 0 || m.selectedRow >= len(releases) {
+		return m.chooseReleaseDefinition()
+	}
+
+	project := m.CurrentProject()
+	f := newReleaseForm(project)
+	f.definition = releases[m.selectedRow].ReleaseDefinition
+	m.createRelease = f
+	return m, fetchReleaseForm(m.clientFor(project), project, f.definition.ID)
+}
+
+// chooseReleaseDefinition opens the release form on the list of release definitions of the
+// current project, starting at the definition of the selected release
+func (m Model) chooseReleaseDefinition() (tea.Model, tea.Cmd) {
+	project := m.CurrentProject()
+	f := newReleaseForm(project)
+	f.choosing = true
+	if releases := m.CurrentReleases(); m.selectedRow >= 0 && m.selectedRow < len(releases) {
+		f.definition = releases[m.selectedRow].ReleaseDefinition
+	}
+	m.createRelease = f
+	return m, fetchReleaseDefinitions(m.clientFor(project), project)
+}
+
+// handleReleaseDefinitionsLoaded offers the listed definitions while choosing one
+func (m Model) handleReleaseDefinitionsLoaded(msg ReleaseDefinitionsLoadedMsg) (tea.Model, tea.Cmd) {
+	f := m.createRelease
+	if f == nil || !f.choosing || f.project.Key() != msg.Project {
+		return m, nil
+	}
+	f.loading = false
+	if msg.Err != nil {
+		f.err = msg.Err
+		return m, nil
+	}
+
+	f.definitions = msg.Definitions
+	f.chosen = 0
+	for i, d := range f.definitions {
+		if d.ID == f.definition.ID {
+			f.chosen = i
+		}
+	}
+	return m, nil
+}
+
+// handleDefinitionChoiceKey handles keyboard input while choosing the definition of the release
+func (m Model) handleDefinitionChoiceKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	f := m.createRelease
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.createRelease = nil
+
+	case tea.KeyUp, tea.KeyShiftTab:
+		f.chosen = max(f.chosen-1, 0)
+
+	case tea.KeyDown, tea.KeyTab:
+		f.chosen = min(f.chosen+1, max(len(f.definitions)-1, 0))
+
+	case tea.KeyEnter:
+		if f.loading || f.chosen >= len(f.definitions) {
+			return m, nil
+		}
+		f.definition = f.definitions[f.chosen]
+		f.choosing = false
+		f.definitions = nil
+		f.loading = true
+		f.err = nil
+		return m, fetchReleaseForm(m.clientFor(f.project), f.project, f.definition.ID)
+	}
+	return m, nil
+}
+
+// handleReleaseFormLoaded fills the form with the artifacts and environments of the definition
+func (m Model) handleReleaseFormLoaded(msg ReleaseFormLoadedMsg) (tea.Model, tea.Cmd) {
+	f := m.createRelease
+	if f == nil || f.definition.ID != msg.DefinitionID {
+		return m, nil
+	}
+	f.loading = false
+	if msg.Err != nil {
+		f.err = msg.Err
+		return m, nil
+	}
+
+	f.definition = *msg.Definition
+	f.artifacts = nil
+	for _, a := range f.definition.BuildArtifacts() {
+		f.artifacts = append(f.artifacts, artifactVersion{artifact: a, builds: msg.Builds[a.Alias]})
+	}
+	f.environments = nil
+	for _, env := range f.definition.Environments {
+		f.environments = append(f.environments, environmentDeploy{environment: env, automated: env.IsAutomated()})
+	}
+	f.field = 0
+	return m, f.focus()
+}
+
+// handleReleaseCreated closes the form and opens the details of the new release
+func (m Model) handleReleaseCreated(msg ReleaseCreatedMsg) (tea.Model, tea.Cmd) {
+	if m.createRelease == nil || m.createRelease.definition.ID != msg.DefinitionID {
+		return m, nil
+	}
+	if msg.Err != nil {
+		m.createRelease.sending = false
+		m.createRelease.err = msg.Err
+		return m, nil
+	}
+
+	project := m.createRelease.project
+	m.createRelease = nil
+	m.activeTab = TabReleases
+	m.selectedRow = 0
+	m.release = &releaseDetail{project: project, release: *msg.Release, loading: true, selected: -1}
+
+	cmds := []tea.Cmd{fetchReleaseDetail(m.clientFor(project), project, msg.Release.ID)}
+	if !m.loadingReleases[project.Key()] {
+		m.loadingReleases[project.Key()] = true
+		cmds = append(cmds, fetchReleases(m.clientFor(project), project, m.config.Display.MaxItemsPerProject))
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// fieldCount returns the number of fields of the form
+func (f *releaseForm) fieldCount() int {
+	return len(f.artifacts) + len(f.environments) + 1
+}
+
+// descriptionField returns the index of the description field
+func (f *releaseForm) descriptionField() int {
+	return len(f.artifacts) + len(f.environments)
+}
+
+// handleReleaseFormKey handles keyboard input while the release form is open. Tab and the arrow
+// keys move between fields, ←/→ pick the version of an artifact, space toggles whether an
+// environment deploys automatically and enter creates the release.
+func (m Model) handleReleaseFormKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	f := m.createRelease
+	if msg.Type == tea.KeyCtrlC {
+		return m, tea.Quit
+	}
+	if f.sending {
+		return m, nil
+	}
+	if f.choosing {
+		return m.handleDefinitionChoiceKey(msg)
+	}
+
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.createRelease = nil
+		return m, nil
+
+	case tea.KeyTab, tea.KeyDown:
+		f.field = (f.field + 1) % f.fieldCount()
+		return m, f.focus()
+
+	case tea.KeyShiftTab, tea.KeyUp:
+		f.field = (f.field + f.fieldCount() - 1) % f.fieldCount()
+		return m, f.focus()
+
+	case tea.KeyEnter, tea.KeyCtrlS:
+		return m.submitReleaseForm()
+	}
+
+	switch {
+	case f.field < len(f.artifacts):
+		a := &f.artifacts[f.field]
+		switch msg.Type {
+		case tea.KeyRight:
+			// Older builds are to the right
+			a.selected = min(a.selected+1, max(len(a.builds)-1, 0))
+		case tea.KeyLeft:
+			a.selected = max(a.selected-1, 0)
+		}
+		return m, nil
+
+	case f.field < f.descriptionField():
+		e := &f.environments[f.field-len(f.artifacts)]
+		// Environments only deployed manually cannot be made automatic for one release
+		if msg.Type == tea.KeySpace && e.environment.IsAutomated() {
+			e.automated = !e.automated
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	f.description, cmd = f.description.Update(msg)
+	return m, cmd
+}
+
+// submitReleaseForm validates the form and creates the release
+func (m Model) submitReleaseForm() (tea.Model, tea.Cmd) {
+	f := m.createRelease
+	if f.loading {
+		return m, nil
+	}
+
+	release := api.NewRelease{
+		DefinitionID: f.definition.ID,
+		Description:  strings.TrimSpace(f.description.Value()),
+	}
+	for _, a := range f.artifacts {
+		if len(a.builds) == 0 {
+			f.err = fmt.Errorf("artifact %s has no successful build to release", a.artifact.Alias)
+			return m, nil
+		}
+		build := a.builds[a.selected]
+		release.Artifacts = append(release.Artifacts, api.ArtifactMetadata{
+			Alias:             a.artifact.Alias,
+			InstanceReference: api.ArtifactSourceReference{ID: strconv.Itoa(build.ID), Name: build.BuildNumber},
+		})
+	}
+	for _, e := range f.environments {
+		if e.environment.IsAutomated() && !e.automated {
+			release.ManualEnvironments = append(release.ManualEnvironments, e.environment.Name)
+		}
+	}
+
+	f.sending = true
+	f.err = nil
+	return m, createRelease(m.clientFor(f.project), f.project, release)
+}
+
+// focus focuses the description input when it is the current field
+func (f *releaseForm) focus() tea.Cmd {
+	if f.field == f.descriptionField() {
+		return f.description.Focus()
+	}
+	f.description.Blur()
+	return nil
+}
+
+// describeTrigger describes when an environment of the definition is deployed
+func describeTrigger(env api.DefinitionEnvironment) string {
+	var triggers []string
+	for _, c := range env.Conditions {
+		switch c.ConditionType {
+		case "event":
+			triggers = append(triggers, "on release creation")
+		case "environmentState":
+			triggers = append(triggers, "after "+c.Name)
+		case "artifact":
+			triggers = append(triggers, "on artifact filter "+c.Name)
+		}
+	}
+	if len(triggers) == 0 {
+		return "manual only"
+	}
+	return "automatic " + strings.Join(triggers, ", ")
+}
+
+// renderReleaseForm renders the release form
+func (m Model) renderReleaseForm() string {
+	f := m.createRelease
+	if f.choosing {
+		return m.renderDefinitionChoice()
+	}
+	var b strings.Builder
+
+	b.WriteString(styles.ActiveTabStyle.Render(fmt.Sprintf("► New release of %s", f.definition.Name)))
+	b.WriteString("\n")
+	b.WriteString(styles.HelpStyle.Render(fmt.Sprintf("Project %s • creating the release starts its automatic deployments", f.project.Name)))
+	b.WriteString("\n\n")
+
+	if f.loading {
+		b.WriteString(m.spinner.View())
+		b.WriteString(" Loading definition and builds...\n")
+	} else {
+		label := func(field int, name string) string {
+			if field == f.field {
+				return styles.SelectedRowStyle.Render("> " + name)
+			}
+			return "  " + name
+		}
+
+		b.WriteString(styles.SubtitleStyle.Render("Artifacts"))
+		b.WriteString("\n")
+		if len(f.artifacts) == 0 {
+			b.WriteString(styles.HelpStyle.Render("  No build artifacts, the default versions are used"))
+			b.WriteString("\n")
+		}
+		for i, a := range f.artifacts {
+			version := styles.ErrorStyle.Render("no successful builds")
+			if len(a.builds) > 0 {
+				build := a.builds[a.selected]
+				version = fmt.Sprintf("◀ %s %s ▶ %s", build.BuildNumber, build.GetBranchName(),
+					styles.HelpStyle.Render(fmt.Sprintf("%s (%d/%d)", formatCreatedTime(build.FinishTime), a.selected+1, len(a.builds))))
+			}
+			b.WriteString(fmt.Sprintf("%s %s\n", label(i, fmt.Sprintf("%-30s", truncate(a.artifact.Alias, 30))), version))
+		}
+		b.WriteString("\n")
+
+		b.WriteString(styles.SubtitleStyle.Render("Deployments"))
+		b.WriteString("\n")
+		for i, e := range f.environments {
+			checkbox := "[ ]"
+			if e.automated {
+				checkbox = "[x]"
+			}
+			trigger := describeTrigger(e.environment)
+			if e.environment.IsAutomated() && !e.automated {
+				trigger = "manual in this release"
+			}
+			b.WriteString(fmt.Sprintf("%s %s %s\n", label(len(f.artifacts)+i, checkbox), fmt.Sprintf("%-20s", truncate(e.environment.Name, 20)),
+				styles.HelpStyle.Render(trigger)))
+		}
+		b.WriteString("\n")
+
+		b.WriteString(label(f.descriptionField(), "Description: ") + f.description.View() + "\n")
+	}
+
+	if f.sending {
+		b.WriteString("\n")
+		b.WriteString(m.spinner.View())
+		b.WriteString(" Creating release...\n")
+	}
+	if f.err != nil {
+		b.WriteString("\n")
+		b.WriteString(styles.ErrorStyle.Render(fmt.Sprintf("Error: %v", f.err)))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(styles.HelpStyle.Render("tab/↑/↓ select field • ←/→ artifact version • space toggle automatic deployment • enter create • esc cancel"))
+	return b.String()
+}
+
+// renderDefinitionChoice renders the release definitions of the project to choose from
+func (m Model) renderDefinitionChoice() string {
+	f := m.createRelease
+	var b strings.Builder
+
+	b.WriteString(styles.ActiveTabStyle.Render("► New release"))
+	b.WriteString("\n")
+	b.WriteString(styles.HelpStyle.Render(fmt.Sprintf("Project %s • choose the release definition", f.project.Name)))
+	b.WriteString("\n\n")
+
+	switch {
+	case f.loading:
+		b.WriteString(m.spinner.View())
+		b.WriteString(" Loading release definitions...\n")
+	case f.err == nil && len(f.definitions) == 0:
+		b.WriteString(styles.HelpStyle.Render("No release definitions found"))
+		b.WriteString("\n")
+	}
+
+	// Scroll so the highlighted definition stays visible
+	start := max(f.chosen-releaseDefinitionRows+1, 0)
+	end := min(start+releaseDefinitionRows, len(f.definitions))
+	for i := start; i < end; i++ {
+		d := f.definitions[i]
+		folder := ""
+		if d.Path != "" && d.Path != `\` {
+			folder = styles.HelpStyle.Render(d.Path)
+		}
+		row := fmt.Sprintf("%-40s", truncate(d.Name, 40))
+		if i == f.chosen {
+			b.WriteString(styles.SelectedRowStyle.Render("> "+row) + " " + folder + "\n")
+		} else {
+			b.WriteString("  " + row + " " + folder + "\n")
+		}
+	}
+	if len(f.definitions) > releaseDefinitionRows {
+		b.WriteString(styles.HelpStyle.Render(fmt.Sprintf("  %d/%d", f.chosen+1, len(f.definitions))))
+		b.WriteString("\n")
+	}
+
+	if f.err != nil {
+		b.WriteString("\n")
+		b.WriteString(styles.ErrorStyle.Render(fmt.Sprintf("Error: %v", f.err)))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(styles.HelpStyle.Render("↑/↓ select definition • enter choose • esc cancel"))
+	return b.String()
+}
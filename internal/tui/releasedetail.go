@@ -0,0 +1,534 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/api"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/config"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/styles"
+)
+
+// releaseChromeLines is the number of screen lines used around the deployment list or log
+const releaseChromeLines = 12
+
+// releaseDetail is the state of the release detail pane, which lists the deploy attempts of
+// every environment with their approvals, gates and tasks, and shows the log of a task
+type releaseDetail struct {
+	project  config.ProjectConfig
+	release  api.Release // From the list until the full release has been fetched
+	loading  bool
+	selected int      // Selected task, an index into the rows
+	log      *taskLog // Log of the selected task, nil while the deployments are shown
+	err      error
+}
+
+// taskLog is the log of a deploy task, scrolled by offset lines
+type taskLog struct {
+	environmentID int
+	phaseID       int
+	task          api.ReleaseTask
+	lines         []string
+	loading       bool
+	offset        int
+	err           error
+}
+
+// releaseRow is a line of the deployment list. Rows of deploy tasks can be selected to open their log.
+type releaseRow struct {
+	text          string
+	task          *api.ReleaseTask
+	environmentID int
+	phaseID       int
+}
+
+// ReleaseDetailMsg is sent when a release has been fetched with its deploy steps
+type ReleaseDetailMsg struct {
+	ReleaseID int
+	Release   *api.Release
+	Err       error
+}
+
+// TaskLogMsg is sent when the log of a deploy task has been fetched
+type TaskLogMsg struct {
+	ReleaseID int
+	TaskID    int
+	Log       string
+	Err       error
+}
+
+// fetchReleaseDetail creates a command to fetch a release with its deploy steps
+func fetchReleaseDetail(client api.Service, project config.ProjectConfig, releaseID int) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		release, err := client.GetRelease(ctx, project.Name, releaseID)
+		return ReleaseDetailMsg{ReleaseID: releaseID, Release: release, Err: err}
+	}
+}
+
+// fetchTaskLog creates a command to fetch the log of a task of a deploy phase
+func fetchTaskLog(client api.Service, project config.ProjectConfig, releaseID int, l *taskLog) tea.Cmd {
+	environmentID, phaseID, taskID := l.environmentID, l.phaseID, l.task.ID
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		log, err := client.GetReleaseTaskLog(ctx, project.Name, releaseID, environmentID, phaseID, taskID)
+		return TaskLogMsg{ReleaseID: releaseID, TaskID: taskID, Log: log, Err: err}
+	}
+}
+
+// openReleaseDetail opens the detail pane for the selected release
+func (m Model) openReleaseDetail() (tea.Model, tea.Cmd) {
+	releases := m.CurrentReleases()
+	if m.selectedRow < 0 || m.selectedRow >= len(releases) {
+		return m, nil
+	}
+
+	project := m.CurrentProject()
+	release := releases[m.selectedRow]
+	m.release = &releaseDetail{project: project, release: release, loading: true, selected: -1}
+	return m, fetchReleaseDetail(m.clientFor(project), project, release.ID)
+}
+
+// handleReleaseDetailLoaded shows the fetched release and selects its first failed task
+func (m Model) handleReleaseDetailLoaded(msg ReleaseDetailMsg) (tea.Model, tea.Cmd) {
+	if m.release == nil || m.release.release.ID != msg.ReleaseID {
+		return m, nil
+	}
+	d := m.release
+	d.loading = false
+	d.err = msg.Err
+	if msg.Err != nil {
+		return m, nil
+	}
+	d.release = *msg.Release
+
+	rows := releaseRows(&d.release)
+	if d.selected < 0 || d.selected >= len(rows) || rows[d.selected].task == nil {
+		d.selected = firstTaskRow(rows)
+	}
+	return m, nil
+}
+
+// handleTaskLogLoaded shows the fetched task log, scrolled to its end where failures are logged
+func (m Model) handleTaskLogLoaded(msg TaskLogMsg) (tea.Model, tea.Cmd) {
+	if m.release == nil || m.release.release.ID != msg.ReleaseID ||
+		m.release.log == nil || m.release.log.task.ID != msg.TaskID {
+		return m, nil
+	}
+	l := m.release.log
+	l.loading = false
+	l.err = msg.Err
+	if msg.Err == nil {
+		l.lines = strings.Split(strings.TrimRight(strings.ReplaceAll(msg.Log, "\r\n", "\n"), "\n"), "\n")
+		l.offset = max(len(l.lines)-m.logHeight(), 0)
+	}
+	return m, nil
+}
+
+// firstTaskRow returns the row of the first failed task, else of the first task, -1 without tasks
+func firstTaskRow(rows []releaseRow) int {
+	first := -1
+	for i, row := range rows {
+		if row.task == nil {
+			continue
+		}
+		if row.task.IsFailed() {
+			return i
+		}
+		if first < 0 {
+			first = i
+		}
+	}
+	return first
+}
+
+// logHeight returns the number of log lines that fit on the screen
+func (m Model) logHeight() int {
+	return max(m.height-releaseChromeLines, 5)
+}
+
+// handleReleaseKey handles keyboard input while the release detail pane is open
+func (m Model) handleReleaseKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	d := m.release
+	if d.log != nil {
+		return m.handleTaskLogKey(msg)
+	}
+	rows := releaseRows(&d.release)
+
+	switch {
+	case msg.Type == tea.KeyEsc:
+		m.release = nil
+		return m, nil
+
+	case key.Matches(msg, m.keys.Quit):
+		return m, tea.Quit
+
+	case key.Matches(msg, m.keys.Up):
+		for i := d.selected - 1; i >= 0; i-- {
+			if rows[i].task != nil {
+				d.selected = i
+				break
+			}
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Down):
+		for i := d.selected + 1; i < len(rows); i++ {
+			if rows[i].task != nil {
+				d.selected = i
+				break
+			}
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Enter):
+		if d.selected < 0 || d.selected >= len(rows) || rows[d.selected].task == nil {
+			return m, nil
+		}
+		row := rows[d.selected]
+		if row.task.StartTime.IsZero() {
+			// Tasks that have not started have no log yet
+			return m, nil
+		}
+		d.log = &taskLog{environmentID: row.environmentID, phaseID: row.phaseID, task: *row.task, loading: true}
+		return m, fetchTaskLog(m.clientFor(d.project), d.project, d.release.ID, d.log)
+
+	case key.Matches(msg, m.keys.Refresh):
+		if d.loading {
+			return m, nil
+		}
+		d.loading = true
+		return m, fetchReleaseDetail(m.clientFor(d.project), d.project, d.release.ID)
+	}
+
+	return m, nil
+}
+
+// handleTaskLogKey handles keyboard input while a task log is shown
+func (m Model) handleTaskLogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	d := m.release
+	l := d.log
+	lastOffset := max(len(l.lines)-m.logHeight(), 0)
+
+	switch {
+	case msg.Type == tea.KeyEsc:
+		d.log = nil
+		return m, nil
+
+	case key.Matches(msg, m.keys.Quit):
+		return m, tea.Quit
+
+	case key.Matches(msg, m.keys.Up):
+		l.offset = max(l.offset-1, 0)
+
+	case key.Matches(msg, m.keys.Down):
+		l.offset = min(l.offset+1, lastOffset)
+
+	case msg.Type == tea.KeyPgUp:
+		l.offset = max(l.offset-m.logHeight(), 0)
+
+	case msg.Type == tea.KeyPgDown || msg.Type == tea.KeySpace:
+		l.offset = min(l.offset+m.logHeight(), lastOffset)
+
+	case msg.Type == tea.KeyHome:
+		l.offset = 0
+
+	case msg.Type == tea.KeyEnd:
+		l.offset = lastOffset
+
+	case key.Matches(msg, m.keys.Refresh):
+		if l.loading {
+			return m, nil
+		}
+		l.loading = true
+		return m, fetchTaskLog(m.clientFor(d.project), d.project, d.release.ID, l)
+	}
+
+	return m, nil
+}
+
+// releaseRows flattens a release into the lines of the deployment list: each environment
+// with its deploy attempts, their approvals, gates, and the tasks of each deploy phase
+func releaseRows(r *api.Release) []releaseRow {
+	var rows []releaseRow
+	add := func(text string) {
+		rows = append(rows, releaseRow{text: text})
+	}
+
+	for i := range r.Environments {
+		env := &r.Environments[i]
+		if i > 0 {
+			add("")
+		}
+		add(fmt.Sprintf("%s %s %s", colorizeEnvIcon(getEnvStatusIcon(env.Status), env.Status),
+			styles.SubtitleStyle.Render(env.Name), styles.HelpStyle.Render(string(env.Status))))
+		if len(env.DeploySteps) == 0 {
+			add(styles.HelpStyle.Render("    Not deployed"))
+			continue
+		}
+
+		for j := range env.DeploySteps {
+			step := &env.DeploySteps[j]
+			attempt := fmt.Sprintf("  Attempt %d", max(step.Attempt, 1))
+			if step.Reason != "" {
+				attempt += " • " + step.Reason
+			}
+			if step.RequestedFor.DisplayName != "" {
+				attempt += " • requested for " + step.RequestedFor.DisplayName
+			}
+			if !step.QueuedOn.IsZero() {
+				attempt += " • queued " + formatCreatedTime(step.QueuedOn)
+			}
+			add(attempt)
+
+			pre, post := env.ApprovalsForAttempt(step.Attempt)
+			for _, a := range pre {
+				rows = append(rows, approvalRows("Pre-deployment approval", a)...)
+			}
+			if step.PreDeploymentGates != nil {
+				rows = append(rows, gateRows("Pre-deployment gates", step.PreDeploymentGates)...)
+			}
+
+			for k := range step.ReleaseDeployPhases {
+				phase := &step.ReleaseDeployPhases[k]
+				add(fmt.Sprintf("    %s %s", phase.Name, styles.HelpStyle.Render("("+phase.Status+")")))
+				for _, task := range phase.Tasks() {
+					task := task
+					rows = append(rows, releaseRow{
+						text:          fmt.Sprintf("%s %-40s %s", renderTaskIcon(task.Status), truncate(task.Name, 40), formatDuration(task.GetDuration())),
+						task:          &task,
+						environmentID: env.ID,
+						phaseID:       phase.ID,
+					})
+					for _, issue := range task.Issues {
+						style := styles.CanceledStyle
+						if issue.IssueType == "error" {
+							style = styles.ErrorStyle
+						}
+						add("          " + style.Render(issue.Message))
+					}
+				}
+				if phase.ErrorLog != "" {
+					add("      " + styles.ErrorStyle.Render(phase.ErrorLog))
+				}
+			}
+
+			if step.PostDeploymentGates != nil {
+				rows = append(rows, gateRows("Post-deployment gates", step.PostDeploymentGates)...)
+			}
+			for _, a := range post {
+				rows = append(rows, approvalRows("Post-deployment approval", a)...)
+			}
+		}
+	}
+	return rows
+}
+
+// approvalRows returns the lines of an approval: status, approver and when, then the comment
+func approvalRows(label string, a api.ReleaseApproval) []releaseRow {
+	when := a.CreatedOn
+	if a.Status != api.ApprovalStatusPending && !a.ModifiedOn.IsZero() {
+		when = a.ModifiedOn
+	}
+	rows := []releaseRow{{text: fmt.Sprintf("    %s: %s %s by %s %s", label, renderApprovalIcon(a.Status),
+		a.Status, a.GetApproverDisplay(), styles.HelpStyle.Render(formatCreatedTime(when)))}}
+	if comments := strings.TrimSpace(a.Comments); comments != "" {
+		rows = append(rows, releaseRow{text: "      " + styles.HelpStyle.Render("“"+comments+"”")})
+	}
+	return rows
+}
+
+// gateRows returns the lines of the gates of a deploy attempt, one per gate
+func gateRows(label string, g *api.ReleaseGates) []releaseRow {
+	rows := []releaseRow{{text: fmt.Sprintf("    %s %s", label, styles.HelpStyle.Render("("+g.Status+")"))}}
+	for _, task := range g.Tasks() {
+		rows = append(rows, releaseRow{text: fmt.Sprintf("      %s %-40s %s",
+			renderTaskIcon(task.Status), truncate(task.Name, 40), formatDuration(task.GetDuration()))})
+	}
+	return rows
+}
+
+// renderReleaseDetail renders the release detail pane
+func (m Model) renderReleaseDetail() string {
+	d := m.release
+	r := d.release
+	var b strings.Builder
+
+	b.WriteString(styles.ActiveTabStyle.Render(fmt.Sprintf("► Release %s (%s)", r.Name, r.ReleaseDefinition.Name)))
+	b.WriteString("\n")
+	b.WriteString(styles.HelpStyle.Render(fmt.Sprintf("%s • %s • created %s",
+		r.Status, r.CreatedBy.DisplayName, formatCreatedTime(r.CreatedOn))))
+	b.WriteString("\n")
+	if description := strings.TrimSpace(r.Description); description != "" {
+		b.WriteString(truncate(strings.ReplaceAll(description, "\n", " "), max(m.width-2, 20)))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	if d.log != nil {
+		b.WriteString(m.renderTaskLog())
+	} else {
+		b.WriteString(m.renderDeployments())
+	}
+
+	if d.err != nil {
+		b.WriteString("\n")
+		b.WriteString(styles.ErrorStyle.Render(fmt.Sprintf("Error: %v", d.err)))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	if d.log != nil {
+		b.WriteString(styles.HelpStyle.Render("↑/↓ scroll • pgup/pgdn page • home/end top/bottom • r reload • esc back to deployments"))
+	} else {
+		b.WriteString(styles.HelpStyle.Render("↑/↓ select task • enter show log • r reload • esc back"))
+	}
+	return b.String()
+}
+
+// renderDeployments renders the deployment list, scrolled to keep the selected task visible
+func (m Model) renderDeployments() string {
+	d := m.release
+	var b strings.Builder
+
+	b.WriteString(styles.SubtitleStyle.Render("Deployments"))
+	b.WriteString("\n")
+	rows := releaseRows(&d.release)
+	if d.loading && (len(rows) == 0 || d.selected < 0) {
+		b.WriteString(m.spinner.View())
+		b.WriteString(" Loading deployments...\n")
+		return b.String()
+	}
+	if len(rows) == 0 {
+		b.WriteString(styles.HelpStyle.Render("  No environments"))
+		b.WriteString("\n")
+		return b.String()
+	}
+
+	height := m.logHeight()
+	start := max(0, min(d.selected-height/2, len(rows)-height))
+	end := min(start+height, len(rows))
+	for i := start; i < end; i++ {
+		row := rows[i]
+		switch {
+		case i == d.selected:
+			b.WriteString(styles.SelectedRowStyle.Render("    > ") + row.text)
+		case row.task != nil:
+			b.WriteString("      " + row.text)
+		default:
+			b.WriteString(row.text)
+		}
+		b.WriteString("\n")
+	}
+	if end < len(rows) {
+		b.WriteString(styles.HelpStyle.Render(fmt.Sprintf("  … %d more lines", len(rows)-end)))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// renderTaskLog renders the visible part of the log of the selected task
+func (m Model) renderTaskLog() string {
+	l := m.release.log
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("%s %s", renderTaskIcon(l.task.Status), styles.SubtitleStyle.Render(l.task.Name)))
+	b.WriteString(styles.HelpStyle.Render(fmt.Sprintf("  %s • %s", l.task.AgentName, formatDuration(l.task.GetDuration()))))
+	b.WriteString("\n")
+
+	switch {
+	case l.loading && len(l.lines) == 0:
+		b.WriteString(m.spinner.View())
+		b.WriteString(" Loading log...\n")
+		return b.String()
+	case l.err != nil:
+		b.WriteString(styles.ErrorStyle.Render(fmt.Sprintf("Error: %v", l.err)))
+		b.WriteString("\n")
+		return b.String()
+	case len(l.lines) == 0:
+		b.WriteString(styles.HelpStyle.Render("  Empty log"))
+		b.WriteString("\n")
+		return b.String()
+	}
+
+	if l.offset > 0 {
+		b.WriteString(styles.HelpStyle.Render(fmt.Sprintf("  … %d lines above", l.offset)))
+		b.WriteString("\n")
+	}
+	end := min(l.offset+m.logHeight(), len(l.lines))
+	for _, line := range l.lines[l.offset:end] {
+		b.WriteString(renderLogLine(line, m.width))
+		b.WriteString("\n")
+	}
+	if end < len(l.lines) {
+		b.WriteString(styles.HelpStyle.Render(fmt.Sprintf("  … %d more lines", len(l.lines)-end)))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// renderLogLine renders a line of an agent log without its timestamp, colored by its
+// logging command ("##[error]", "##[warning]", "##[section]")
+func renderLogLine(line string, width int) string {
+	if stamp, text, ok := strings.Cut(line, " "); ok {
+		if _, err := time.Parse(time.RFC3339Nano, stamp); err == nil {
+			line = text
+		}
+	}
+	line = truncate(strings.ReplaceAll(line, "\t", "    "), max(width-2, 20))
+
+	switch {
+	case strings.HasPrefix(line, "##[error]"):
+		return styles.FailedStyle.Render(strings.TrimPrefix(line, "##[error]"))
+	case strings.HasPrefix(line, "##[warning]"):
+		return styles.CanceledStyle.Render(strings.TrimPrefix(line, "##[warning]"))
+	case strings.HasPrefix(line, "##[section]"):
+		return styles.InProgressStyle.Render(strings.TrimPrefix(line, "##[section]"))
+	default:
+		return line
+	}
+}
+
+// renderTaskIcon returns a colored icon for the status of a task
+func renderTaskIcon(status api.TaskStatus) string {
+	switch status {
+	case api.TaskStatusSucceeded, api.TaskStatusSuccess:
+		return styles.SucceededStyle.Render("✓")
+	case api.TaskStatusPartiallySucceeded:
+		return styles.CanceledStyle.Render("◐")
+	case api.TaskStatusFailed, api.TaskStatusFailure:
+		return styles.FailedStyle.Render("✗")
+	case api.TaskStatusInProgress:
+		return styles.InProgressStyle.Render("●")
+	case api.TaskStatusCanceled:
+		return styles.CanceledStyle.Render("⊘")
+	case api.TaskStatusSkipped:
+		return styles.NotStartedStyle.Render("⊝")
+	default:
+		return styles.NotStartedStyle.Render("○")
+	}
+}
+
+// renderApprovalIcon returns a colored icon for the status of an approval
+func renderApprovalIcon(status api.ApprovalStatus) string {
+	switch status {
+	case api.ApprovalStatusApproved:
+		return styles.SucceededStyle.Render("✓")
+	case api.ApprovalStatusRejected:
+		return styles.FailedStyle.Render("✗")
+	case api.ApprovalStatusPending:
+		return styles.QueuedStyle.Render("○")
+	case api.ApprovalStatusCanceled, api.ApprovalStatusReassigned:
+		return styles.CanceledStyle.Render("⊘")
+	default:
+		return styles.NotStartedStyle.Render("⊝")
+	}
+}
@@ -1,11 +1,16 @@
 package tui
 
 import (
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/api"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/config"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/discovery"
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/webhook"
 )
 
 // Update handles messages and updates the model
@@ -23,35 +28,114 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case BuildsLoadedMsg:
+		if msg.Branch != m.prFilter.forProject(msg.Project).Branch {
+			// Fetched before the current branch filter was toggled; a fetch with it is pending
+			return m, nil
+		}
 		m.loadingBuilds[msg.Project] = false
 		if msg.Err != nil {
 			m.errors[msg.Project+"-builds"] = msg.Err
+			m.markStale(msg.Project+"-builds", len(m.builds[msg.Project]) > 0)
 		} else {
 			delete(m.errors, msg.Project+"-builds")
 			m.builds[msg.Project] = msg.Builds
+			m.markFresh(msg.Project + "-builds")
+			// Builds of the current branch only are not cached
+			if m.snapshot != nil && m.prFilter.Branch.Project != msg.Project {
+				m.snapshot.SetBuilds(msg.Project, msg.Builds, m.updatedAt[msg.Project+"-builds"])
+			}
 		}
-		return m, nil
+		return m, m.saveSnapshot()
 
 	case ReleasesLoadedMsg:
 		m.loadingReleases[msg.Project] = false
 		if msg.Err != nil {
 			m.errors[msg.Project+"-releases"] = msg.Err
+			m.markStale(msg.Project+"-releases", len(m.releases[msg.Project]) > 0)
 		} else {
 			delete(m.errors, msg.Project+"-releases")
 			m.releases[msg.Project] = msg.Releases
+			m.markFresh(msg.Project + "-releases")
+			if m.snapshot != nil {
+				m.snapshot.SetReleases(msg.Project, msg.Releases, m.updatedAt[msg.Project+"-releases"])
+			}
 		}
-		return m, nil
+		return m, m.saveSnapshot()
 
 	case PullRequestsLoadedMsg:
+		if msg.Filter != m.prFilter.forProject(msg.Project) {
+			// Fetched before the filters were changed; a fetch with the current filters is pending
+			return m, nil
+		}
 		m.loadingPullRequests[msg.Project] = false
 		if msg.Err != nil {
 			m.errors[msg.Project+"-pullrequests"] = msg.Err
+			m.markStale(msg.Project+"-pullrequests", len(m.pullRequests[msg.Project]) > 0)
 		} else {
 			delete(m.errors, msg.Project+"-pullrequests")
 			m.pullRequests[msg.Project] = msg.PullRequests
+			m.markFresh(msg.Project + "-pullrequests")
+			if m.snapshot != nil && m.isStartupFilter() {
+				m.snapshot.SetPullRequests(msg.Project, msg.PullRequests, m.updatedAt[msg.Project+"-pullrequests"])
+			}
 		}
+		return m, m.saveSnapshot()
+
+	case ProjectsListedMsg:
+		return m.handleProjectsListed(msg)
+
+	case PullRequestDetailMsg:
+		return m.handleDetailLoaded(msg)
+
+	case ThreadUpdatedMsg:
+		return m.handleThreadUpdated(msg)
+
+	case PullRequestChangesMsg:
+		return m.handleChangesLoaded(msg)
+
+	case FileDiffMsg:
+		return m.handleFileDiffLoaded(msg)
+
+	case PullRequestCompletedMsg:
+		return m.handlePullRequestCompleted(msg)
+
+	case RepositoryLoadedMsg:
+		return m.handleRepositoryLoaded(msg)
+
+	case PullRequestCreatedMsg:
+		return m.handlePullRequestCreated(msg)
+
+	case ReleaseDetailMsg:
+		return m.handleReleaseDetailLoaded(msg)
+
+	case TaskLogMsg:
+		return m.handleTaskLogLoaded(msg)
+
+	case ReleaseDefinitionsLoadedMsg:
+		return m.handleReleaseDefinitionsLoaded(msg)
+
+	case ReleaseFormLoadedMsg:
+		return m.handleReleaseFormLoaded(msg)
+
+	case ReleaseCreatedMsg:
+		return m.handleReleaseCreated(msg)
+
+	case PreflightMsg:
+		m.preflight[msg.Organization] = &msg.Report
 		return m, nil
 
+	case WebhookEventMsg:
+		return m.handleWebhookEvent(msg.Event)
+
+	case DefinitionsTickMsg:
+		return m, tea.Batch(
+			resolveDefinitions(m.clients, m.config.Projects),
+			definitionsTicker(m.config.Display.DefinitionRefreshInterval),
+		)
+
+	case DefinitionsResolvedMsg:
+		return m.handleDefinitionsResolved(msg)
+
 	case RefreshTickMsg:
 		return m.handleRefresh()
 
@@ -62,11 +146,63 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, tea.Batch(cmds...)
 	}
 
+	// Let the picker's text input handle its cursor blinking
+	if m.picker != nil {
+		var cmd tea.Cmd
+		m.picker.input, cmd = m.picker.input.Update(msg)
+		return m, cmd
+	}
+	if m.detail != nil && m.detail.replying {
+		var cmd tea.Cmd
+		m.detail.input, cmd = m.detail.input.Update(msg)
+		return m, cmd
+	}
+	if m.create != nil {
+		var cmds []tea.Cmd
+		var cmd tea.Cmd
+		m.create.target, cmd = m.create.target.Update(msg)
+		cmds = append(cmds, cmd)
+		m.create.title, cmd = m.create.title.Update(msg)
+		cmds = append(cmds, cmd)
+		m.create.description, cmd = m.create.description.Update(msg)
+		cmds = append(cmds, cmd)
+		m.create.reviewers, cmd = m.create.reviewers.Update(msg)
+		cmds = append(cmds, cmd)
+		return m, tea.Batch(cmds...)
+	}
+	if m.createRelease != nil {
+		var cmd tea.Cmd
+		m.createRelease.description, cmd = m.createRelease.description.Update(msg)
+		return m, cmd
+	}
+
 	return m, nil
 }
 
 // handleKeyMsg handles keyboard input
 func (m Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.picker != nil {
+		return m.handlePickerKey(msg)
+	}
+	if m.create != nil {
+		return m.handleCreateKey(msg)
+	}
+	if m.createRelease != nil {
+		return m.handleReleaseFormKey(msg)
+	}
+	if m.complete != nil {
+		return m.handleCompleteKey(msg)
+	}
+	if m.files != nil {
+		return m.handleFilesKey(msg)
+	}
+	if m.detail != nil {
+		return m.handleDetailKey(msg)
+	}
+	if m.release != nil {
+		return m.handleReleaseKey(msg)
+	}
+
 	switch {
 	case key.Matches(msg, m.keys.Quit):
 		return m, tea.Quit
@@ -113,6 +249,56 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case key.Matches(msg, m.keys.Refresh):
 		return m.handleRefresh()
+
+	case key.Matches(msg, m.keys.Projects):
+		return m.openPicker()
+
+	case key.Matches(msg, m.keys.CurrentBranch):
+		return m.toggleCurrentBranch()
+	}
+
+	if m.activeTab == TabReleases {
+		switch {
+		case key.Matches(msg, m.keys.Details):
+			return m.openReleaseDetail()
+
+		case key.Matches(msg, m.keys.NewRelease):
+			return m.openReleaseForm()
+
+		case key.Matches(msg, m.keys.NewReleaseOf):
+			return m.chooseReleaseDefinition()
+		}
+	}
+
+	if m.activeTab == TabPullRequests {
+		switch {
+		case key.Matches(msg, m.keys.FilterStatus):
+			return m.toggleFilter(func(f *pullRequestFilter) { f.Status = f.nextStatus() })
+
+		case key.Matches(msg, m.keys.FilterMine):
+			return m.toggleFilter(func(f *pullRequestFilter) { f.CreatedByMe = !f.CreatedByMe })
+
+		case key.Matches(msg, m.keys.FilterReview):
+			return m.toggleFilter(func(f *pullRequestFilter) { f.AwaitingMyReview = !f.AwaitingMyReview })
+
+		case key.Matches(msg, m.keys.FilterTarget):
+			return m.toggleFilter(func(f *pullRequestFilter) { f.AllTargets = !f.AllTargets })
+
+		case key.Matches(msg, m.keys.FilterDrafts):
+			return m.toggleFilter(func(f *pullRequestFilter) { f.HideDrafts = !f.HideDrafts })
+
+		case key.Matches(msg, m.keys.Details):
+			return m.openDetail()
+
+		case key.Matches(msg, m.keys.Files):
+			return m.openSelectedFiles()
+
+		case key.Matches(msg, m.keys.Complete):
+			return m.openCompleteForm()
+
+		case key.Matches(msg, m.keys.NewPullRequest):
+			return m.openCreateForm()
+		}
 	}
 
 	return m, nil
@@ -121,6 +307,7 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 // handleEnter opens the selected build/release/pull request in the browser
 func (m Model) handleEnter() (tea.Model, tea.Cmd) {
 	project := m.CurrentProject().Name
+	client := m.clientFor(m.CurrentProject())
 	var url string
 
 	switch m.activeTab {
@@ -131,7 +318,7 @@ func (m Model) handleEnter() (tea.Model, tea.Cmd) {
 			if build.Links.Web.Href != "" {
 				url = build.Links.Web.Href
 			} else {
-				url = m.client.GetBuildWebURL(project, build.ID)
+				url = client.GetBuildWebURL(project, build.ID)
 			}
 		}
 
@@ -142,7 +329,7 @@ func (m Model) handleEnter() (tea.Model, tea.Cmd) {
 			if release.Links.Web.Href != "" {
 				url = release.Links.Web.Href
 			} else {
-				url = m.client.GetReleaseWebURL(project, release.ID)
+				url = client.GetReleaseWebURL(project, release.ID)
 			}
 		}
 
@@ -150,7 +337,7 @@ func (m Model) handleEnter() (tea.Model, tea.Cmd) {
 		pullRequests := m.CurrentPullRequests()
 		if m.selectedRow >= 0 && m.selectedRow < len(pullRequests) {
 			pr := pullRequests[m.selectedRow]
-			url = m.client.GetPullRequestWebURL(project, pr.Repository.Name, pr.PullRequestID)
+			url = client.GetPullRequestWebURL(project, pr.Repository.Name, pr.PullRequestID)
 		}
 	}
 
@@ -170,17 +357,22 @@ func (m Model) handleRefresh() (tea.Model, tea.Cmd) {
 
 	// Mark all as loading
 	for _, p := range m.config.Projects {
-		m.loadingBuilds[p.Name] = true
-		m.loadingReleases[p.Name] = true
-		m.loadingPullRequests[p.Name] = true
+		m.loadingBuilds[p.Key()] = true
+		m.loadingReleases[p.Key()] = true
+		m.loadingPullRequests[p.Key()] = true
 	}
 
 	m.lastRefresh = time.Now()
 
-	return m, tea.Batch(
-		fetchAllData(m.client, m.config.Projects, m.config.Display.MaxItemsPerProject),
-		refreshTicker(m.config.Display.RefreshInterval),
-	)
+	cmds := []tea.Cmd{
+		fetchAllData(m.clients, m.config.Projects, m.prFilter, m.users, m.config.Display.MaxItemsPerProject),
+		refreshTicker(m.config.RefreshInterval()),
+	}
+	// Retry a failed definition lookup instead of waiting for the next scheduled one
+	if m.definitionsErr != nil {
+		cmds = append(cmds, resolveDefinitions(m.clients, m.config.Projects))
+	}
+	return m, tea.Batch(cmds...)
 }
 
 // isAnyLoading returns true if any project is currently loading data
@@ -202,3 +394,211 @@ func (m Model) isAnyLoading() bool {
 	}
 	return false
 }
+
+// markFresh records that the data for key was just fetched successfully
+func (m *Model) markFresh(key string) {
+	m.updatedAt[key] = time.Now()
+	delete(m.stale, key)
+	if m.snapshot != nil {
+		m.snapshotDirty = true
+	}
+}
+
+// markStale flags the shown data for key as stale after a failed fetch
+func (m *Model) markStale(key string, hasData bool) {
+	if hasData {
+		m.stale[key] = true
+	}
+}
+
+// saveSnapshot writes the snapshot to disk once a refresh has completed
+func (m *Model) saveSnapshot() tea.Cmd {
+	if m.snapshot == nil || !m.snapshotDirty || m.isAnyLoading() {
+		return nil
+	}
+	m.snapshotDirty = false
+
+	// Encode now so the command does not race with later updates to the snapshot
+	data, err := m.snapshot.Encode()
+	if err != nil {
+		return nil
+	}
+	return writeSnapshot(m.snapshotPath, data)
+}
+
+// handleWebhookEvent applies a service hook event to the matching row immediately.
+// Rows that are not shown yet are picked up by refetching the affected section.
+func (m Model) handleWebhookEvent(event webhook.Event) (tea.Model, tea.Cmd) {
+	project, ok := m.findProject(event.OrganizationURL, event.Project)
+	if !ok {
+		return m, nil
+	}
+	key := project.Key()
+	client := m.clientFor(project)
+	maxItems := m.config.Display.MaxItemsPerProject
+
+	switch {
+	case event.Build != nil:
+		builds := m.builds[key]
+		for i := range builds {
+			if builds[i].ID == event.Build.ID {
+				updated := append([]api.Build(nil), builds...)
+				build := *event.Build
+				build.Stages = builds[i].Stages // The payload has no timeline
+				updated[i] = build
+				m.builds[key] = updated
+				// Builds of the current branch only are not cached
+				if m.snapshot != nil && m.prFilter.Branch.Project != key {
+					m.snapshot.SetBuilds(key, updated, m.updatedAt[key+"-builds"])
+					m.snapshotDirty = true
+				}
+				break
+			}
+		}
+		save := m.saveSnapshot()
+
+		// Refetch to pick up the final stage results and builds not shown yet
+		if m.loadingBuilds[key] {
+			return m, save
+		}
+		m.loadingBuilds[key] = true
+		return m, tea.Batch(save, fetchBuilds(client, project, m.prFilter.Branch, maxItems))
+
+	case event.Deployment != nil:
+		releases := m.releases[key]
+		for i := range releases {
+			if releases[i].ID != event.Deployment.ReleaseID {
+				continue
+			}
+			updated := append([]api.Release(nil), releases...)
+			environments := append([]api.ReleaseEnvironment(nil), releases[i].Environments...)
+			for j := range environments {
+				if environments[j].ID == event.Deployment.Environment.ID {
+					environments[j].Status = event.Deployment.Environment.Status
+					if len(event.Deployment.Environment.DeploySteps) > 0 {
+						environments[j].DeploySteps = event.Deployment.Environment.DeploySteps
+					}
+				}
+			}
+			updated[i].Environments = environments
+			m.releases[key] = updated
+			if m.snapshot != nil {
+				m.snapshot.SetReleases(key, updated, m.updatedAt[key+"-releases"])
+				m.snapshotDirty = true
+			}
+			return m, m.saveSnapshot()
+		}
+		if m.loadingReleases[key] {
+			return m, nil
+		}
+		m.loadingReleases[key] = true
+		return m, fetchReleases(client, project, maxItems)
+
+	case event.PullRequest != nil:
+		listed := m.pullRequestListed(project, *event.PullRequest)
+
+		pullRequests := m.pullRequests[key]
+		for i := range pullRequests {
+			if pullRequests[i].PullRequestID != event.PullRequest.PullRequestID {
+				continue
+			}
+			var updated []api.PullRequest
+			if listed {
+				updated = append([]api.PullRequest(nil), pullRequests...)
+				updated[i] = *event.PullRequest
+				// Policy evaluations are not part of the event
+				updated[i].Policies = pullRequests[i].Policies
+				updated[i].PolicyError = pullRequests[i].PolicyError
+			} else {
+				updated = append(append([]api.PullRequest(nil), pullRequests[:i]...), pullRequests[i+1:]...)
+			}
+			m.pullRequests[key] = updated
+			if m.activeTab == TabPullRequests && m.selectedRow >= len(updated) && m.selectedRow > 0 {
+				m.selectedRow = len(updated) - 1
+			}
+			if m.snapshot != nil && m.isStartupFilter() {
+				m.snapshot.SetPullRequests(key, updated, m.updatedAt[key+"-pullrequests"])
+				m.snapshotDirty = true
+			}
+			return m, m.saveSnapshot()
+		}
+		if !listed || m.loadingPullRequests[key] {
+			return m, nil
+		}
+		m.loadingPullRequests[key] = true
+		return m, fetchPullRequests(client, project, m.prFilter, m.users, maxItems)
+	}
+
+	return m, nil
+}
+
+// findProject returns the configured project with the given name. When the event names its
+// organization, the project must belong to it; otherwise the name must be unambiguous.
+func (m Model) findProject(organizationURL, name string) (config.ProjectConfig, bool) {
+	var found []config.ProjectConfig
+	for _, p := range m.config.Projects {
+		if !strings.EqualFold(p.Name, name) {
+			continue
+		}
+		if organizationURL != "" && !sameURL(m.clientFor(p).GetOrganizationURL(), organizationURL) {
+			continue
+		}
+		found = append(found, p)
+	}
+	if len(found) != 1 {
+		return config.ProjectConfig{}, false
+	}
+	return found[0], true
+}
+
+// sameURL compares URLs ignoring case and trailing slashes
+func sameURL(a, b string) bool {
+	return strings.EqualFold(strings.TrimRight(a, "/"), strings.TrimRight(b, "/"))
+}
+
+// handleDefinitionsResolved stores the resolved definitions and fetches the sections of projects
+// whose definitions could not be resolved before
+func (m Model) handleDefinitionsResolved(msg DefinitionsResolvedMsg) (tea.Model, tea.Cmd) {
+	m.definitionsErr = msg.Err
+	maxItems := m.config.Display.MaxItemsPerProject
+
+	var cmds []tea.Cmd
+	for i, p := range m.config.Projects {
+		res, ok := msg.Resolved[p.Key()]
+		if !ok {
+			continue
+		}
+		m.setDefinitions(p.Key(), res)
+
+		// Other projects show their new definitions from the next refresh
+		project, key := m.config.Projects[i], p.Key()
+		if len(p.BuildDefinitions) == 0 && len(res.BuildDefinitions) > 0 && !m.loadingBuilds[key] {
+			m.loadingBuilds[key] = true
+			cmds = append(cmds, fetchBuilds(m.clientFor(project), project, m.prFilter.Branch, maxItems))
+		}
+		if len(p.ReleaseDefinitions) == 0 && len(res.ReleaseDefinitions) > 0 && !m.loadingReleases[key] {
+			m.loadingReleases[key] = true
+			cmds = append(cmds, fetchReleases(m.clientFor(project), project, maxItems))
+		}
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// setDefinitions stores the resolved definition IDs of a project; they are used from the next refresh
+func (m *Model) setDefinitions(key string, res discovery.Resolution) {
+	for i := range m.config.Organizations {
+		org := &m.config.Organizations[i]
+		for j := range org.Projects {
+			if org.Projects[j].Key() == key {
+				org.Projects[j].BuildDefinitions = res.BuildDefinitions
+				org.Projects[j].ReleaseDefinitions = res.ReleaseDefinitions
+			}
+		}
+	}
+	for i := range m.config.Projects {
+		if m.config.Projects[i].Key() == key {
+			m.config.Projects[i].BuildDefinitions = res.BuildDefinitions
+			m.config.Projects[i].ReleaseDefinitions = res.ReleaseDefinitions
+		}
+	}
+}
@@ -26,14 +26,59 @@ func (m Model) View() string {
 	b.WriteString(m.renderProjectTabs())
 	b.WriteString("\n\n")
 
+	// Connection check problems
+	if m.hasPreflightFailures() {
+		b.WriteString(m.renderPreflightFailures())
+		b.WriteString("\n\n")
+	}
+	if len(m.warnings) > 0 || m.definitionsErr != nil || m.projectsErr != nil {
+		b.WriteString(m.renderWarnings())
+		b.WriteString("\n\n")
+	}
+
+	// Project picker replaces the sections while open
+	if m.picker != nil {
+		b.WriteString(m.renderPicker())
+		return b.String()
+	}
+
+	// The pull request forms, changed files and pull request details replace the sections while open
+	if m.create != nil {
+		b.WriteString(m.renderCreateForm())
+		return b.String()
+	}
+	if m.complete != nil {
+		b.WriteString(m.renderCompleteForm())
+		return b.String()
+	}
+	if m.files != nil {
+		b.WriteString(m.renderFiles())
+		return b.String()
+	}
+	if m.detail != nil {
+		b.WriteString(m.renderDetail())
+		return b.String()
+	}
+
+	// The release form and release details replace the sections while open
+	if m.createRelease != nil {
+		b.WriteString(m.renderReleaseForm())
+		return b.String()
+	}
+	if m.release != nil {
+		b.WriteString(m.renderReleaseDetail())
+		return b.String()
+	}
+
 	// Builds section
 	branchInfo := m.getBranchFilterInfo()
 	b.WriteString(m.renderSectionHeader("Builds", m.activeTab == TabBuilds))
 	b.WriteString(styles.HelpStyle.Render(fmt.Sprintf(" (branches: %s)", branchInfo)))
+	b.WriteString(m.renderStaleInfo("builds"))
 	b.WriteString("\n")
 	if m.hasBuildData() {
 		b.WriteString(m.renderBuildsTable())
-	} else if m.loadingBuilds[m.CurrentProject().Name] {
+	} else if m.loadingBuilds[m.CurrentProject().Key()] {
 		b.WriteString(m.spinner.View())
 		b.WriteString(" Loading builds...")
 	} else if err := m.getBuildError(); err != nil {
@@ -46,10 +91,11 @@ func (m Model) View() string {
 
 	// Releases section
 	b.WriteString(m.renderSectionHeader("Releases", m.activeTab == TabReleases))
+	b.WriteString(m.renderStaleInfo("releases"))
 	b.WriteString("\n")
 	if m.hasReleaseData() {
 		b.WriteString(m.renderReleasesTable())
-	} else if m.loadingReleases[m.CurrentProject().Name] {
+	} else if m.loadingReleases[m.CurrentProject().Key()] {
 		b.WriteString(m.spinner.View())
 		b.WriteString(" Loading releases...")
 	} else if err := m.getReleaseError(); err != nil {
@@ -62,10 +108,14 @@ func (m Model) View() string {
 
 	// Pull Requests section
 	b.WriteString(m.renderSectionHeader("Pull Requests", m.activeTab == TabPullRequests))
+	if filters := m.prFilter.describe(m.CurrentProject()); filters != "" {
+		b.WriteString(styles.HelpStyle.Render(fmt.Sprintf(" (%s)", filters)))
+	}
+	b.WriteString(m.renderStaleInfo("pullrequests"))
 	b.WriteString("\n")
 	if m.hasPullRequestData() {
 		b.WriteString(m.renderPullRequestsTable())
-	} else if m.loadingPullRequests[m.CurrentProject().Name] {
+	} else if m.loadingPullRequests[m.CurrentProject().Key()] {
 		b.WriteString(m.spinner.View())
 		b.WriteString(" Loading pull requests...")
 	} else if err := m.getPullRequestError(); err != nil {
@@ -93,12 +143,69 @@ func (m Model) renderSectionHeader(title string, isActive bool) string {
 	return styles.TabStyle.Render("  " + title)
 }
 
+// renderStaleInfo renders a marker when a section shows cached or outdated data
+func (m Model) renderStaleInfo(kind string) string {
+	since := m.getStaleSince(kind)
+	if since.IsZero() {
+		return ""
+	}
+	return styles.CanceledStyle.Render(" stale since " + since.Local().Format(m.config.Display.DateFormat))
+}
+
+// renderPreflightFailures renders the failed startup connection checks
+func (m Model) renderPreflightFailures() string {
+	var b strings.Builder
+	b.WriteString(styles.ErrorStyle.Render("Connection check failed:"))
+	for _, organization := range m.organizations() {
+		report, ok := m.preflight[organization]
+		if !ok {
+			continue
+		}
+		prefix := ""
+		if len(m.clients) > 1 {
+			prefix = organization + ": "
+		}
+		for _, check := range report.Failures() {
+			b.WriteString("\n")
+			b.WriteString(styles.ErrorStyle.Render("  " + check.Status.Icon() + " " + prefix + check.Name + ": "))
+			b.WriteString(check.Message)
+		}
+	}
+	return b.String()
+}
+
+// renderWarnings renders the problems found before the dashboard started and the failed
+// definition lookup
+func (m Model) renderWarnings() string {
+	var lines []string
+	if m.projectsErr != nil {
+		title := "Projects could not be listed:"
+		if m.restoredProjects {
+			title = "Projects could not be listed, showing the projects cached by the last run:"
+		}
+		lines = append(lines, styles.ErrorStyle.Render(title))
+		for _, line := range strings.Split(m.projectsErr.Error(), "\n") {
+			lines = append(lines, styles.ErrorStyle.Render("  ✗ ")+line)
+		}
+	}
+	for _, warning := range m.warnings {
+		lines = append(lines, styles.CanceledStyle.Render("! ")+warning)
+	}
+	if m.definitionsErr != nil {
+		lines = append(lines, styles.ErrorStyle.Render("Definition lookup failed, retrying with the next refresh:"))
+		for _, line := range strings.Split(m.definitionsErr.Error(), "\n") {
+			lines = append(lines, styles.ErrorStyle.Render("  ✗ ")+line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 // renderProjectTabs renders the project selection tabs
 func (m Model) renderProjectTabs() string {
 	var tabs []string
 
 	for i, project := range m.config.Projects {
-		name := project.Name
+		name := m.projectLabel(project)
 		if i == m.activeProject {
 			tabs = append(tabs, styles.ActiveProjectStyle.Render(name))
 		} else {
@@ -217,10 +324,16 @@ func (m Model) renderPullRequestsTable() string {
 		return styles.HelpStyle.Render("No pull requests found")
 	}
 
+	// Completed and abandoned pull requests show when they were closed and the merge commit
+	showClosed := m.prFilter.showsClosed()
+
 	// Calculate dynamic column widths based on screen width
-	// Fixed columns: Status(12), Reviewers(12), Created(18) = 42
+	// Fixed columns: Status(12), Reviewers(12), Policies(12), Created(18) = 54, plus Commit(10) for closed PRs
 	// Variable columns: Title, Repository, Branches, Author
-	fixedWidth := 12 + 12 + 18 + 5 // +5 for spacing
+	fixedWidth := 12 + 12 + 12 + 18 + 6 // +6 for spacing
+	if showClosed {
+		fixedWidth += 10 + 1
+	}
 	availableWidth := m.width - fixedWidth
 	if availableWidth < 80 {
 		availableWidth = 80
@@ -233,8 +346,11 @@ func (m Model) renderPullRequestsTable() string {
 	var b strings.Builder
 
 	// Header
-	headerFmt := fmt.Sprintf("%%-%ds %%-%ds %%-%ds %%-%ds %%-12s %%-12s %%-18s", titleWidth, repoWidth, branchesWidth, authorWidth)
-	header := fmt.Sprintf(headerFmt, "Title", "Repository", "Branches", "Author", "Status", "Reviewers", "Created")
+	headerFmt := fmt.Sprintf("%%-%ds %%-%ds %%-%ds %%-%ds %%-12s %%-12s %%-12s %%-18s", titleWidth, repoWidth, branchesWidth, authorWidth)
+	header := fmt.Sprintf(headerFmt, "Title", "Repository", "Branches", "Author", "Status", "Reviewers", "Policies", "Created")
+	if showClosed {
+		header = fmt.Sprintf(headerFmt+" %-10s", "Title", "Repository", "Branches", "Author", "Status", "Reviewers", "Policies", "Closed", "Commit")
+	}
 	b.WriteString(styles.TableHeaderStyle.Render(header))
 	b.WriteString("\n")
 
@@ -246,12 +362,20 @@ func (m Model) renderPullRequestsTable() string {
 		author := truncate(pr.CreatedBy.DisplayName, authorWidth-2)
 		status := pr.GetStatusDisplay()
 		reviewers := pr.GetReviewerSummary()
+		policies := pr.GetPolicySummary()
 		created := formatCreatedTime(pr.CreationDate)
 
 		statusDisplay := styles.GetPullRequestStatusStyle(status).Render(fmt.Sprintf("%-12s", status))
 
-		rowFmt := fmt.Sprintf("%%-%ds %%-%ds %%-%ds %%-%ds %%s %%-12s %%-18s", titleWidth, repoWidth, branchesWidth, authorWidth)
-		row := fmt.Sprintf(rowFmt, title, repo, branches, author, statusDisplay, reviewers, created)
+		rowFmt := fmt.Sprintf("%%-%ds %%-%ds %%-%ds %%-%ds %%s %%-12s %%-12s %%-18s", titleWidth, repoWidth, branchesWidth, authorWidth)
+		row := fmt.Sprintf(rowFmt, title, repo, branches, author, statusDisplay, reviewers, policies, created)
+		if showClosed {
+			closed := "-"
+			if !pr.IsActive() {
+				closed = formatCreatedTime(pr.ClosedDate)
+			}
+			row = fmt.Sprintf(rowFmt+" %-10s", title, repo, branches, author, statusDisplay, reviewers, policies, closed, pr.GetMergeCommitShort())
+		}
 
 		// Only show selection if Pull Requests section is active
 		if i == m.selectedRow && m.activeTab == TabPullRequests {
@@ -262,6 +386,22 @@ func (m Model) renderPullRequestsTable() string {
 		b.WriteString("\n")
 	}
 
+	// Name the policies blocking the selected pull request
+	if m.activeTab == TabPullRequests && m.selectedRow < len(pullRequests) {
+		if blocking := pullRequests[m.selectedRow].BlockingPolicies(); len(blocking) > 0 {
+			b.WriteString(styles.HelpStyle.Render("Blocking policies:"))
+			b.WriteString("\n")
+			for _, p := range blocking {
+				icon, style := "○", styles.InProgressStyle
+				if p.IsFailed() {
+					icon, style = "✗", styles.FailedStyle
+				}
+				b.WriteString("  " + style.Render(icon) + " " + p.Name())
+				b.WriteString("\n")
+			}
+		}
+	}
+
 	return b.String()
 }
 
@@ -277,7 +417,17 @@ func (m Model) renderStatusBar() string {
 
 	// Next refresh
 	parts = append(parts, fmt.Sprintf("Auto-refresh: %s",
-		m.config.Display.RefreshInterval.String()))
+		m.config.RefreshInterval().String()))
+
+	// Service hook listener
+	if m.config.Webhook.Enabled {
+		parts = append(parts, "Webhooks: "+m.config.Webhook.Listen)
+	}
+
+	// Authenticated user
+	if users := m.signedInUsers(); len(users) > 0 {
+		parts = append(parts, "Signed in as "+strings.Join(users, ", "))
+	}
 
 	// Loading indicator
 	var loadingCount int
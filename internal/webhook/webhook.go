@@ -0,0 +1,254 @@
+// Package webhook receives Azure DevOps service hook events so the dashboard can update
+// immediately instead of waiting for the next poll.
+package webhook
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/api"
+)
+
+// Supported service hook event types
+const (
+	EventBuildComplete       = "build.complete"
+	EventDeploymentCompleted = "ms.vss-release.deployment-completed-event"
+	EventPullRequestUpdated  = "git.pullrequest.updated"
+)
+
+// SecretHeader is the HTTP header carrying the shared secret. Add it to the
+// service hook subscription under "HTTP headers", e.g. "X-Azdo-Tui-Secret: <secret>".
+const SecretHeader = "X-Azdo-Tui-Secret"
+
+// maxPayloadSize limits the size of accepted payloads
+const maxPayloadSize = 4 << 20
+
+// Event is a service hook notification relevant to the dashboard.
+// Exactly one of Build, Deployment and PullRequest is set, matching Type.
+type Event struct {
+	Type            string
+	OrganizationURL string // Organization or collection URL, empty if the payload does not include it
+	Project         string // Project name, empty if the payload does not include it
+	Build           *api.Build
+	Deployment      *Deployment
+	PullRequest     *api.PullRequest
+}
+
+// Deployment is a completed deployment of a release to one environment
+type Deployment struct {
+	ReleaseID   int
+	Environment api.ReleaseEnvironment
+}
+
+// Config holds the listener settings. Either Secret or Username and Password must be set.
+type Config struct {
+	Secret   string // Shared secret expected in SecretHeader
+	Username string // Basic auth user name
+	Password string // Basic auth password
+}
+
+// Server is an http.Handler that accepts service hook payloads and publishes them as events
+type Server struct {
+	config   Config
+	events   chan Event
+	listener net.Listener
+	server   *http.Server
+}
+
+// NewServer creates a service hook receiver
+func NewServer(cfg Config) *Server {
+	return &Server{
+		config: cfg,
+		events: make(chan Event, 64),
+	}
+}
+
+// Events returns the channel on which received events are published
+func (s *Server) Events() <-chan Event {
+	return s.events
+}
+
+// Start listens on addr and serves requests in the background.
+// Errors binding the address are returned immediately.
+func (s *Server) Start(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	s.listener = listener
+	s.server = &http.Server{
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		_ = s.server.Serve(listener)
+	}()
+	return nil
+}
+
+// Addr returns the address the server listens on, or an empty string if not started
+func (s *Server) Addr() string {
+	if s.listener == nil {
+		return ""
+	}
+	return s.listener.Addr().String()
+}
+
+// Close stops the listener
+func (s *Server) Close() error {
+	if s.server == nil {
+		return nil
+	}
+	return s.server.Close()
+}
+
+// ServeHTTP handles a single service hook request
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="azdo-tui"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxPayloadSize))
+	if err != nil {
+		http.Error(w, "failed to read payload", http.StatusBadRequest)
+		return
+	}
+
+	event, err := ParseEvent(body)
+	if errors.Is(err, ErrUnsupportedEvent) {
+		// Acknowledge so Azure DevOps does not disable the subscription
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	select {
+	case s.events <- event:
+		w.WriteHeader(http.StatusNoContent)
+	case <-r.Context().Done():
+	}
+}
+
+// authorized checks the shared secret or basic auth credentials in constant time
+func (s *Server) authorized(r *http.Request) bool {
+	if s.config.Secret != "" {
+		if secret := r.Header.Get(SecretHeader); secret != "" {
+			return equal(secret, s.config.Secret)
+		}
+	}
+
+	if s.config.Username != "" || s.config.Password != "" {
+		user, pass, ok := r.BasicAuth()
+		if ok {
+			return equal(user, s.config.Username) && equal(pass, s.config.Password)
+		}
+	}
+
+	return false
+}
+
+// equal compares two strings in constant time
+func equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// ErrUnsupportedEvent is returned by ParseEvent for event types the dashboard does not use
+var ErrUnsupportedEvent = errors.New("unsupported event type")
+
+// payload is the service hook envelope
+type payload struct {
+	EventType          string          `json:"eventType"`
+	Resource           json.RawMessage `json:"resource"`
+	ResourceContainers struct {
+		Collection resourceContainer `json:"collection"`
+		Account    resourceContainer `json:"account"`
+	} `json:"resourceContainers"`
+}
+
+// resourceContainer identifies the organization or collection an event belongs to
+type resourceContainer struct {
+	ID      string `json:"id"`
+	BaseURL string `json:"baseUrl"`
+}
+
+// deploymentResource is the resource of a deployment completed event
+type deploymentResource struct {
+	Environment struct {
+		api.ReleaseEnvironment
+		ReleaseID int `json:"releaseId"`
+	} `json:"environment"`
+	Release api.Release     `json:"release"`
+	Project api.TeamProject `json:"project"`
+}
+
+// ParseEvent decodes a service hook payload
+func ParseEvent(data []byte) (Event, error) {
+	var p payload
+	if err := json.Unmarshal(data, &p); err != nil {
+		return Event{}, fmt.Errorf("failed to parse payload: %w", err)
+	}
+	if len(p.Resource) == 0 {
+		return Event{}, fmt.Errorf("payload has no resource")
+	}
+
+	event := Event{Type: p.EventType, OrganizationURL: p.ResourceContainers.Collection.BaseURL}
+	if event.OrganizationURL == "" {
+		event.OrganizationURL = p.ResourceContainers.Account.BaseURL
+	}
+
+	switch p.EventType {
+	case EventBuildComplete:
+		var build api.Build
+		if err := json.Unmarshal(p.Resource, &build); err != nil {
+			return Event{}, fmt.Errorf("failed to parse build: %w", err)
+		}
+		event.Build = &build
+		event.Project = build.Project.Name
+
+	case EventDeploymentCompleted:
+		var res deploymentResource
+		if err := json.Unmarshal(p.Resource, &res); err != nil {
+			return Event{}, fmt.Errorf("failed to parse deployment: %w", err)
+		}
+		releaseID := res.Environment.ReleaseID
+		if releaseID == 0 {
+			releaseID = res.Release.ID
+		}
+		event.Deployment = &Deployment{ReleaseID: releaseID, Environment: res.Environment.ReleaseEnvironment}
+		event.Project = res.Project.Name
+		if event.Project == "" {
+			event.Project = res.Release.ProjectReference.Name
+		}
+
+	case EventPullRequestUpdated:
+		var pr api.PullRequest
+		if err := json.Unmarshal(p.Resource, &pr); err != nil {
+			return Event{}, fmt.Errorf("failed to parse pull request: %w", err)
+		}
+		event.PullRequest = &pr
+		event.Project = pr.Repository.Project.Name
+
+	default:
+		return Event{}, fmt.Errorf("%w: %s", ErrUnsupportedEvent, p.EventType)
+	}
+
+	return event, nil
+}
@@ -0,0 +1,129 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/polakv93/azure_devops_tui_dashboard/internal/api"
+)
+
+const buildPayload = `{
+  "eventType": "build.complete",
+  "resource": {
+    "id": 42,
+    "buildNumber": "20240101.3",
+    "status": "completed",
+    "result": "failed",
+    "definition": {"id": 7, "name": "payments-ci"},
+    "project": {"id": "p1", "name": "Payments"}
+  }
+}`
+
+const deploymentPayload = `{
+  "eventType": "ms.vss-release.deployment-completed-event",
+  "resource": {
+    "environment": {"id": 11, "releaseId": 3, "name": "prod", "status": "succeeded"},
+    "project": {"id": "p1", "name": "Payments"}
+  }
+}`
+
+const pullRequestPayload = `{
+  "eventType": "git.pullrequest.updated",
+  "resource": {
+    "pullRequestId": 5,
+    "title": "Add refunds endpoint",
+    "status": "completed",
+    "repository": {"name": "payments-api", "project": {"id": "p1", "name": "Payments"}}
+  }
+}`
+
+func TestParseEvent(t *testing.T) {
+	event, err := ParseEvent([]byte(buildPayload))
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if event.Project != "Payments" || event.Build == nil || event.Build.ID != 42 || event.Build.Result != api.BuildResultFailed {
+		t.Errorf("build event = %+v", event)
+	}
+
+	event, err = ParseEvent([]byte(deploymentPayload))
+	if err != nil {
+		t.Fatalf("deployment: %v", err)
+	}
+	if d := event.Deployment; event.Project != "Payments" || d == nil || d.ReleaseID != 3 ||
+		d.Environment.ID != 11 || d.Environment.Status != api.EnvironmentStatusSucceeded {
+		t.Errorf("deployment event = %+v", event)
+	}
+
+	event, err = ParseEvent([]byte(pullRequestPayload))
+	if err != nil {
+		t.Fatalf("pull request: %v", err)
+	}
+	if event.Project != "Payments" || event.PullRequest == nil || event.PullRequest.Status != api.PullRequestStatusCompleted {
+		t.Errorf("pull request event = %+v", event)
+	}
+
+	if _, err := ParseEvent([]byte(`{"eventType": "workitem.updated", "resource": {}}`)); !errors.Is(err, ErrUnsupportedEvent) {
+		t.Errorf("unsupported event error = %v", err)
+	}
+}
+
+func TestServerAuthentication(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		setup  func(r *http.Request)
+		want   int
+	}{
+		{"secret", Config{Secret: "s3cret"}, func(r *http.Request) { r.Header.Set(SecretHeader, "s3cret") }, http.StatusNoContent},
+		{"wrong secret", Config{Secret: "s3cret"}, func(r *http.Request) { r.Header.Set(SecretHeader, "guess") }, http.StatusUnauthorized},
+		{"basic auth", Config{Username: "hook", Password: "pw"}, func(r *http.Request) { r.SetBasicAuth("hook", "pw") }, http.StatusNoContent},
+		{"wrong password", Config{Username: "hook", Password: "pw"}, func(r *http.Request) { r.SetBasicAuth("hook", "nope") }, http.StatusUnauthorized},
+		{"no credentials", Config{Secret: "s3cret"}, func(r *http.Request) {}, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := NewServer(tt.config)
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(buildPayload))
+			tt.setup(req)
+			rec := httptest.NewRecorder()
+
+			srv.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if tt.want == http.StatusNoContent {
+				select {
+				case event := <-srv.Events():
+					if event.Build == nil || event.Build.ID != 42 {
+						t.Errorf("event = %+v", event)
+					}
+				case <-time.After(time.Second):
+					t.Fatal("no event published")
+				}
+			}
+		})
+	}
+}
+
+func TestServerIgnoresUnsupportedEvents(t *testing.T) {
+	srv := NewServer(Config{Secret: "s3cret"})
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"eventType": "workitem.updated", "resource": {}}`))
+	req.Header.Set(SecretHeader, "s3cret")
+	rec := httptest.NewRecorder()
+
+	srv.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusAccepted)
+	}
+	if len(srv.Events()) != 0 {
+		t.Errorf("unsupported event was published")
+	}
+}